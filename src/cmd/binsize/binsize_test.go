@@ -0,0 +1,174 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"internal/testenv"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSymPackage(t *testing.T) {
+	for _, tt := range []struct {
+		sym, pkg string
+	}{
+		{"main.main", "main"},
+		{"runtime.(*mheap).alloc", "runtime"},
+		{"net/http.(*Server).Serve.func1", "net/http"},
+		{"encoding/json.Marshal", "encoding/json"},
+		{"golang.org/x/mod/module.Check", "golang.org/x/mod/module"},
+		{"gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml.v3"},
+		{"sync/atomic.(*Pointer[go.shape.struct { internal/godebug.value string }]).Load", "sync/atomic"},
+		{"slices.Sort[go.shape.[]string,go.shape.string]", "slices"},
+		{"net/url..inittask", "net/url"},
+		{"go:main.inittasks", "main"},
+		{"go:itab.*os.fileStat,io/fs.FileInfo", "os"},
+		{"type:.eq.go/ast.SliceExpr", "go/ast"},
+		{"type:.eq.[2]runtime.Frame", "runtime"},
+		{"type:.eq.struct { a int }", "(types)"},
+		{"_rt0_amd64_linux", "(unknown)"},
+		{"x_cgo_init", "(unknown)"},
+	} {
+		if pkg := symPackage(tt.sym); pkg != tt.pkg {
+			t.Errorf("symPackage(%q) = %q, want %q", tt.sym, pkg, tt.pkg)
+		}
+	}
+}
+
+func TestTypePackage(t *testing.T) {
+	for _, tt := range []struct {
+		typ, pkg string
+	}{
+		{"go/ast.File", "go/ast"},
+		{"*go/ast.File", "go/ast"},
+		{"[]*net/url.URL", "net/url"},
+		{"[4]sync.Mutex", "sync"},
+		{"chan time.Time", "time"},
+		{"noalg.[8]string", ""},
+		{"noalg.struct { F uintptr; R *sync/atomic.Uint64 }", ""},
+		{"runtime.untracedG·4", "runtime"},
+		{"sync/atomic.Pointer[internal/godebug.value]", "sync/atomic"},
+		{"map[string]int", ""},
+		{"func(int) error", ""},
+		{"uintptr", ""},
+	} {
+		if pkg := typePackage(tt.typ); pkg != tt.pkg {
+			t.Errorf("typePackage(%q) = %q, want %q", tt.typ, pkg, tt.pkg)
+		}
+	}
+}
+
+func TestPackageModule(t *testing.T) {
+	mods := []string{"example.com/m", "example.com/m/sub", "golang.org/x/text"}
+	for _, tt := range []struct {
+		pkg, mod string
+	}{
+		{"example.com/m", "example.com/m"},
+		{"example.com/m/internal/x", "example.com/m"},
+		{"example.com/m/sub/y", "example.com/m/sub"},
+		{"example.com/mm", "(unknown)"},
+		{"golang.org/x/text/unicode/norm", "golang.org/x/text"},
+		{"vendor/golang.org/x/net/idna", "std"},
+		{"net/http", "std"},
+		{"(strings)", "(strings)"},
+	} {
+		if mod := packageModule(tt.pkg, mods); mod != tt.mod {
+			t.Errorf("packageModule(%q) = %q, want %q", tt.pkg, mod, tt.mod)
+		}
+	}
+}
+
+func TestShortestChain(t *testing.T) {
+	const deps = `# example.com/m
+_ -> _rt0_amd64_linux
+_ -> go:main.inittasks
+_rt0_amd64_linux -> runtime.main
+runtime.main -> runtime.main_main·f
+runtime.main_main·f -> main.main
+main.main -> main.run
+main.run -> net/http.Get
+main.main -> net/url.Parse <ReflectMethod>
+go:main.inittasks -> example.com/m/config..inittask
+example.com/m/config..inittask -> example.com/m/config.init.0
+example.com/m/config.init.0 -> encoding/json.Unmarshal
+runtime.main -> internal/godebug.New
+go:main.inittasks -> net/http..inittask
+net/http..inittask -> net/http.init.0
+net/http.init.0 -> net/http.init.func1
+go:main.inittasks -> net/http/pprof..inittask
+net/http/pprof..inittask -> net/http/pprof.init.0
+`
+	g, err := readDeps(strings.NewReader(deps))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		pkg   string
+		roots []string
+		chain []string
+	}{
+		{"net/url", g.whyRoots("net/url"), []string{"main.main", "net/url.Parse"}},
+		// Not from the initialization of net/http itself.
+		{"net/http", g.whyRoots("net/http"), []string{"main.main", "main.run", "net/http.Get"}},
+		{"encoding/json", g.whyRoots("encoding/json"), []string{"example.com/m/config.init.0", "encoding/json.Unmarshal"}},
+		{"internal/godebug", g.whyRoots("internal/godebug"), nil},
+		{"internal/godebug", g.edges[rootSym], []string{"_rt0_amd64_linux", "runtime.main", "internal/godebug.New"}},
+		// Linked only for its initialization.
+		{"net/http/pprof", g.whyRoots("net/http/pprof"), nil},
+		{"net/http/pprof", g.edges[rootSym], []string{"go:main.inittasks", "net/http/pprof..inittask"}},
+		{"os", g.edges[rootSym], nil},
+	} {
+		chain := g.shortestChain(tt.roots, tt.pkg)
+		if !slices.Equal(chain, tt.chain) {
+			t.Errorf("shortestChain(%v, %q) = %q, want %q", tt.roots, tt.pkg, chain, tt.chain)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "hello.go")
+	err := os.WriteFile(src, []byte(`package main
+
+import "net/url"
+
+func main() {
+	u, _ := url.Parse("https://go.dev/")
+	println(u.Host)
+}
+`), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exe := filepath.Join(dir, "hello.exe")
+	out, err := testenv.Command(t, testenv.GoToolPath(t), "build", "-o", exe, src).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+
+	r, err := analyze(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range []string{"main", "runtime", "net/url"} {
+		s := r.sizes[pkg]
+		if s == nil || s[kindText] == 0 || s[kindPclntab] == 0 {
+			t.Errorf("package %s: sizes %v, want text and pclntab", pkg, s)
+		}
+	}
+	if s := r.sizes["net/url"]; s != nil && s[kindTypes] == 0 && runtime.GOOS != "aix" {
+		t.Errorf("package net/url: no type descriptors attributed")
+	}
+	if total := r.total().total(); total <= 0 || total > r.fileSize {
+		t.Errorf("total size %d, want > 0 and <= file size %d", total, r.fileSize)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Binsize reports how the size of a Go executable is divided among
+// the packages and modules linked into it.
+//
+// Usage:
+//
+//	go tool binsize [-modules] [-n count] binary
+//	go tool binsize [-modules] [-n count] -diff old new
+//	go tool binsize -why package -deps file
+//
+// In its first form, binsize prints one line per package, largest first,
+// giving the number of bytes of the binary attributed to the package
+// in total and in each of these categories:
+//
+//	text	machine code
+//	rodata	read-only data, such as constants and itabs
+//	data	initialized variables
+//	types	runtime type descriptors
+//	pclntab	function metadata: the PC-value tables and function data
+//	dwarf	DWARF debugging information
+//
+// Text, rodata, and data are attributed exactly, from the symbol table.
+// The linker packs type descriptors, string data, and function metadata
+// into aggregate symbols, so the other categories are estimates: each type
+// descriptor is attributed to the package defining the type, using the
+// DWARF debugging information to locate it; function metadata is divided
+// among packages in proportion to their text size; and DWARF data is
+// divided in proportion to the size of each package's compilation units.
+// String data cannot be attributed and is reported as the pseudo-package
+// "(strings)". The output ends with the total for all packages and the
+// size of the file, which also includes headers and other sections.
+//
+// The -modules flag aggregates the sizes by module instead of by package,
+// using the build information in the binary. Standard library packages are
+// reported as module "std".
+//
+// The -n flag limits the output to the given number of largest entries.
+//
+// In its second form, binsize compares two binaries, such as two versions
+// of the same program, and prints the change in size of each package
+// (or module, with -modules) that differs, largest change first.
+//
+// In its third form, binsize explains why a package is linked into a
+// binary, by printing the shortest chain of references from main.main
+// or the initializer of another package to a symbol in the package. If
+// the package is linked only to be initialized, the chain starts at the
+// program's list of initialization tasks. The references are
+// read from the symbol dependency graph printed by the linker's -dumpdep
+// flag, which can be saved while building the binary:
+//
+//	go build -ldflags=-dumpdep 2>deps.txt
+//	go tool binsize -why net/http -deps deps.txt
+package main
@@ -0,0 +1,176 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+const helpText = `usage: go tool binsize [-modules] [-n count] binary
+       go tool binsize [-modules] [-n count] -diff old new
+       go tool binsize -why package -deps file
+  -deps file
+      read the symbol dependency graph printed by the linker's -dumpdep flag from file
+  -diff
+      compare the sizes in two binaries
+  -modules
+      report sizes by module instead of by package
+  -n count
+      print only the count largest entries
+  -why package
+      print the shortest chain of references that links package
+`
+
+func usage() {
+	fmt.Fprint(os.Stderr, helpText)
+	os.Exit(2)
+}
+
+var (
+	diffFlag    = flag.Bool("diff", false, "")
+	modulesFlag = flag.Bool("modules", false, "")
+	nFlag       = flag.Int("n", 0, "")
+	whyFlag     = flag.String("why", "", "")
+	depsFlag    = flag.String("deps", "", "")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("binsize: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	switch {
+	case *whyFlag != "":
+		if *depsFlag == "" || flag.NArg() != 0 {
+			usage()
+		}
+		why(*whyFlag, *depsFlag)
+
+	case *diffFlag:
+		if flag.NArg() != 2 {
+			usage()
+		}
+		old := load(flag.Arg(0))
+		new := load(flag.Arg(1))
+		printDiff(old, new)
+
+	default:
+		if flag.NArg() != 1 {
+			usage()
+		}
+		printReport(load(flag.Arg(0)))
+	}
+}
+
+// load analyzes the named binary and returns the sizes to report,
+// by package or, with -modules, by module.
+func load(file string) *report {
+	r, err := analyze(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *modulesFlag {
+		if err := r.byModule(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return r
+}
+
+// header is the heading of the columns printed by printReport.
+var header = fmt.Sprintf("%10s %10s %10s %10s %10s %10s %10s  %s",
+	"total", "text", "rodata", "data", "types", "pclntab", "dwarf", "package")
+
+func printReport(r *report) {
+	names := r.names()
+	slices.SortFunc(names, func(a, b string) int {
+		return cmpSize(r.sizes[a].total(), r.sizes[b].total(), a, b)
+	})
+	if *nFlag > 0 && len(names) > *nFlag {
+		names = names[:*nFlag]
+	}
+
+	h := header
+	if *modulesFlag {
+		h = strings.TrimSuffix(h, "package") + "module"
+	}
+	fmt.Println(h)
+	for _, name := range names {
+		printSizes(r.sizes[name], name)
+	}
+	printSizes(r.total(), "total")
+	fmt.Printf("%10d %54s  file size\n", r.fileSize, "")
+}
+
+func printSizes(s *sizes, name string) {
+	fmt.Printf("%10d", s.total())
+	for _, n := range s {
+		fmt.Printf(" %10d", n)
+	}
+	fmt.Printf("  %s\n", name)
+}
+
+func printDiff(old, new *report) {
+	type delta struct {
+		name     string
+		old, new int64
+	}
+	var deltas []delta
+	seen := make(map[string]bool)
+	for _, r := range []*report{old, new} {
+		for name := range r.sizes {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			d := delta{name, old.sizes[name].total(), new.sizes[name].total()}
+			if d.old != d.new {
+				deltas = append(deltas, d)
+			}
+		}
+	}
+	slices.SortFunc(deltas, func(a, b delta) int {
+		return cmpSize(abs(a.new-a.old), abs(b.new-b.old), a.name, b.name)
+	})
+	if *nFlag > 0 && len(deltas) > *nFlag {
+		deltas = deltas[:*nFlag]
+	}
+
+	what := "package"
+	if *modulesFlag {
+		what = "module"
+	}
+	fmt.Printf("%10s %10s %10s  %s\n", "delta", "old", "new", what)
+	for _, d := range deltas {
+		fmt.Printf("%+10d %10d %10d  %s\n", d.new-d.old, d.old, d.new, d.name)
+	}
+	oldTotal, newTotal := old.total().total(), new.total().total()
+	fmt.Printf("%+10d %10d %10d  total\n", newTotal-oldTotal, oldTotal, newTotal)
+	fmt.Printf("%+10d %10d %10d  file size\n", new.fileSize-old.fileSize, old.fileSize, new.fileSize)
+}
+
+// cmpSize orders entries by decreasing size x, y, then by name.
+func cmpSize(x, y int64, xname, yname string) int {
+	if x != y {
+		if x > y {
+			return -1
+		}
+		return +1
+	}
+	return strings.Compare(xname, yname)
+}
+
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
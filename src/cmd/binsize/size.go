@@ -0,0 +1,432 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"debug/buildinfo"
+	"debug/dwarf"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"cmd/internal/objfile"
+)
+
+// Size categories, in the order they are printed.
+const (
+	kindText = iota
+	kindRodata
+	kindData
+	kindTypes
+	kindPclntab
+	kindDWARF
+	numKinds
+)
+
+// Pseudo-packages for data that cannot be attributed to a package.
+const (
+	pkgStrings = "(strings)"
+	pkgTypes   = "(types)"
+	pkgUnknown = "(unknown)"
+)
+
+// sizes holds the number of bytes in each category.
+type sizes [numKinds]int64
+
+func (s *sizes) total() int64 {
+	if s == nil {
+		return 0
+	}
+	var t int64
+	for _, n := range s {
+		t += n
+	}
+	return t
+}
+
+// A report holds the sizes attributed to each package
+// (or module) in a binary.
+type report struct {
+	file     string
+	fileSize int64
+	sizes    map[string]*sizes
+}
+
+func (r *report) add(pkg string, kind int, n int64) {
+	s := r.sizes[pkg]
+	if s == nil {
+		s = new(sizes)
+		r.sizes[pkg] = s
+	}
+	s[kind] += n
+}
+
+func (r *report) names() []string {
+	var names []string
+	for name := range r.sizes {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (r *report) total() *sizes {
+	t := new(sizes)
+	for _, s := range r.sizes {
+		for k, n := range s {
+			t[k] += n
+		}
+	}
+	return t
+}
+
+// analyze returns the sizes attributed to each package in the named binary.
+func analyze(file string) (*report, error) {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	f, err := objfile.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	syms, err := f.Symbols()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", file, err)
+	}
+	if len(syms) == 0 {
+		return nil, fmt.Errorf("reading %s: no symbols", file)
+	}
+	r := &report{file: file, fileSize: fi.Size(), sizes: make(map[string]*sizes)}
+
+	// The PC-value tables lie between runtime.pclntab and
+	// runtime.epclntab. The bss sections, which take no space in the
+	// file, lie between runtime.bss and runtime.ebss and between
+	// runtime.noptrbss and runtime.enoptrbss.
+	addrs := make(map[string]uint64)
+	for _, s := range syms {
+		switch s.Name {
+		case "runtime.pclntab", "runtime.epclntab",
+			"runtime.bss", "runtime.ebss",
+			"runtime.noptrbss", "runtime.enoptrbss":
+			addrs[s.Name] = s.Addr
+		}
+	}
+	inBSS := func(addr uint64) bool {
+		return addrs["runtime.bss"] <= addr && addr < addrs["runtime.ebss"] ||
+			addrs["runtime.noptrbss"] <= addr && addr < addrs["runtime.enoptrbss"]
+	}
+
+	// The aggregate symbols for type descriptors, string data, and
+	// function data have size 0 in the symbol table. Each extends to
+	// the next symbol.
+	extent := func(i int) int64 {
+		for _, s := range syms[i+1:] {
+			if s.Addr > syms[i].Addr {
+				return int64(s.Addr - syms[i].Addr)
+			}
+		}
+		return 0
+	}
+	var typesSize, funcdataSize int64
+	for i, s := range syms {
+		switch s.Name {
+		case "type:*":
+			typesSize = extent(i)
+			continue
+		case "go:string.*":
+			r.add(pkgStrings, kindRodata, extent(i))
+			continue
+		case "go:func.*":
+			funcdataSize = extent(i)
+			continue
+		}
+		if s.Size <= 0 || inBSS(s.Addr) {
+			continue
+		}
+		kind := -1
+		switch s.Code {
+		case 'T', 't':
+			kind = kindText
+		case 'R', 'r':
+			kind = kindRodata
+		case 'D', 'd':
+			kind = kindData
+		}
+		if kind >= 0 {
+			r.add(symPackage(s.Name), kind, s.Size)
+		}
+	}
+
+	// Divide the function metadata in proportion to text size.
+	pclntabSize := funcdataSize
+	if start, end := addrs["runtime.pclntab"], addrs["runtime.epclntab"]; end > start {
+		pclntabSize += int64(end - start)
+	}
+	textSizes := make(map[string]int64)
+	for pkg, s := range r.sizes {
+		textSizes[pkg] = s[kindText]
+	}
+	r.apportion(kindPclntab, pclntabSize, textSizes)
+
+	// Use the DWARF data to attribute type descriptors
+	// and the DWARF data itself.
+	var dwarfSize int64
+	if sects, err := f.Sections(); err == nil {
+		for _, s := range sects {
+			if strings.Contains(s.Name, "debug_") {
+				dwarfSize += int64(s.Size)
+			}
+		}
+	}
+	types, units := map[uint64]string(nil), map[string]int64(nil)
+	if d, err := f.DWARF(); err == nil {
+		types, units, err = readDWARF(d)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %v", file, err)
+		}
+	}
+	r.addTypes(typesSize, types)
+	r.apportion(kindDWARF, dwarfSize, units)
+
+	return r, nil
+}
+
+// attrGoRuntimeType is the DWARF attribute holding the offset of a type's
+// runtime type descriptor from the start of the type descriptors,
+// DW_AT_go_runtime_type in cmd/internal/dwarf.
+const attrGoRuntimeType dwarf.Attr = 0x2904
+
+// readDWARF returns the names of the types whose type descriptors are
+// at each offset from the start of the type descriptors, and the size of
+// the compilation units of each package in the DWARF info.
+func readDWARF(d *dwarf.Data) (types map[uint64]string, units map[string]int64, err error) {
+	types = make(map[uint64]string)
+	units = make(map[string]int64)
+	r := d.Reader()
+	unit := ""
+	var unitStart, last dwarf.Offset
+	for {
+		e, err := r.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if e == nil || e.Tag == dwarf.TagCompileUnit {
+			if unit != "" {
+				units[unit] += int64(last) - int64(unitStart)
+			}
+			if e == nil {
+				break
+			}
+			unit, _ = e.Val(dwarf.AttrName).(string)
+			unitStart = e.Offset
+		}
+		if e.Tag != 0 { // null entries have no offset
+			last = e.Offset
+		}
+		if off, ok := e.Val(attrGoRuntimeType).(uint64); ok {
+			if name, ok := e.Val(dwarf.AttrName).(string); ok {
+				types[off] = name
+			}
+		}
+	}
+	return types, units, nil
+}
+
+// addTypes attributes the size bytes of type descriptors to the packages
+// defining the types. Each type descriptor is assumed to extend to the
+// next one whose offset is in types.
+func (r *report) addTypes(size int64, types map[uint64]string) {
+	if size <= 0 {
+		return
+	}
+	offs := make([]uint64, 0, len(types))
+	for off := range types {
+		if int64(off) < size {
+			offs = append(offs, off)
+		}
+	}
+	slices.Sort(offs)
+	start := int64(0)
+	pkg := pkgTypes
+	for _, off := range offs {
+		r.add(pkg, kindTypes, int64(off)-start)
+		start = int64(off)
+		if pkg = typePackage(types[off]); pkg == "" {
+			pkg = pkgTypes
+		}
+	}
+	r.add(pkg, kindTypes, size-start)
+}
+
+// apportion divides size bytes of the given kind among the packages
+// in proportion to their weights. Any remainder from rounding goes
+// to the package with the largest weight.
+func (r *report) apportion(kind int, size int64, weights map[string]int64) {
+	var total int64
+	for _, w := range weights {
+		total += w
+	}
+	if size <= 0 {
+		return
+	}
+	if total <= 0 {
+		r.add(pkgUnknown, kind, size)
+		return
+	}
+	pkgs := make([]string, 0, len(weights))
+	for pkg := range weights {
+		pkgs = append(pkgs, pkg)
+	}
+	slices.SortFunc(pkgs, func(a, b string) int {
+		return cmpSize(weights[a], weights[b], a, b)
+	})
+	left := size
+	for _, pkg := range pkgs {
+		// Use floating point: size*w may overflow int64.
+		n := int64(float64(size) * float64(weights[pkg]) / float64(total))
+		if n > 0 {
+			r.add(pkg, kind, n)
+			left -= n
+		}
+	}
+	if left != 0 {
+		r.add(pkgs[0], kind, left)
+	}
+}
+
+// byModule replaces the package sizes in r by module sizes,
+// using the build information in the binary.
+func (r *report) byModule() error {
+	bi, err := buildinfo.ReadFile(r.file)
+	if err != nil {
+		return err
+	}
+	var mods []string
+	if bi.Main.Path != "" {
+		mods = append(mods, bi.Main.Path)
+	}
+	for _, dep := range bi.Deps {
+		mods = append(mods, dep.Path)
+	}
+
+	pkgSizes := r.sizes
+	r.sizes = make(map[string]*sizes)
+	for pkg, s := range pkgSizes {
+		mod := packageModule(pkg, mods)
+		if pkg == "main" && bi.Main.Path != "" {
+			mod = bi.Main.Path
+		}
+		for k, n := range s {
+			r.add(mod, k, n)
+		}
+	}
+	return nil
+}
+
+// packageModule returns the module in mods that provides pkg.
+// Packages that are not in any module and whose path does not
+// begin with a domain name are in the standard library, "std".
+func packageModule(pkg string, mods []string) string {
+	if strings.HasPrefix(pkg, "(") {
+		return pkg // pseudo-package
+	}
+	best := ""
+	for _, m := range mods {
+		if (pkg == m || strings.HasPrefix(pkg, m+"/")) && len(m) > len(best) {
+			best = m
+		}
+	}
+	if best != "" {
+		return best
+	}
+	elem, _, _ := strings.Cut(pkg, "/")
+	if !strings.Contains(elem, ".") {
+		return "std"
+	}
+	return pkgUnknown
+}
+
+// symPackage returns the path of the package defining the symbol name,
+// or "(unknown)" if the symbol has no package, as for assembly or C symbols
+// without a package qualifier.
+func symPackage(name string) string {
+	for _, prefix := range []string{"type:.eq.", "type:.hash.", "type:"} {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			if pkg := typePackage(rest); pkg != "" {
+				return pkg
+			}
+			return pkgTypes
+		}
+	}
+	if rest, ok := strings.CutPrefix(name, "go:itab."); ok {
+		// go:itab.T,I is attributed to the package of T.
+		name, _, _ = strings.Cut(strings.TrimPrefix(rest, "*"), ",")
+	} else {
+		name = strings.TrimPrefix(name, "go:")
+	}
+	if pkg := qualifier(name); pkg != "" {
+		return pkg
+	}
+	return pkgUnknown
+}
+
+// typePackage returns the path of the package defining the named type,
+// or of its element type for pointer, slice, array, and channel types.
+// It returns "" for other unnamed types and predeclared types.
+func typePackage(name string) string {
+	name = strings.TrimPrefix(name, "noalg.")
+	for {
+		switch {
+		case strings.HasPrefix(name, "*"):
+			name = name[1:]
+		case strings.HasPrefix(name, "[]"):
+			name = name[2:]
+		case strings.HasPrefix(name, "["):
+			i := strings.Index(name, "]")
+			if i < 0 {
+				return ""
+			}
+			name = name[i+1:]
+		case strings.HasPrefix(name, "chan "):
+			name = name[len("chan "):]
+		case strings.HasPrefix(name, "<-chan "):
+			name = name[len("<-chan "):]
+		default:
+			if strings.HasPrefix(name, "map[") || strings.HasPrefix(name, "func(") ||
+				strings.HasPrefix(name, "struct {") || strings.HasPrefix(name, "interface {") {
+				return ""
+			}
+			return qualifier(name)
+		}
+	}
+}
+
+// qualifier returns the package path qualifying the symbol or type name,
+// such as "net/http" for "net/http.(*Server).Serve", or "" if there is none.
+// The last element of a package path in a symbol name has its dots
+// escaped, as in "gopkg.in/yaml%2ev3.Unmarshal".
+func qualifier(name string) string {
+	// Ignore type arguments and receivers, which may contain other
+	// package paths.
+	if i := strings.IndexAny(name, "[("); i >= 0 {
+		name = name[:i]
+	}
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot <= 0 {
+		return ""
+	}
+	pkg := name[:slash+1+dot]
+	if strings.Contains(pkg, "%") {
+		if p, err := url.PathUnescape(pkg); err == nil {
+			pkg = p
+		}
+	}
+	return pkg
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+// why prints the shortest chain of references from main.main or the
+// initializer of another package to a symbol in pkg, using the symbol
+// dependency graph in depsFile. If there is none, it prints the
+// shortest chain from any root of the graph, such as the program entry
+// point or the list of package initializers, which only shows that pkg
+// is linked for its own initialization.
+func why(pkg, depsFile string) {
+	f, err := os.Open(depsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	g, err := readDeps(f)
+	if err != nil {
+		log.Fatalf("reading %s: %v", depsFile, err)
+	}
+
+	chain := g.shortestChain(g.whyRoots(pkg), pkg)
+	if chain == nil {
+		chain = g.shortestChain(g.edges[rootSym], pkg)
+	}
+	if chain == nil {
+		fmt.Printf("%s is not linked\n", pkg)
+		return
+	}
+	fmt.Printf("%s is linked by:\n", pkg)
+	for i, sym := range chain {
+		if i == 0 {
+			fmt.Printf("\t%s\n", sym)
+		} else {
+			fmt.Printf("\t-> %s\n", sym)
+		}
+	}
+}
+
+// rootSym is the source of the edges to the roots of the linker's
+// symbol dependency graph.
+const rootSym = "_"
+
+// A depGraph is a symbol dependency graph.
+type depGraph struct {
+	edges map[string][]string // references from each symbol, sorted
+}
+
+// readDeps reads the output of the linker's -dumpdep flag,
+// lines of the form "from -> to", in which to may be followed by
+// flags in angle brackets and from is "_" for the roots of the graph.
+// Other lines, such as those printed by the go command, are ignored.
+func readDeps(r io.Reader) (*depGraph, error) {
+	g := &depGraph{edges: make(map[string][]string)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		from, to, ok := strings.Cut(scanner.Text(), " -> ")
+		if !ok {
+			continue
+		}
+		from, _, _ = strings.Cut(from, " <")
+		to, _, _ = strings.Cut(to, " <")
+		g.edges[from] = append(g.edges[from], to)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, to := range g.edges {
+		slices.Sort(to)
+	}
+	return g, nil
+}
+
+// whyRoots returns the symbols from which why looks for references to
+// pkg first: main.main and the initialization functions and tasks of
+// the packages other than pkg. The initialization of pkg itself is
+// excluded, as is the list of the program's initialization tasks, which
+// refers to that of every linked package: the references from them
+// would only show that pkg is initialized, not why it is linked.
+func (g *depGraph) whyRoots(pkg string) []string {
+	var roots []string
+	for sym := range g.edges {
+		if isMainOrInit(sym) && sym != "go:main.inittasks" && symPackage(sym) != pkg {
+			roots = append(roots, sym)
+		}
+	}
+	slices.Sort(roots)
+	return roots
+}
+
+// shortestChain returns the shortest chain of references starting at one
+// of roots and ending at a symbol in pkg, or nil if there is none.
+func (g *depGraph) shortestChain(roots []string, pkg string) []string {
+	parent := make(map[string]string)
+	var queue []string
+	for _, root := range roots {
+		if _, ok := parent[root]; !ok {
+			parent[root] = ""
+			queue = append(queue, root)
+		}
+	}
+	for len(queue) > 0 {
+		sym := queue[0]
+		queue = queue[1:]
+		if symPackage(sym) == pkg {
+			var chain []string
+			for ; sym != ""; sym = parent[sym] {
+				chain = append(chain, sym)
+			}
+			slices.Reverse(chain)
+			return chain
+		}
+		for _, to := range g.edges[sym] {
+			if _, ok := parent[to]; !ok {
+				parent[to] = sym
+				queue = append(queue, to)
+			}
+		}
+	}
+	return nil
+}
+
+// isMainOrInit reports whether sym is main.main
+// or a package initialization function or task.
+func isMainOrInit(sym string) bool {
+	return sym == "main.main" || sym == "go:main.inittasks" ||
+		strings.HasSuffix(sym, ".init") || strings.Contains(sym, ".init.") ||
+		strings.HasSuffix(sym, "..inittask")
+}
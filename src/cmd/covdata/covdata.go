@@ -83,6 +83,10 @@ merge       merge data files together
 subtract    subtract one set of data files from another set
 intersect   generate intersection of two sets of data files
 debugdump   dump data in human-readable format for debugging purposes
+bytest      output percentage of statements covered by each test
+testsfor    output list of tests that executed given source lines
+lcov        convert coverage data to LCOV tracefile format
+cobertura   convert coverage data to Cobertura XML format
 `)
 	fmt.Fprintf(os.Stderr, "\nFor help on a specific subcommand, try:\n")
 	fmt.Fprintf(os.Stderr, "\ngo tool covdata <cmd> -help\n")
@@ -105,6 +109,10 @@ const (
 	pkglistMode   = "pkglist"
 	textfmtMode   = "textfmt"
 	debugDumpMode = "debugdump"
+	bytestMode    = "bytest"
+	testsforMode  = "testsfor"
+	lcovMode      = "lcov"
+	coberturaMode = "cobertura"
 )
 
 func main() {
@@ -133,6 +141,8 @@ func main() {
 		op = makeSubtractIntersectOp(subtractMode)
 	case intersectMode:
 		op = makeSubtractIntersectOp(intersectMode)
+	case bytestMode, testsforMode, lcovMode, coberturaMode:
+		op = makeTestOp(cmd)
 	default:
 		usage(fmt.Sprintf("unknown command selector %q", cmd))
 	}
@@ -76,5 +76,41 @@ specifics on usage of a given mode):
 	$ go tool covdata debugdump -i=indir
 	<human readable output>
 	$
+
+9. Report percent of statements covered by each test, using the
+per-test coverage data written by "go test -coverpertest":
+
+	$ go test -coverpertest=testcov ./p
+	$ go tool covdata bytest -i=testcov
+	cov-example/p	TestMedium	coverage: 38.9% of statements
+	cov-example/p	TestSmall	coverage: 5.6% of statements
+	$
+
+The percentage for each test is relative to the statements of the
+packages instrumented in its test binary. The -test flag restricts the data to tests whose names match a regular
+expression, and the -o flag writes the coverage of the selected tests
+in legacy textual format.
+
+10. List the tests that executed given lines of source code, for example
+to select the tests affected by a change:
+
+	$ go tool covdata testsfor -i=testcov -lines=p/p.go:32-40,p/q.go
+	cov-example/p TestSmall
+	$
+
+Each element of the -lines list is a file name optionally followed by
+a line number or a range of line numbers first-last; a file name
+selects all files whose path ends with it.
+
+11. Convert coverage data to an LCOV tracefile, with a separate test
+name for each test in per-test coverage data:
+
+	$ go tool covdata lcov -i=testcov -o=lcov.info
+	$
+
+12. Convert coverage data to a Cobertura XML report:
+
+	$ go tool covdata cobertura -i=profiledir -o=coverage.xml
+	$
 */
 package main
@@ -0,0 +1,309 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// This file contains functions to support the "lcov" and "cobertura"
+// subcommands of "go tool covdata", which export coverage data in
+// formats understood by other coverage tools.
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"internal/coverage"
+	"io"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileCoverage is the coverage of a single source file:
+// the execution count of each line and function.
+type fileCoverage struct {
+	pkg   string
+	file  string
+	lines map[uint32]uint32
+	funcs map[string]*funcCoverage
+}
+
+// funcCoverage is the coverage of a single function.
+type funcCoverage struct {
+	name  string
+	line  uint32            // first line
+	col   uint32            // first column
+	count uint32            // execution count of the first unit
+	lines map[uint32]uint32 // execution count of each line
+}
+
+// byFile converts unit counts into line and function counts for each
+// source file, sorted by file name. A line spanned by several units
+// has the largest of their counts. The count of a function is the
+// count of its first unit, which executes each time it is called.
+// Function literals are counted in the lines, but not as functions,
+// since they have no useful name.
+func (t *tstate) byFile(counts func(u unit) uint32) []*fileCoverage {
+	files := make(map[string]*fileCoverage)
+	for u := range t.units {
+		fc := files[u.file]
+		if fc == nil {
+			fc = &fileCoverage{
+				pkg:   u.pkg,
+				file:  u.file,
+				lines: make(map[uint32]uint32),
+				funcs: make(map[string]*funcCoverage),
+			}
+			files[u.file] = fc
+		}
+		count := counts(u)
+		if t.mode == coverage.CtrModeSet && count > 1 {
+			count = 1
+		}
+		var fnc *funcCoverage
+		if !u.lit {
+			fnc = fc.funcs[u.fn]
+			if fnc == nil {
+				fnc = &funcCoverage{name: u.fn, line: u.StLine, col: u.StCol, lines: make(map[uint32]uint32)}
+				fc.funcs[u.fn] = fnc
+			}
+			if u.StLine < fnc.line || u.StLine == fnc.line && u.StCol <= fnc.col {
+				fnc.line, fnc.col, fnc.count = u.StLine, u.StCol, count
+			}
+		}
+		for l := u.StLine; l <= u.EnLine; l++ {
+			fc.lines[l] = max(fc.lines[l], count)
+			if fnc != nil {
+				fnc.lines[l] = max(fnc.lines[l], count)
+			}
+		}
+	}
+	list := make([]*fileCoverage, 0, len(files))
+	for _, fc := range files {
+		list = append(list, fc)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].file < list[j].file
+	})
+	return list
+}
+
+// sortedFuncs returns the functions in the file in source order.
+func (fc *fileCoverage) sortedFuncs() []*funcCoverage {
+	list := make([]*funcCoverage, 0, len(fc.funcs))
+	for _, fnc := range fc.funcs {
+		list = append(list, fnc)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].line != list[j].line {
+			return list[i].line < list[j].line
+		}
+		return list[i].name < list[j].name
+	})
+	return list
+}
+
+// sortedLines returns the line numbers in the map in increasing order.
+func sortedLines(lines map[uint32]uint32) []uint32 {
+	list := make([]uint32, 0, len(lines))
+	for l := range lines {
+		list = append(list, l)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i] < list[j]
+	})
+	return list
+}
+
+// emitLCOV writes the coverage data as an LCOV tracefile. For
+// per-test coverage data, the tracefile has a separate set of records
+// for each test, identified by its test name (TN) line, so that LCOV
+// tools can report which tests covered each line. Other coverage data
+// is written as a single set of records with an empty test name.
+func (t *tstate) emitLCOV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if len(t.tests) == 0 {
+		t.writeLCOVTest(bw, "", func(u unit) uint32 { return t.units[u] })
+	}
+	for _, id := range t.sortedTests() {
+		m := t.tests[id]
+		t.writeLCOVTest(bw, lcovTestName(id), func(u unit) uint32 { return m[u] })
+	}
+	return bw.Flush()
+}
+
+func (t *tstate) writeLCOVTest(w *bufio.Writer, name string, counts func(u unit) uint32) {
+	for _, fc := range t.byFile(counts) {
+		fmt.Fprintf(w, "TN:%s\n", name)
+		fmt.Fprintf(w, "SF:%s\n", fc.file)
+		funcs := fc.sortedFuncs()
+		fnh := 0
+		for _, fnc := range funcs {
+			fmt.Fprintf(w, "FN:%d,%s\n", fnc.line, fnc.name)
+		}
+		for _, fnc := range funcs {
+			fmt.Fprintf(w, "FNDA:%d,%s\n", fnc.count, fnc.name)
+			if fnc.count != 0 {
+				fnh++
+			}
+		}
+		fmt.Fprintf(w, "FNF:%d\n", len(funcs))
+		fmt.Fprintf(w, "FNH:%d\n", fnh)
+		lh := 0
+		for _, l := range sortedLines(fc.lines) {
+			count := fc.lines[l]
+			fmt.Fprintf(w, "DA:%d,%d\n", l, count)
+			if count != 0 {
+				lh++
+			}
+		}
+		fmt.Fprintf(w, "LF:%d\n", len(fc.lines))
+		fmt.Fprintf(w, "LH:%d\n", lh)
+		fmt.Fprintf(w, "end_of_record\n")
+	}
+}
+
+// lcovTestName returns the LCOV test name for the test. LCOV test
+// names may contain only letters, digits, underscores, and hyphens,
+// so other characters in the package path are replaced by underscores.
+func lcovTestName(id testID) string {
+	return strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, id.String())
+}
+
+// Cobertura XML report elements, as defined by
+// http://cobertura.sourceforge.net/xml/coverage-04.dtd.
+type (
+	coberturaCoverage struct {
+		XMLName         xml.Name           `xml:"coverage"`
+		LineRate        float64            `xml:"line-rate,attr"`
+		BranchRate      float64            `xml:"branch-rate,attr"`
+		LinesCovered    int                `xml:"lines-covered,attr"`
+		LinesValid      int                `xml:"lines-valid,attr"`
+		BranchesCovered int                `xml:"branches-covered,attr"`
+		BranchesValid   int                `xml:"branches-valid,attr"`
+		Complexity      float64            `xml:"complexity,attr"`
+		Version         string             `xml:"version,attr"`
+		Timestamp       int64              `xml:"timestamp,attr"`
+		Packages        []coberturaPackage `xml:"packages>package"`
+	}
+	coberturaPackage struct {
+		Name       string           `xml:"name,attr"`
+		LineRate   float64          `xml:"line-rate,attr"`
+		BranchRate float64          `xml:"branch-rate,attr"`
+		Complexity float64          `xml:"complexity,attr"`
+		Classes    []coberturaClass `xml:"classes>class"`
+	}
+	coberturaClass struct {
+		Name       string            `xml:"name,attr"`
+		Filename   string            `xml:"filename,attr"`
+		LineRate   float64           `xml:"line-rate,attr"`
+		BranchRate float64           `xml:"branch-rate,attr"`
+		Complexity float64           `xml:"complexity,attr"`
+		Methods    []coberturaMethod `xml:"methods>method"`
+		Lines      []coberturaLine   `xml:"lines>line"`
+	}
+	coberturaMethod struct {
+		Name       string          `xml:"name,attr"`
+		Signature  string          `xml:"signature,attr"`
+		LineRate   float64         `xml:"line-rate,attr"`
+		BranchRate float64         `xml:"branch-rate,attr"`
+		Complexity float64         `xml:"complexity,attr"`
+		Lines      []coberturaLine `xml:"lines>line"`
+	}
+	coberturaLine struct {
+		Number int    `xml:"number,attr"`
+		Hits   uint32 `xml:"hits,attr"`
+	}
+)
+
+// emitCobertura writes the coverage data of all selected tests, merged,
+// as a Cobertura XML report. Each source file is reported as a class
+// in the package containing it. Go coverage does not record branches,
+// so the branch rates are always zero.
+func (t *tstate) emitCobertura(w io.Writer) error {
+	cov := &coberturaCoverage{
+		Version:   runtime.Version(),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	var pkg *coberturaPackage
+	var pkgCovered, pkgValid int
+	endPackage := func() {
+		if pkg != nil {
+			pkg.LineRate = rate(pkgCovered, pkgValid)
+			cov.Packages = append(cov.Packages, *pkg)
+		}
+	}
+	files := t.byFile(func(u unit) uint32 { return t.units[u] })
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].pkg < files[j].pkg
+	})
+	for _, fc := range files {
+		if pkg == nil || pkg.Name != fc.pkg {
+			endPackage()
+			pkg = &coberturaPackage{Name: fc.pkg}
+			pkgCovered, pkgValid = 0, 0
+		}
+		lines, covered := coberturaLines(fc.lines)
+		class := coberturaClass{
+			Name:     path.Base(fc.file),
+			Filename: fc.file,
+			LineRate: rate(covered, len(lines)),
+			Lines:    lines,
+		}
+		for _, fnc := range fc.sortedFuncs() {
+			lines, covered := coberturaLines(fnc.lines)
+			class.Methods = append(class.Methods, coberturaMethod{
+				Name:     fnc.name,
+				LineRate: rate(covered, len(lines)),
+				Lines:    lines,
+			})
+		}
+		pkg.Classes = append(pkg.Classes, class)
+		pkgCovered += covered
+		pkgValid += len(lines)
+		cov.LinesCovered += covered
+		cov.LinesValid += len(lines)
+	}
+	endPackage()
+	cov.LineRate = rate(cov.LinesCovered, cov.LinesValid)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`+"\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(cov); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// coberturaLines returns the Cobertura line elements for the line
+// counts, in line order, and the number of lines executed.
+func coberturaLines(counts map[uint32]uint32) (lines []coberturaLine, covered int) {
+	for _, l := range sortedLines(counts) {
+		lines = append(lines, coberturaLine{Number: int(l), Hits: counts[l]})
+		if counts[l] != 0 {
+			covered++
+		}
+	}
+	return lines, covered
+}
+
+func rate(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total)
+}
@@ -0,0 +1,479 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// This file contains functions and apis to support the "go tool
+// covdata" sub-commands that report coverage by test, using the
+// counter data files written by "go test -coverpertest": "bytest" and
+// "testsfor". It also holds the visitor that collects the coverage
+// data for those sub-commands and for "lcov" and "cobertura".
+
+import (
+	"flag"
+	"fmt"
+	"internal/coverage"
+	"internal/coverage/calloc"
+	"internal/coverage/cformat"
+	"internal/coverage/cmerge"
+	"internal/coverage/decodecounter"
+	"internal/coverage/decodemeta"
+	"internal/coverage/pods"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var testflag *string
+var testoutflag *string
+var linesflag *string
+
+func makeTestOp(cmd string) covOperation {
+	testflag = flag.String("test", "", "Select only tests whose names match the regular expression")
+	switch cmd {
+	case bytestMode:
+		testoutflag = flag.String("o", "", "Output text format of the selected tests to file")
+	case testsforMode:
+		linesflag = flag.String("lines", "", "Comma-separated list of file:line or file:first-last ranges to look up")
+	case lcovMode, coberturaMode:
+		testoutflag = flag.String("o", "", "Output file to write")
+	}
+	t := &tstate{
+		cmd:      cmd,
+		cm:       &cmerge.Merger{},
+		units:    make(map[unit]uint32),
+		tests:    make(map[testID]map[unit]uint32),
+		testPkgs: make(map[testID]map[string]bool),
+	}
+	// As for the "percent" and "func" reports, merge data from
+	// different counter modes.
+	t.cm.SetModeMergePolicy(cmerge.ModeMergeRelaxed)
+	return t
+}
+
+// testID identifies a top-level test. The zero testID stands for
+// counter data not associated with a single test.
+type testID struct {
+	pkg, name string
+}
+
+func (id testID) String() string {
+	if id.name == "" {
+		return "(no test)"
+	}
+	return id.pkg + "." + id.name
+}
+
+// unit is a coverable unit within a function, with the package, file,
+// and function containing it.
+type unit struct {
+	pkg, file, fn string
+	lit           bool
+	coverage.CoverableUnit
+}
+
+// lineRange is a range of lines in a source file selected by -lines.
+// A zero last line selects the whole file.
+type lineRange struct {
+	file        string
+	first, last uint32
+}
+
+// tstate encapsulates state and provides methods for implementing the
+// "bytest", "testsfor", "lcov", and "cobertura" operations. It
+// implements the CovDataVisitor interface, recording the count of each
+// coverable unit for each test.
+type tstate struct {
+	// for batch allocation of counter arrays
+	calloc.BatchCounterAlloc
+
+	// counter merging state + methods
+	cm *cmerge.Merger
+
+	// Sub-command (ex: "bytest", "lcov").
+	cmd string
+
+	// Tests selected by -test, or nil for all data.
+	match *regexp.Regexp
+
+	// Line ranges selected by -lines.
+	ranges []lineRange
+
+	// Counter mode of the data. For set mode, counts are 0 or 1.
+	mode coverage.CounterMode
+
+	// Counter data for the current pod, by test and pkgid/funcid.
+	mm map[testID]map[pkfunc][]uint32
+
+	// Test whose counter data file is being visited.
+	cur testID
+
+	// Current package import path.
+	pkgImportPath string
+
+	// Counts of all coverable units, summed over the selected data.
+	units map[unit]uint32
+
+	// Nonzero counts of coverable units, for each selected test.
+	tests map[testID]map[unit]uint32
+
+	// Packages described by the meta-data of the pods holding the
+	// counter data of each selected test, that is, the packages
+	// instrumented in the test binary.
+	testPkgs map[testID]map[string]bool
+}
+
+func (t *tstate) Usage(msg string) {
+	if len(msg) > 0 {
+		fmt.Fprintf(os.Stderr, "error: %s\n", msg)
+	}
+	switch t.cmd {
+	case testsforMode:
+		fmt.Fprintf(os.Stderr, "usage: go tool covdata %s -i=<directories> -lines=<ranges>\n\n", t.cmd)
+	case lcovMode, coberturaMode:
+		fmt.Fprintf(os.Stderr, "usage: go tool covdata %s -i=<directories> -o=<file>\n\n", t.cmd)
+	default:
+		fmt.Fprintf(os.Stderr, "usage: go tool covdata %s -i=<directories>\n\n", t.cmd)
+	}
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nExamples:\n\n")
+	switch t.cmd {
+	case bytestMode:
+		fmt.Fprintf(os.Stderr, "  go tool covdata bytest -i=dir\n\n")
+		fmt.Fprintf(os.Stderr, "  \treads the per-test coverage data files written\n")
+		fmt.Fprintf(os.Stderr, "  \tby 'go test -coverpertest=dir' and reports the\n")
+		fmt.Fprintf(os.Stderr, "  \tpercentage of statements covered by each test.\n\n")
+		fmt.Fprintf(os.Stderr, "  go tool covdata bytest -i=dir -test=TestParse -o=out.txt\n\n")
+		fmt.Fprintf(os.Stderr, "  \temits text format for the statements covered\n")
+		fmt.Fprintf(os.Stderr, "  \tby the tests matching TestParse into file 'out.txt'\n")
+	case testsforMode:
+		fmt.Fprintf(os.Stderr, "  go tool covdata testsfor -i=dir -lines=p/p.go:10-20,p/q.go\n\n")
+		fmt.Fprintf(os.Stderr, "  \treads the per-test coverage data files written\n")
+		fmt.Fprintf(os.Stderr, "  \tby 'go test -coverpertest=dir' and lists the\n")
+		fmt.Fprintf(os.Stderr, "  \ttests that executed lines 10 to 20 of p/p.go\n")
+		fmt.Fprintf(os.Stderr, "  \tor any line of p/q.go.\n")
+	case lcovMode:
+		fmt.Fprintf(os.Stderr, "  go tool covdata lcov -i=dir1,dir2 -o=lcov.info\n\n")
+		fmt.Fprintf(os.Stderr, "  \tmerges data from input directories dir1+dir2\n")
+		fmt.Fprintf(os.Stderr, "  \tand emits an LCOV tracefile into file 'lcov.info',\n")
+		fmt.Fprintf(os.Stderr, "  \twith a separate test name for each test in\n")
+		fmt.Fprintf(os.Stderr, "  \tper-test coverage data.\n")
+	case coberturaMode:
+		fmt.Fprintf(os.Stderr, "  go tool covdata cobertura -i=dir1,dir2 -o=coverage.xml\n\n")
+		fmt.Fprintf(os.Stderr, "  \tmerges data from input directories dir1+dir2\n")
+		fmt.Fprintf(os.Stderr, "  \tand emits a Cobertura XML report into file\n")
+		fmt.Fprintf(os.Stderr, "  \t'coverage.xml'.\n")
+	default:
+		panic("unexpected")
+	}
+	Exit(2)
+}
+
+// Setup is called once at program startup time to vet flag values
+// and do any necessary setup operations.
+func (t *tstate) Setup() {
+	if *indirsflag == "" {
+		t.Usage("select input directories with '-i' option")
+	}
+	if *testflag != "" {
+		re, err := regexp.Compile(*testflag)
+		if err != nil {
+			t.Usage(fmt.Sprintf("bad -test regular expression: %v", err))
+		}
+		t.match = re
+	}
+	switch t.cmd {
+	case testsforMode:
+		if *linesflag == "" {
+			t.Usage("select line ranges with '-lines' option")
+		}
+		ranges, err := parseLineRanges(*linesflag)
+		if err != nil {
+			t.Usage(err.Error())
+		}
+		t.ranges = ranges
+	case lcovMode, coberturaMode:
+		if *testoutflag == "" {
+			t.Usage("select output file name with '-o' option")
+		}
+	}
+}
+
+// parseLineRanges parses the value of the -lines flag, a
+// comma-separated list of file, file:line, or file:first-last.
+func parseLineRanges(s string) ([]lineRange, error) {
+	var ranges []lineRange
+	for _, r := range strings.Split(s, ",") {
+		if r == "" {
+			continue
+		}
+		lr := lineRange{file: r}
+		// A colon not followed by a line number may be part of the
+		// file name, as in a Windows path.
+		if i := strings.LastIndex(r, ":"); i >= 0 && i+1 < len(r) && '0' <= r[i+1] && r[i+1] <= '9' {
+			first, last, isRange := strings.Cut(r[i+1:], "-")
+			f, err := strconv.ParseUint(first, 10, 32)
+			if err != nil || f == 0 {
+				return nil, fmt.Errorf("bad line range %q", r)
+			}
+			l := f
+			if isRange {
+				l, err = strconv.ParseUint(last, 10, 32)
+				if err != nil || l < f {
+					return nil, fmt.Errorf("bad line range %q", r)
+				}
+			}
+			lr = lineRange{file: r[:i], first: uint32(f), last: uint32(l)}
+		}
+		ranges = append(ranges, lr)
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no line ranges in %q", s)
+	}
+	return ranges, nil
+}
+
+// contains reports whether the line range includes any line of u.
+// The file in the range matches the file of u if it is the same or a
+// suffix of it following a slash, so that "p/p.go" selects
+// "example.com/p/p.go".
+func (r lineRange) contains(u unit) bool {
+	if u.file != r.file && !strings.HasSuffix(u.file, "/"+r.file) {
+		return false
+	}
+	return r.last == 0 || u.StLine <= r.last && r.first <= u.EnLine
+}
+
+func (t *tstate) BeginPod(p pods.Pod) {
+	t.mm = make(map[testID]map[pkfunc][]uint32)
+}
+
+func (t *tstate) EndPod(p pods.Pod) {
+}
+
+func (t *tstate) BeginCounterDataFile(cdf string, cdr *decodecounter.CounterDataReader, dirIdx int) {
+	dbgtrace(2, "visit counter data file %s dirIdx %d", cdf, dirIdx)
+	pkg, name := cdr.Test()
+	t.cur = testID{pkg: pkg, name: name}
+}
+
+func (t *tstate) EndCounterDataFile(cdf string, cdr *decodecounter.CounterDataReader, dirIdx int) {
+}
+
+func (t *tstate) VisitFuncCounterData(data decodecounter.FuncPayload) {
+	if !t.selected(t.cur) {
+		return
+	}
+	m := t.mm[t.cur]
+	if m == nil {
+		m = make(map[pkfunc][]uint32)
+		t.mm[t.cur] = m
+	}
+	key := pkfunc{pk: data.PkgIdx, fcn: data.FuncIdx}
+	val := m[key]
+	if len(val) < len(data.Counters) {
+		v := t.AllocateCounters(len(data.Counters))
+		copy(v, val)
+		val = v
+	}
+	err, overflow := t.cm.MergeCounters(val, data.Counters)
+	if err != nil {
+		fatal("%v", err)
+	}
+	if overflow {
+		warn("uint32 overflow during counter merge")
+	}
+	m[key] = val
+}
+
+// selected reports whether the data for the test is selected
+// by the -test flag.
+func (t *tstate) selected(id testID) bool {
+	return t.match == nil || id.name != "" && t.match.MatchString(id.name)
+}
+
+func (t *tstate) EndCounters() {
+}
+
+func (t *tstate) VisitMetaDataFile(mdf string, mfr *decodemeta.CoverageMetaFileReader) {
+	newmode := mfr.CounterMode()
+	if err := t.cm.SetModeAndGranularity(mdf, newmode, mfr.CounterGranularity()); err != nil {
+		fatal("%v", err)
+	}
+	if t.mode == coverage.CtrModeInvalid {
+		t.mode = newmode
+	} else if t.mode != newmode {
+		// Mixing modes: only whether a unit executed is meaningful.
+		t.mode = coverage.CtrModeSet
+	}
+}
+
+func (t *tstate) BeginPackage(pd *decodemeta.CoverageMetaDataDecoder, pkgIdx uint32) {
+	t.pkgImportPath = pd.PackagePath()
+	for id := range t.mm {
+		if id.name == "" {
+			continue
+		}
+		pkgs := t.testPkgs[id]
+		if pkgs == nil {
+			pkgs = make(map[string]bool)
+			t.testPkgs[id] = pkgs
+		}
+		pkgs[t.pkgImportPath] = true
+	}
+}
+
+func (t *tstate) EndPackage(pd *decodemeta.CoverageMetaDataDecoder, pkgIdx uint32) {
+}
+
+func (t *tstate) VisitFunc(pkgIdx uint32, fnIdx uint32, fd *coverage.FuncDesc) {
+	key := pkfunc{pk: pkgIdx, fcn: fnIdx}
+	for i, cu := range fd.Units {
+		// Skip units with non-zero parent, as the text format does.
+		if cu.Parent != 0 {
+			continue
+		}
+		u := unit{
+			pkg:           t.pkgImportPath,
+			file:          fd.Srcfile,
+			fn:            fd.Funcname,
+			lit:           fd.Lit,
+			CoverableUnit: cu,
+		}
+		total := t.units[u]
+		for id, m := range t.mm {
+			counters := m[key]
+			if counters == nil || counters[i] == 0 {
+				continue
+			}
+			total, _ = cmerge.SaturatingAdd(total, counters[i])
+			if id.name == "" {
+				continue
+			}
+			tm := t.tests[id]
+			if tm == nil {
+				tm = make(map[unit]uint32)
+				t.tests[id] = tm
+			}
+			tm[u], _ = cmerge.SaturatingAdd(tm[u], counters[i])
+		}
+		t.units[u] = total
+	}
+}
+
+// sortedTests returns the tests with coverage data,
+// sorted by package and name.
+func (t *tstate) sortedTests() []testID {
+	ids := make([]testID, 0, len(t.tests))
+	for id := range t.tests {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].pkg != ids[j].pkg {
+			return ids[i].pkg < ids[j].pkg
+		}
+		return ids[i].name < ids[j].name
+	})
+	return ids
+}
+
+func (t *tstate) Finish() {
+	switch t.cmd {
+	case bytestMode:
+		t.emitByTest()
+	case testsforMode:
+		t.emitTestsFor()
+	case lcovMode:
+		t.writeOutput(t.emitLCOV)
+	case coberturaMode:
+		t.writeOutput(t.emitCobertura)
+	}
+}
+
+// emitByTest writes the percentage of statements covered by each
+// test to the standard output and, with -o, the coverage of the
+// selected tests in text format to the named file. The percentage
+// is relative to the statements of the packages instrumented in the
+// test's binary, not to those of all the packages in the data.
+func (t *tstate) emitByTest() {
+	if len(t.tests) == 0 {
+		warn("no per-test coverage data found (use 'go test -coverpertest')")
+	}
+	pkgStmts := make(map[string]uint64)
+	for u := range t.units {
+		pkgStmts[u.pkg] += uint64(u.NxStmts)
+	}
+	tabber := tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
+	for _, id := range t.sortedTests() {
+		var totalStmts, coveredStmts uint64
+		for pkg := range t.testPkgs[id] {
+			totalStmts += pkgStmts[pkg]
+		}
+		for u := range t.tests[id] {
+			coveredStmts += uint64(u.NxStmts)
+		}
+		if totalStmts == 0 {
+			totalStmts = 1
+		}
+		fmt.Fprintf(tabber, "%s\t%s\tcoverage: %.1f%% of statements\n",
+			id.pkg, id.name, 100.0*float64(coveredStmts)/float64(totalStmts))
+	}
+	tabber.Flush()
+
+	if *testoutflag != "" && t.mode != coverage.CtrModeInvalid {
+		t.writeOutput(func(w io.Writer) error {
+			format := cformat.NewFormatter(t.mode)
+			for u, count := range t.units {
+				format.SetPackage(u.pkg)
+				format.AddUnit(u.file, u.fn, u.lit, u.CoverableUnit, count)
+			}
+			return format.EmitTextual(w)
+		})
+	}
+}
+
+// emitTestsFor writes the package and name of each test that executed
+// a line in the ranges selected by -lines to the standard output.
+func (t *tstate) emitTestsFor() {
+	if len(t.tests) == 0 {
+		warn("no per-test coverage data found (use 'go test -coverpertest')")
+	}
+	for _, id := range t.sortedTests() {
+		if t.touches(id) {
+			fmt.Printf("%s %s\n", id.pkg, id.name)
+		}
+	}
+}
+
+// touches reports whether the test executed a line in the ranges
+// selected by -lines.
+func (t *tstate) touches(id testID) bool {
+	for u := range t.tests[id] {
+		for _, r := range t.ranges {
+			if r.contains(u) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeOutput creates the file named by -o and calls emit to write to it.
+func (t *tstate) writeOutput(emit func(w io.Writer) error) {
+	f, err := os.Create(*testoutflag)
+	if err != nil {
+		fatal("%v", err)
+	}
+	if err := emit(f); err != nil {
+		f.Close()
+		fatal("writing to %s: %v", *testoutflag, err)
+	}
+	if err := f.Close(); err != nil {
+		fatal("closing %s: %v", *testoutflag, err)
+	}
+}
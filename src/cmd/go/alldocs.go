@@ -37,11 +37,13 @@
 // Additional help topics:
 //
 //	buildconstraint build constraints
+//	buildjson       build -json encoding
 //	buildmode       build modes
 //	c               calling between Go and C
 //	cache           build and test caching
 //	environment     environment variables
 //	filetype        file types
+//	goauth          GOAUTH environment variable
 //	go.mod          the go.mod file
 //	gopath          GOPATH environment variable
 //	goproxy         module proxy protocol
@@ -69,7 +71,7 @@
 //
 // Usage:
 //
-//	go build [-o output] [build flags] [packages]
+//	go build [-o output] [-json] [build flags] [packages]
 //
 // Build compiles the packages named by the import paths,
 // along with their dependencies, but it does not install the results.
@@ -100,6 +102,10 @@
 // ends with a slash or backslash, then any resulting executables
 // will be written to that directory.
 //
+// The -json flag causes build to report its progress, output and failures
+// as a stream of JSON events on standard output instead of text on
+// standard error. See 'go help buildjson' for the encoding details.
+//
 // The build flags are shared by the build, clean, get, install, list, run,
 // and test commands:
 //
@@ -658,7 +664,7 @@
 //
 // Usage:
 //
-//	go get [-t] [-u] [-v] [build flags] [packages]
+//	go get [-t] [-u] [-v] [-tool] [build flags] [packages]
 //
 // Get resolves its command-line arguments to packages at specific module versions,
 // updates go.mod to require those versions, and downloads source code into the
@@ -684,6 +690,14 @@
 //
 //	go get toolchain@patch
 //
+// To track a package as a tool of the main module, runnable with 'go tool':
+//
+//	go get -tool example.com/cmd/tool
+//
+// To upgrade all the tools of the main module:
+//
+//	go get tool
+//
 // See https://golang.org/ref/mod#go-get for details.
 //
 // In earlier versions of Go, 'go get' was used to build and install packages.
@@ -712,6 +726,11 @@
 // When the -t and -u flags are used together, get will update
 // test dependencies as well.
 //
+// The -tool flag instructs get to add a tool directive to go.mod for
+// each package named on the command line, in addition to requiring
+// the modules that provide the packages and their dependencies.
+// When used with @none, get removes the tool directives instead.
+//
 // The -x flag prints commands as they are executed. This is useful for
 // debugging version control commands when a module is downloaded directly
 // from a repository.
@@ -734,7 +753,7 @@
 //
 // Usage:
 //
-//	go install [build flags] [packages]
+//	go install [-json] [build flags] [packages]
 //
 // Install compiles and installs the packages named by the import paths.
 //
@@ -787,6 +806,9 @@
 // Setting GODEBUG=installgoroot=all restores the use of
 // $GOROOT/pkg/$GOOS_$GOARCH.
 //
+// The -json flag reports the progress, output and failures of the build
+// as JSON events, as for 'go build -json'. See 'go help buildjson'.
+//
 // For more about build flags, see 'go help build'.
 //
 // For more about specifying packages, see 'go help packages'.
@@ -1119,6 +1141,7 @@
 //	edit        edit go.mod from tools or scripts
 //	graph       print module requirement graph
 //	init        initialize new module in current directory
+//	serve       serve the module cache as a module proxy
 //	tidy        add missing and remove unused modules
 //	vendor      make vendored copy of dependencies
 //	verify      verify dependencies have expected content
@@ -1230,9 +1253,13 @@
 // like "v1.2.3" or a closed interval like "[v1.1.0,v1.1.9]". Note that
 // -retract=version is a no-op if that retraction already exists.
 //
+// The -tool=path and -droptool=path flags add and drop a tool directive
+// for the given package path. Note that -tool=path is a no-op if that
+// tool directive already exists.
+//
 // The -require, -droprequire, -exclude, -dropexclude, -replace,
-// -dropreplace, -retract, and -dropretract editing flags may be repeated,
-// and the changes are applied in the order given.
+// -dropreplace, -retract, -dropretract, -tool, and -droptool editing
+// flags may be repeated, and the changes are applied in the order given.
 //
 // The -go=version flag sets the expected Go language version.
 //
@@ -1257,6 +1284,7 @@
 //		Exclude   []Module
 //		Replace   []Replace
 //		Retract   []Retract
+//		Tool      []Tool
 //	}
 //
 //	type ModPath struct {
@@ -1281,6 +1309,10 @@
 //		Rationale string
 //	}
 //
+//	type Tool struct {
+//		Path string
+//	}
+//
 // Retract entries representing a single version (not an interval) will have
 // the "Low" and "High" fields set to the same value.
 //
@@ -1328,6 +1360,51 @@
 //
 // See https://golang.org/ref/mod#go-mod-init for more about 'go mod init'.
 //
+// # Serve the module cache as a module proxy
+//
+// Usage:
+//
+//	go mod serve [-http addr] [-dir dir]...
+//
+// Serve serves modules from the module cache as a read-only module proxy
+// that implements the GOPROXY protocol, for use by other go commands,
+// for example on machines without network access.
+// See https://golang.org/ref/mod#goproxy-protocol for the protocol.
+//
+// The -http flag sets the TCP address on which to serve.
+// The default is localhost:8080. The address of the server is
+// printed to standard error when it starts.
+//
+// By default, serve serves the modules downloaded to the module cache,
+// $GOMODCACHE/cache/download. The -dir flag serves the modules in the
+// given directory instead, which must have the same layout. Such a
+// directory can be created by copying the module cache download directory
+// after running 'go mod download', or by setting GOMODCACHE to the
+// directory while downloading. The -dir flag may be repeated to serve
+// several directories; for each request, the directories are searched
+// in order.
+//
+// Serve does not serve vendor directories. A vendor directory holds only
+// the packages needed to build the main module, not complete modules, so
+// the module zip files served from it would not match the checksums
+// recorded in go.sum files and in the checksum database.
+//
+// For each module, the server answers requests for the list of
+// available versions ($module/@v/list), the latest version
+// ($module/@latest), and a version's .info, .mod, and .zip files
+// ($module/@v/$version.info and so on). The list includes only versions
+// whose .info file is present, and excludes pseudo-versions. The latest
+// version is the highest release version, or, if there are none, the
+// highest pre-release version or pseudo-version.
+//
+// The server also proxies the checksum database (see 'go help
+// module-auth'): it serves the lookups and tiles that the go command
+// cached in the sumdb subdirectory of each directory while verifying
+// the modules it downloaded, and the latest signed tree head from
+// $GOPATH/pkg/sumdb (or the sumdb subdirectory). Clients can therefore
+// verify the modules being served without access to the checksum
+// database itself.
+//
 // # Add missing and remove unused modules
 //
 // Usage:
@@ -1804,7 +1881,9 @@
 // The rule for a match in the cache is that the run involves the same
 // test binary and the flags on the command line come entirely from a
 // restricted set of 'cacheable' test flags, defined as -benchtime, -cpu,
-// -list, -parallel, -run, -short, -timeout, -failfast, -fullpath and -v.
+// -list, -parallel, -run, -short, -timeout, -failfast, -fullpath, -shard,
+// -shardtimes and -v. With -shardtimes, the content of the named file is
+// part of the cache key as well.
 // If a run of go test has any test or non-test flags outside this set,
 // the result is not cached. To disable test caching, use any test flag
 // or argument other than the cacheable flags. The idiomatic way to disable
@@ -1857,8 +1936,18 @@
 //	go tool [-n] command [args...]
 //
 // Tool runs the go tool command identified by the arguments.
+//
+// Go ships with a number of builtin tools, and additional tools
+// may be defined in the go.mod of the current module.
+//
 // With no arguments it prints the list of known tools.
 //
+// A tool defined in go.mod by a tool directive may be named either
+// by its full package path or, if that is unambiguous, by the last
+// element of the path (ignoring a major version suffix such as /v2).
+// The tool is built, cached in the build cache, and then run.
+// See 'go help gomod' for more about the tool directive.
+//
 // The -n flag causes tool to print the command that would be
 // executed but not execute it.
 //
@@ -1868,7 +1957,7 @@
 //
 // Usage:
 //
-//	go version [-m] [-v] [file ...]
+//	go version [-m] [-v] [-json] [-sbom format] [file ...]
 //
 // Version prints the build information for Go binary files.
 //
@@ -1887,6 +1976,23 @@
 // information consists of multiple lines following the version line, each
 // indented by a leading tab character.
 //
+// The -json flag is similar to -m but outputs a runtime/debug.BuildInfo
+// structure in JSON format. If flag -json is specified without -m,
+// go version reports an error.
+//
+// The -sbom flag causes go version to print a software bill of materials
+// (SBOM) for each file, derived from its embedded build information, in
+// the given format: "spdx" for an SPDX 2.3 JSON document, or "cyclonedx"
+// for a CycloneDX 1.5 JSON BOM. The SBOM describes the main module as
+// the root component, with the SHA-256 hash of the binary, the build
+// settings, and the Go version that built it; each dependency module,
+// with its go.sum hash and the module it replaces, if any; and the Go
+// toolchain. A go.sum hash is not the hash of a file, so it is recorded
+// as an annotation (SPDX) or a "go:sum" property (CycloneDX) of the
+// module, not as a checksum. Because the information is read from the binary, an SBOM
+// can be produced for any Go binary built with module support, without
+// rebuilding it.
+//
 // See also: go doc runtime/debug.BuildInfo.
 //
 // # Report likely mistakes in packages
@@ -2045,6 +2151,68 @@
 // with a "// +build" prefix. The gofmt command will add an equivalent //go:build
 // constraint when encountering the older syntax.
 //
+// # Build -json encoding
+//
+// The 'go build', 'go install', and 'go test' commands take a -json flag
+// that reports build output and failures as structured JSON output on
+// standard output.
+//
+// The JSON stream is a newline-separated sequence of BuildEvent objects
+// corresponding to the Go struct:
+//
+//	type BuildEvent struct {
+//		ImportPath string
+//		Action     string
+//		Mode       string
+//		Cached     bool
+//		Output     string
+//		File       string
+//		Line       int
+//		Col        int
+//	}
+//
+// The ImportPath field gives the package ID of the package being built.
+// This matches the Package.ImportPath field of go list -json and the
+// TestEvent.FailedBuild field of go test -json. Note that it does not
+// match TestEvent.Package.
+//
+// The Action field is one of the following:
+//
+//	build-start  - the package has started compiling or linking
+//	build-end    - the package has finished compiling or linking
+//	build-output - the toolchain printed output
+//	build-fail   - the build failed
+//
+// The Mode field is set for "build-start" and "build-end" events,
+// and is "build" for compiling a package or "link" for linking
+// an executable.
+//
+// The Cached field is set for "build-end" events if the result
+// was found in the build cache or in an up-to-date installed
+// target, so that no compiling or linking was needed.
+//
+// The Output field is set for Action == "build-output" and is a line of
+// the build's output. The concatenation of the Output fields of all output
+// events is the exact output of the build. There may be more than one
+// output event for a given ImportPath. This matches the definition of
+// the TestEvent.Output field produced by go test -json.
+//
+// If a line of output is a diagnostic of the form "file:line:col: message"
+// or "file:line: message", the File, Line and Col fields give its position.
+//
+// With go test -json, build events are reported unless GODEBUG
+// contains gotestjsonbuildtext=1, in which case build output is
+// printed as text on standard error, as in earlier releases.
+// This struct is designed so that parsers can distinguish
+// interleaved TestEvents and BuildEvents by inspecting the Action field.
+// Furthermore, as with TestEvent, parsers can simply concatenate the Output
+// fields of all events to reconstruct the text format output, as it would
+// have appeared from go build without the -json flag.
+//
+// Note that there may also be non-JSON error text on standard error, even
+// with the -json flag. Typically, this indicates an early, serious error.
+// Consumers should be robust to this.
+//
 // # Build modes
 //
 // The 'go build' and 'go install' commands take a -buildmode argument which
@@ -2181,6 +2349,9 @@
 //	GOARCH
 //		The architecture, or processor, for which to compile code.
 //		Examples are amd64, 386, arm, ppc64.
+//	GOAUTH
+//		Controls authentication for go-import and HTTPS module mirror interactions.
+//		See 'go help goauth'.
 //	GOBIN
 //		The directory where 'go install' will install a command.
 //	GOCACHE
@@ -2406,6 +2577,82 @@
 // line comment. See the go/build package documentation for
 // more details.
 //
+// # GOAUTH environment variable
+//
+// GOAUTH is a semicolon-separated list of authentication commands for go-import and
+// HTTPS module mirror interactions. The default is netrc.
+//
+// The supported authentication commands are:
+//
+// off
+//
+//	Disables authentication.
+//
+// netrc
+//
+//	Uses credentials from NETRC or the .netrc file in your home directory.
+//
+// git dir
+//
+//	Runs 'git credential fill' in dir and uses its credentials.
+//	dir must be an absolute path to a git working directory.
+//
+// command
+//
+//	Executes the given command (a space-separated argument list) and attaches
+//	the provided headers to HTTPS requests.
+//	The command must produce output in the following format:
+//		Response      = { CredentialSet } .
+//		CredentialSet = URLLine { URLLine } BlankLine { HeaderLine } BlankLine .
+//		URLLine       = /* URL that starts with "https://" */ '\n' .
+//		HeaderLine    = /* HTTP Request header */ '\n' .
+//		BlankLine     = '\n' .
+//
+//	Example:
+//		https://example.com
+//		https://example.net/api/
+//
+//		Authorization: Basic <token>
+//
+//		https://another-example.org/
+//
+//		Example: Data
+//
+//	A credential set with no headers removes any credentials
+//	previously provided for its URLs.
+//
+//	If the server rejects a request with status 401 or 403, the go command
+//	writes the response to the command's standard input:
+//		Response      = StatusLine { HeaderLine } BlankLine .
+//		StatusLine    = Protocol Space Status '\n' .
+//		Protocol      = /* HTTP protocol */ .
+//		Space         = ' ' .
+//		Status        = /* HTTP status code */ .
+//		BlankLine     = '\n' .
+//		HeaderLine    = /* HTTP Response's header */ '\n' .
+//
+//	Example:
+//		HTTP/1.1 401 Unauthorized
+//		Content-Length: 19
+//		Content-Type: text/plain; charset=utf-8
+//		Date: Thu, 07 Nov 2024 18:43:09 GMT
+//
+// Before the first HTTPS fetch, the go command will invoke each GOAUTH
+// command in the list with no additional arguments and no input.
+// If the server responds with status 401 or 403, the go command will invoke
+// the GOAUTH commands again with the URL as an additional command-line
+// argument and the HTTP response on the command's standard input, and then
+// retry the request once with the resulting credentials.
+// If the server rejects the request again, the fetch fails.
+//
+// Credentials apply to requests whose URLs begin with the given prefix,
+// and the longest matching prefix is used. If more than one command
+// provides credentials for the same prefix, the command listed first
+// in GOAUTH takes precedence.
+//
+// For the netrc method, credentials apply to all HTTPS requests to
+// the named machine.
+//
 // # The go.mod file
 //
 // A module version is defined by a tree of source files, with a go.mod
@@ -2427,6 +2674,13 @@
 // 'go get'. For details, see 'go help module-get' or
 // https://golang.org/ref/mod#go-get.
 //
+// To track a package as a tool of the module, runnable with 'go tool',
+// use 'go get -tool', which adds a tool directive to go.mod:
+//
+//	tool example.com/cmd/tool
+//
+// See 'go help tool'.
+//
 // To make other changes or to parse go.mod as JSON for use by other tools,
 // use 'go mod edit'. See 'go help mod edit' or
 // https://golang.org/ref/mod#go-mod-edit.
@@ -2835,7 +3089,7 @@
 // If no import paths are given, the action applies to the
 // package in the current directory.
 //
-// There are four reserved names for paths that should not be used
+// There are five reserved names for paths that should not be used
 // for packages to be built with the go tool:
 //
 // - "main" denotes the top-level package in a stand-alone executable.
@@ -2844,7 +3098,8 @@
 // trees. For example, 'go list all' lists all the packages on the local
 // system. When using modules, "all" expands to all packages in
 // the main module and their dependencies, including dependencies
-// needed by tests of any of those.
+// needed by tests of any of those, as well as the tools of the main
+// module and their dependencies.
 //
 // - "std" is like all but expands to just the packages in the standard
 // Go library.
@@ -2852,6 +3107,8 @@
 // - "cmd" expands to the Go repository's commands and their
 // internal libraries.
 //
+// - "tool" expands to the tools defined in the current module's go.mod file.
+//
 // Import paths beginning with "cmd/" only match source code in
 // the Go repository.
 //
@@ -2935,6 +3192,9 @@
 // GOPRIVATE applies even in GOPATH mode. In that case, it matches import paths
 // instead of module paths.
 //
+// Private module proxies and servers that require credentials can be
+// configured with the GOAUTH variable; see 'go help goauth'.
+//
 // The 'go env -w' command (see 'go help env') can be used to set these variables
 // for future go command invocations.
 //
@@ -3087,6 +3347,32 @@
 //	    integer N, then N will be used as the seed value. In both cases,
 //	    the seed will be reported for reproducibility.
 //
+//	-shard i/n
+//	    Run only shard i of n (0 <= i < n) of the tests, examples, and
+//	    fuzz tests in each package. Every top-level test, as listed by
+//	    -list, is assigned to exactly one shard, so running each of the
+//	    n shards, for example on different machines, runs every test once.
+//	    The assignment is deterministic and does not depend on the order
+//	    of the tests, so -shuffle randomizes the order of the tests within
+//	    a shard without changing which tests it contains. Without
+//	    -shardtimes, each test is assigned by a hash of its name.
+//	    Benchmarks are not sharded.
+//
+//	-shardpkgs
+//	    With -shard, partition the packages instead of the tests:
+//	    each package is tested entirely within a single shard, and
+//	    the other shards do not build or run its tests.
+//	    Without -shardtimes, each package is assigned by a hash of
+//	    its import path.
+//
+//	-shardtimes file
+//	    Balance the shards selected by -shard using the durations
+//	    recorded in file, which holds the output of a previous
+//	    'go test -json' run. Tests (or, with -shardpkgs, packages)
+//	    are assigned longest first to the shard with the least total
+//	    duration so far. Tests not recorded in the file are assumed to
+//	    take the average recorded duration.
+//
 //	-skip regexp
 //	    Run only those tests, examples, fuzz tests, and benchmarks that
 //	    do not match the regular expression. Like for -run and -bench,
@@ -3132,6 +3418,17 @@
 //	    if -test.blockprofile is set without this flag, all blocking events
 //	    are recorded, equivalent to -test.blockprofilerate=1.
 //
+//	-coverpertest dir
+//	    Write the coverage counters incremented by each top-level test
+//	    to a separate counter data file in dir, along with the coverage
+//	    meta-data file, for use with 'go tool covdata'. Each counter data
+//	    file records the names of the test and its package. Counters
+//	    incremented while tests run in parallel may be attributed to
+//	    any of those tests.
+//	    Sets -cover and, unless -covermode is set, sets -covermode to
+//	    count (atomic when -race is enabled); -covermode=set is not
+//	    allowed.
+//
 //	-coverprofile cover.out
 //	    Write a coverage profile to the file after all tests have passed.
 //	    Sets -cover.
@@ -5,24 +5,181 @@
 // Package auth provides access to user-provided authentication credentials.
 package auth
 
-import "net/http"
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
-// AddCredentials fills in the user's credentials for req, if any.
+	"cmd/go/internal/base"
+	"cmd/go/internal/cfg"
+)
+
+var (
+	credentialCache sync.Map // prefix → http.Header
+	authOnce        sync.Once
+)
+
+// AddCredentials fills in the user's credentials for req, if any,
+// as specified by the GOAUTH environment variable.
 // The return value reports whether any matching credentials were found.
-func AddCredentials(req *http.Request) (added bool) {
-	host := req.Host
-	if host == "" {
-		host = req.URL.Hostname()
-	}
-
-	// TODO(golang.org/issue/26232): Support arbitrary user-provided credentials.
-	netrcOnce.Do(readNetrc)
-	for _, l := range netrc {
-		if l.machine == host {
-			req.SetBasicAuth(l.login, l.password)
-			return true
+//
+// If res is non-nil, it is the server's response to an earlier attempt
+// at req that was rejected for lack of authorization. In that case the
+// GOAUTH commands are invoked again with the URL of req, so that they
+// can supply credentials specific to it.
+//
+// req must use HTTPS.
+func AddCredentials(req *http.Request, res *http.Response) (added bool) {
+	if req.URL.Scheme != "https" {
+		panic("GOAUTH called without https")
+	}
+	if cfg.GOAUTH == "off" {
+		return false
+	}
+	// Run all GOAUTH commands at least once.
+	authOnce.Do(func() {
+		runGoAuth(nil, "")
+	})
+	url := req.URL.String()
+	if res != nil {
+		// The first attempt was rejected; re-invoke the GOAUTH commands for url.
+		runGoAuth(res, url)
+	}
+	return loadCredential(req, url)
+}
+
+// runGoAuth executes the authentication commands listed in GOAUTH,
+// storing the credentials they provide for later use.
+// url is empty for the initial invocation, and otherwise is the URL
+// of a request that the server rejected with response res.
+func runGoAuth(res *http.Response, url string) {
+	var cmdErrs []error // errors to report if no command provides a credential
+	goAuthCmds := strings.Split(cfg.GOAUTH, ";")
+	// Process the commands in reverse order, so that when several commands
+	// provide credentials for the same prefix, the earliest one in the
+	// list takes precedence.
+	slices.Reverse(goAuthCmds)
+	for _, command := range goAuthCmds {
+		command = strings.TrimSpace(command)
+		words := strings.Fields(command)
+		if len(words) == 0 {
+			base.Fatalf("go: GOAUTH encountered an empty command (GOAUTH=%s)", cfg.GOAUTH)
+		}
+		switch words[0] {
+		case "off":
+			if len(goAuthCmds) != 1 {
+				base.Fatalf("go: GOAUTH=off cannot be combined with other authentication commands (GOAUTH=%s)", cfg.GOAUTH)
+			}
+			return
+
+		case "netrc":
+			lines, err := readNetrc()
+			if err != nil {
+				cmdErrs = append(cmdErrs, fmt.Errorf("GOAUTH=%s: %v", command, err))
+				continue
+			}
+			// Store the lines in reverse, so that if the same machine is
+			// listed more than once, the first entry wins, as it did
+			// before GOAUTH existed.
+			for i := len(lines) - 1; i >= 0; i-- {
+				l := lines[i]
+				r := http.Request{Header: make(http.Header)}
+				r.SetBasicAuth(l.login, l.password)
+				storeCredential(l.machine, r.Header)
+			}
+
+		case "git":
+			if len(words) != 2 {
+				base.Fatalf("go: GOAUTH=git dir method requires an absolute path to the git working directory")
+			}
+			dir := words[1]
+			if !filepath.IsAbs(dir) {
+				base.Fatalf("go: GOAUTH=git dir method requires an absolute path to the git working directory, dir is not absolute")
+			}
+			fi, err := os.Stat(dir)
+			if err != nil {
+				base.Fatalf("go: GOAUTH=git encountered an error; cannot stat %s: %v", dir, err)
+			}
+			if !fi.IsDir() {
+				base.Fatalf("go: GOAUTH=git dir method requires an absolute path to the git working directory, dir is not a directory")
+			}
+			if url == "" {
+				// 'git credential' has no way to enumerate credentials,
+				// so wait for a request for a specific URL.
+				continue
+			}
+			prefix, header, err := runGitAuth(dir, url)
+			if err != nil {
+				cmdErrs = append(cmdErrs, fmt.Errorf("GOAUTH=%s: %v", command, err))
+				continue
+			}
+			storeCredential(prefix, header)
+
+		default:
+			credentials, err := runAuthCommand(command, url, res)
+			if err != nil {
+				cmdErrs = append(cmdErrs, fmt.Errorf("GOAUTH=%s: %v", command, err))
+				continue
+			}
+			for prefix, header := range credentials {
+				storeCredential(prefix, header)
+			}
 		}
 	}
 
+	// If no command provided a credential for url, report
+	// the errors that may explain why.
+	if cfg.BuildX && url != "" && len(cmdErrs) > 0 {
+		req := &http.Request{Header: make(http.Header)}
+		if !loadCredential(req, url) {
+			log.Printf("GOAUTH encountered errors for %s:", url)
+			for _, err := range cmdErrs {
+				log.Printf("  %v", err)
+			}
+		}
+	}
+}
+
+// loadCredential adds the stored credentials for the longest
+// matching prefix of url to the headers of req.
+// It reports whether any credentials were found.
+func loadCredential(req *http.Request, url string) bool {
+	prefix := strings.TrimPrefix(url, "https://")
+	if i := strings.IndexAny(prefix, "?#"); i >= 0 {
+		prefix = prefix[:i]
+	}
+	// Try each prefix in turn, moving up the path hierarchy.
+	for prefix != "/" && prefix != "." && prefix != "" {
+		header, ok := credentialCache.Load(prefix)
+		if !ok {
+			prefix = path.Dir(prefix)
+			continue
+		}
+		for key, values := range header.(http.Header) {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+		return true
+	}
 	return false
 }
+
+// storeCredential records header as the credentials to use for
+// requests to URLs beginning with prefix. An empty header removes
+// any stored credentials for prefix.
+func storeCredential(prefix string, header http.Header) {
+	// Trim the scheme to match the form of netrc machine names.
+	prefix = strings.TrimSuffix(strings.TrimPrefix(prefix, "https://"), "/")
+	if len(header) == 0 {
+		credentialCache.Delete(prefix)
+	} else {
+		credentialCache.Store(prefix, header)
+	}
+}
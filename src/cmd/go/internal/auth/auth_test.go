@@ -0,0 +1,49 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCredentialCache(t *testing.T) {
+	testCases := []netrcLine{
+		{"api.github.com", "user", "pwd"},
+		{"test.host", "user2", "pwd2"},
+		{"oneline", "user3", "pwd3"},
+		{"hasmacro.too", "user4", "pwd4"},
+	}
+	for _, tc := range testCases {
+		want := http.Request{Header: make(http.Header)}
+		want.SetBasicAuth(tc.login, tc.password)
+		storeCredential(tc.machine, want.Header)
+		got := &http.Request{Header: make(http.Header)}
+		ok := loadCredential(got, "https://"+tc.machine+"/path/to/module")
+		if !ok || !reflect.DeepEqual(got.Header, want.Header) {
+			t.Errorf("loadCredential(%q):\nhave %q\nwant %q", tc.machine, got.Header, want.Header)
+		}
+	}
+
+	// Having stored those credentials, we should be able to look up the
+	// most specific prefix for a URL.
+	specific := http.Header{"Authorization": {"Bearer token"}}
+	storeCredential("https://test.host/private/", specific)
+	got := &http.Request{Header: make(http.Header)}
+	if ok := loadCredential(got, "https://test.host/private/mod/@v/list"); !ok || !reflect.DeepEqual(got.Header, specific) {
+		t.Errorf("loadCredential for nested prefix:\nhave %q\nwant %q", got.Header, specific)
+	}
+
+	// Storing an empty header removes the credentials for a prefix.
+	storeCredential("https://test.host/private/", nil)
+	got = &http.Request{Header: make(http.Header)}
+	if ok := loadCredential(got, "https://test.host/private/mod/@v/list"); !ok || got.Header.Get("Authorization") == "Bearer token" {
+		t.Errorf("loadCredential after deleting nested prefix: have %q, want credentials for test.host", got.Header)
+	}
+	if ok := loadCredential(&http.Request{Header: make(http.Header)}, "https://other.host/"); ok {
+		t.Errorf("loadCredential(%q) found credentials, want none", "https://other.host/")
+	}
+}
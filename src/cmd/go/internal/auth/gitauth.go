@@ -0,0 +1,79 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// runGitAuth retrieves credentials for the given url using
+// 'git credential fill' run in dir.
+// It returns the URL prefix the credentials apply to and an
+// http.Header carrying them as Basic Authentication.
+func runGitAuth(dir, url string) (string, http.Header, error) {
+	if dir == "" {
+		// Prevent config-injection attacks by requiring an explicit working directory.
+		// See https://golang.org/issue/29230 for details.
+		panic("'git' invoked in an arbitrary directory") // this should be caught earlier.
+	}
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("url=%s\n", url))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", nil, fmt.Errorf("'git credential fill' failed (url=%s): %w\n%s", url, err, out)
+	}
+	prefix, username, password := parseGitAuth(out)
+	if prefix == "" {
+		return "", nil, fmt.Errorf("'git credential fill' failed for url=%s, could not parse url", url)
+	}
+	// Check that the URL Git gave us is a prefix of the one we requested.
+	if !strings.HasPrefix(url, prefix) {
+		return "", nil, fmt.Errorf("requested a credential for %s, but 'git credential fill' provided one for %s", url, prefix)
+	}
+	req := &http.Request{Header: make(http.Header)}
+	req.SetBasicAuth(username, password)
+	return prefix, req.Header, nil
+}
+
+// parseGitAuth parses the output of 'git credential fill', extracting
+// the URL prefix, user, and password.
+// Any of these values may be empty if parsing fails.
+func parseGitAuth(data []byte) (prefix, username, password string) {
+	u := new(url.URL)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "protocol":
+			u.Scheme = value
+		case "host":
+			u.Host = value
+		case "path":
+			u.Path = value
+		case "username":
+			username = value
+		case "password":
+			password = value
+		case "url":
+			// Parse into a separate variable: if the url field is malformed,
+			// keep what we learned from the protocol, host, and path fields.
+			pu, err := url.ParseRequestURI(value)
+			if err != nil {
+				log.Printf("malformed URL from 'git credential fill' (%v): %q\n", err, value)
+				continue
+			}
+			u = pu
+		}
+	}
+	return u.String(), username, password
+}
@@ -18,11 +18,22 @@ type netrcLine struct {
 	password string
 }
 
-var (
-	netrcOnce sync.Once
-	netrc     []netrcLine
-	netrcErr  error
-)
+var readNetrc = sync.OnceValues(func() ([]netrcLine, error) {
+	path, err := netrcPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	return parseNetrc(string(data)), nil
+})
 
 func parseNetrc(data string) []netrcLine {
 	// See https://www.gnu.org/software/inetutils/manual/html_node/The-_002enetrc-file.html
@@ -90,21 +101,3 @@ func netrcPath() (string, error) {
 	}
 	return filepath.Join(dir, base), nil
 }
-
-func readNetrc() {
-	path, err := netrcPath()
-	if err != nil {
-		netrcErr = err
-		return
-	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			netrcErr = err
-		}
-		return
-	}
-
-	netrc = parseNetrc(string(data))
-}
@@ -11,6 +11,7 @@ import (
 	"internal/testenv"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 )
@@ -150,6 +151,55 @@ func dummyID(x int) [HashSize]byte {
 	return out
 }
 
+func TestPutExecutable(t *testing.T) {
+	dir, err := os.MkdirTemp("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	id := ActionID(dummyID(1))
+	out, size, err := c.PutExecutable(id, "tool", bytes.NewReader([]byte("binary")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 6 {
+		t.Fatalf("PutExecutable size = %d, want 6", size)
+	}
+
+	file, entry, err := GetFile(c, id)
+	if err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	if entry.OutputID != out {
+		t.Fatalf("GetFile OutputID = %x, want %x", entry.OutputID, out)
+	}
+	if filepath.Base(file) != "tool" {
+		t.Fatalf("GetFile file = %s, want base name tool", file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "binary" {
+		t.Fatalf("cached executable = %q, want %q", data, "binary")
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode()&0111 == 0 {
+			t.Fatalf("cached executable mode = %v, want executable", info.Mode())
+		}
+	}
+}
+
 func TestCacheTrim(t *testing.T) {
 	dir, err := os.MkdirTemp("", "cachetest-")
 	if err != nil {
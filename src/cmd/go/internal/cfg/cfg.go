@@ -77,6 +77,7 @@ var (
 	BuildCover         bool                    // -cover flag
 	BuildCoverMode     string                  // -covermode flag
 	BuildCoverPkg      []string                // -coverpkg flag
+	BuildJSON          bool                    // -json flag
 	BuildN             bool                    // -n flag
 	BuildO             string                  // -o flag
 	BuildP             = runtime.GOMAXPROCS(0) // -p flag
@@ -417,6 +418,7 @@ var (
 	GONOSUMDB  = envOr("GONOSUMDB", GOPRIVATE)
 	GOINSECURE = Getenv("GOINSECURE")
 	GOVCS      = Getenv("GOVCS")
+	GOAUTH     = envOr("GOAUTH", "netrc")
 )
 
 var SumdbDir = gopathDir("pkg/sumdb")
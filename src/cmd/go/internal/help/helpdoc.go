@@ -49,7 +49,7 @@ environment variable (For more details see: 'go help gopath').
 If no import paths are given, the action applies to the
 package in the current directory.
 
-There are four reserved names for paths that should not be used
+There are five reserved names for paths that should not be used
 for packages to be built with the go tool:
 
 - "main" denotes the top-level package in a stand-alone executable.
@@ -58,7 +58,8 @@ for packages to be built with the go tool:
 trees. For example, 'go list all' lists all the packages on the local
 system. When using modules, "all" expands to all packages in
 the main module and their dependencies, including dependencies
-needed by tests of any of those.
+needed by tests of any of those, as well as the tools of the main
+module and their dependencies.
 
 - "std" is like all but expands to just the packages in the standard
 Go library.
@@ -66,6 +67,8 @@ Go library.
 - "cmd" expands to the Go repository's commands and their
 internal libraries.
 
+- "tool" expands to the tools defined in the current module's go.mod file.
+
 Import paths beginning with "cmd/" only match source code in
 the Go repository.
 
@@ -493,6 +496,9 @@ General-purpose environment variables:
 	GOARCH
 		The architecture, or processor, for which to compile code.
 		Examples are amd64, 386, arm, ppc64.
+	GOAUTH
+		Controls authentication for go-import and HTTPS module mirror interactions.
+		See 'go help goauth'.
 	GOBIN
 		The directory where 'go install' will install a command.
 	GOCACHE
@@ -825,6 +831,145 @@ decisions about whether to reuse a cached test result.
 `,
 }
 
+var HelpBuildJSON = &base.Command{
+	UsageLine: "buildjson",
+	Short:     "build -json encoding",
+	Long: `
+The 'go build', 'go install', and 'go test' commands take a -json flag
+that reports build output and failures as structured JSON output on
+standard output.
+
+The JSON stream is a newline-separated sequence of BuildEvent objects
+corresponding to the Go struct:
+
+	type BuildEvent struct {
+		ImportPath string
+		Action     string
+		Mode       string
+		Cached     bool
+		Output     string
+		File       string
+		Line       int
+		Col        int
+	}
+
+The ImportPath field gives the package ID of the package being built.
+This matches the Package.ImportPath field of go list -json and the
+TestEvent.FailedBuild field of go test -json. Note that it does not
+match TestEvent.Package.
+
+The Action field is one of the following:
+
+	build-start  - the package has started compiling or linking
+	build-end    - the package has finished compiling or linking
+	build-output - the toolchain printed output
+	build-fail   - the build failed
+
+The Mode field is set for "build-start" and "build-end" events,
+and is "build" for compiling a package or "link" for linking
+an executable.
+
+The Cached field is set for "build-end" events if the result
+was found in the build cache or in an up-to-date installed
+target, so that no compiling or linking was needed.
+
+The Output field is set for Action == "build-output" and is a line of
+the build's output. The concatenation of the Output fields of all output
+events is the exact output of the build. There may be more than one
+output event for a given ImportPath. This matches the definition of
+the TestEvent.Output field produced by go test -json.
+
+If a line of output is a diagnostic of the form "file:line:col: message"
+or "file:line: message", the File, Line and Col fields give its position.
+
+With go test -json, build events are reported unless GODEBUG
+contains gotestjsonbuildtext=1, in which case build output is
+printed as text on standard error, as in earlier releases.
+This struct is designed so that parsers can distinguish
+interleaved TestEvents and BuildEvents by inspecting the Action field.
+Furthermore, as with TestEvent, parsers can simply concatenate the Output
+fields of all events to reconstruct the text format output, as it would
+have appeared from go build without the -json flag.
+
+Note that there may also be non-JSON error text on standard error, even
+with the -json flag. Typically, this indicates an early, serious error.
+Consumers should be robust to this.
+`,
+}
+
+var HelpGoAuth = &base.Command{
+	UsageLine: "goauth",
+	Short:     "GOAUTH environment variable",
+	Long: `
+GOAUTH is a semicolon-separated list of authentication commands for go-import and
+HTTPS module mirror interactions. The default is netrc.
+
+The supported authentication commands are:
+
+off
+	Disables authentication.
+netrc
+	Uses credentials from NETRC or the .netrc file in your home directory.
+git dir
+	Runs 'git credential fill' in dir and uses its credentials.
+	dir must be an absolute path to a git working directory.
+command
+	Executes the given command (a space-separated argument list) and attaches
+	the provided headers to HTTPS requests.
+	The command must produce output in the following format:
+		Response      = { CredentialSet } .
+		CredentialSet = URLLine { URLLine } BlankLine { HeaderLine } BlankLine .
+		URLLine       = /* URL that starts with "https://" */ '\n' .
+		HeaderLine    = /* HTTP Request header */ '\n' .
+		BlankLine     = '\n' .
+
+	Example:
+		https://example.com
+		https://example.net/api/
+
+		Authorization: Basic <token>
+
+		https://another-example.org/
+
+		Example: Data
+
+	A credential set with no headers removes any credentials
+	previously provided for its URLs.
+
+	If the server rejects a request with status 401 or 403, the go command
+	writes the response to the command's standard input:
+		Response      = StatusLine { HeaderLine } BlankLine .
+		StatusLine    = Protocol Space Status '\n' .
+		Protocol      = /* HTTP protocol */ .
+		Space         = ' ' .
+		Status        = /* HTTP status code */ .
+		BlankLine     = '\n' .
+		HeaderLine    = /* HTTP Response's header */ '\n' .
+
+	Example:
+		HTTP/1.1 401 Unauthorized
+		Content-Length: 19
+		Content-Type: text/plain; charset=utf-8
+		Date: Thu, 07 Nov 2024 18:43:09 GMT
+
+Before the first HTTPS fetch, the go command will invoke each GOAUTH
+command in the list with no additional arguments and no input.
+If the server responds with status 401 or 403, the go command will invoke
+the GOAUTH commands again with the URL as an additional command-line
+argument and the HTTP response on the command's standard input, and then
+retry the request once with the resulting credentials.
+If the server rejects the request again, the fetch fails.
+
+Credentials apply to requests whose URLs begin with the given prefix,
+and the longest matching prefix is used. If more than one command
+provides credentials for the same prefix, the command listed first
+in GOAUTH takes precedence.
+
+For the netrc method, credentials apply to all HTTPS requests to
+the named machine.
+`,
+}
+
 var HelpBuildConstraint = &base.Command{
 	UsageLine: "buildconstraint",
 	Short:     "build constraints",
@@ -3038,9 +3038,13 @@ func CheckPackageErrors(pkgs []*Package) {
 	}
 	if anyIncomplete {
 		all := PackageList(pkgs)
+		byPath := make(map[string]*Package, len(all))
+		for _, p := range all {
+			byPath[p.ImportPath] = p
+		}
 		for _, p := range all {
 			if p.Error != nil {
-				base.Errorf("%v", p.Error)
+				DefaultPrinter().Errorf(errorPackage(p, byPath), "%v", p.Error)
 			}
 		}
 	}
@@ -3070,6 +3074,19 @@ func CheckPackageErrors(pkgs []*Package) {
 	base.ExitIfErrors()
 }
 
+// errorPackage returns the package that the load error of p is reported
+// for: the importing package if the error is in its import declaration,
+// as for a package that does not exist, or p itself otherwise.
+func errorPackage(p *Package, byPath map[string]*Package) *Package {
+	stk := p.Error.ImportStack
+	if len(stk) > 0 && stk[len(stk)-1] != p.ImportPath {
+		if importer := byPath[stk[len(stk)-1]]; importer != nil {
+			return importer
+		}
+	}
+	return p
+}
+
 // mainPackagesOnly filters out non-main packages matched only by arguments
 // containing "..." and returns the remaining main packages.
 //
@@ -0,0 +1,195 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/cfg"
+)
+
+// A Printer reports output about a Package.
+type Printer interface {
+	// Printf reports output from building pkg. The arguments are of the form
+	// expected by [fmt.Printf].
+	//
+	// pkg may be nil if this output is not associated with the build of a
+	// particular package.
+	//
+	// The caller is responsible for checking if printing output is appropriate,
+	// for example by checking cfg.BuildN or cfg.BuildV.
+	Printf(pkg *Package, format string, args ...any)
+
+	// Errorf prints output in the form of `log.Errorf` and reports that
+	// building pkg failed.
+	//
+	// This ensures the output is terminated with a new line if there's any
+	// output, but does not do any other formatting. Callers should generally
+	// use a higher-level output abstraction, such as (*Shell).reportCmd.
+	//
+	// pkg may be nil if this output is not associated with the build of a
+	// particular package.
+	//
+	// This sets the process exit status to 1.
+	Errorf(pkg *Package, format string, args ...any)
+
+	// Event reports that the build action with the given mode
+	// (such as "build" or "link") for pkg has started or ended.
+	// action is one of "build-start" or "build-end".
+	// cached reports whether an ended action was satisfied
+	// from the build cache or an up-to-date install target.
+	Event(pkg *Package, mode, action string, cached bool)
+}
+
+// DefaultPrinter returns the default Printer.
+func DefaultPrinter() Printer {
+	return defaultPrinter()
+}
+
+var defaultPrinter = sync.OnceValue(func() Printer {
+	if cfg.BuildJSON {
+		return NewJSONPrinter(os.Stdout)
+	}
+	return &TextPrinter{os.Stderr}
+})
+
+func ensureNewline(s string) string {
+	if s == "" {
+		return ""
+	}
+	if !strings.HasSuffix(s, "\n") {
+		return s + "\n"
+	}
+	return s
+}
+
+// A TextPrinter emits text format output to Writer.
+type TextPrinter struct {
+	Writer io.Writer
+}
+
+func (p *TextPrinter) Printf(_ *Package, format string, args ...any) {
+	fmt.Fprintf(p.Writer, format, args...)
+}
+
+func (p *TextPrinter) Errorf(_ *Package, format string, args ...any) {
+	fmt.Fprint(p.Writer, ensureNewline(fmt.Sprintf(format, args...)))
+	base.SetExitStatus(1)
+}
+
+func (p *TextPrinter) Event(*Package, string, string, bool) {}
+
+// A JSONPrinter emits output about a build in JSON format.
+type JSONPrinter struct {
+	enc *json.Encoder
+}
+
+func NewJSONPrinter(w io.Writer) *JSONPrinter {
+	return &JSONPrinter{json.NewEncoder(w)}
+}
+
+// A jsonBuildEvent is a single event reported by a build-like
+// command run with -json. See 'go help buildjson'.
+type jsonBuildEvent struct {
+	ImportPath string
+	Action     string
+	Mode       string `json:",omitempty"` // for build-start and build-end
+	Cached     bool   `json:",omitempty"` // for build-end
+	Output     string `json:",omitempty"` // for build-output
+	File       string `json:",omitempty"` // position of the diagnostic in Output, if any
+	Line       int    `json:",omitempty"`
+	Col        int    `json:",omitempty"`
+}
+
+func (p *JSONPrinter) Printf(pkg *Package, format string, args ...any) {
+	ev := &jsonBuildEvent{
+		Action: "build-output",
+	}
+	if pkg != nil {
+		ev.ImportPath = pkg.Desc()
+	}
+	// Report each line of output in its own event, so that
+	// diagnostics can carry their source position.
+	for _, line := range strings.SplitAfter(fmt.Sprintf(format, args...), "\n") {
+		if line == "" {
+			continue
+		}
+		ev.Output = line
+		ev.File, ev.Line, ev.Col = parsePos(line)
+		p.emit(ev)
+	}
+}
+
+func (p *JSONPrinter) Errorf(pkg *Package, format string, args ...any) {
+	s := ensureNewline(fmt.Sprintf(format, args...))
+	// For clarity, emit each line as a separate output event.
+	p.Printf(pkg, "%s", s)
+	ev := &jsonBuildEvent{
+		Action: "build-fail",
+	}
+	if pkg != nil {
+		ev.ImportPath = pkg.Desc()
+	}
+	p.emit(ev)
+	base.SetExitStatus(1)
+}
+
+func (p *JSONPrinter) Event(pkg *Package, mode, action string, cached bool) {
+	ev := &jsonBuildEvent{
+		Action: action,
+		Mode:   mode,
+		Cached: cached,
+	}
+	if pkg != nil {
+		ev.ImportPath = pkg.Desc()
+	}
+	p.emit(ev)
+}
+
+func (p *JSONPrinter) emit(ev *jsonBuildEvent) {
+	if err := p.enc.Encode(ev); err != nil {
+		// Should be impossible.
+		fmt.Fprintf(os.Stderr, "error encoding JSON build event: %v\n", err)
+	}
+}
+
+// parsePos returns the source position at the start of a line of
+// compiler or linker output of the form "file:line:col: message"
+// or "file:line: message", or zero values if there is none.
+func parsePos(line string) (file string, lineno, col int) {
+	i := strings.Index(line, ": ")
+	if i < 0 {
+		return "", 0, 0
+	}
+	pos := line[:i]
+	// Split off up to two trailing numeric fields.
+	var nums []int
+	for len(nums) < 2 {
+		j := strings.LastIndex(pos, ":")
+		if j < 0 {
+			break
+		}
+		n, err := strconv.Atoi(pos[j+1:])
+		if err != nil || n <= 0 {
+			break
+		}
+		nums = append(nums, n)
+		pos = pos[:j]
+	}
+	if len(nums) == 0 || pos == "" || strings.ContainsAny(pos, " \t") {
+		return "", 0, 0
+	}
+	if len(nums) == 2 {
+		return pos, nums[1], nums[0]
+	}
+	return pos, nums[0], 0
+}
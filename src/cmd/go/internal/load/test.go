@@ -958,6 +958,12 @@ func testing_registerCover2(mode string, tearDown func(coverprofile string, goco
 //go:linkname runtime_coverage_markProfileEmitted runtime/coverage.markProfileEmitted
 func runtime_coverage_markProfileEmitted(val bool)
 
+//go:linkname testing_registerCoverPerTest testing.registerCoverPerTest
+func testing_registerCoverPerTest(f func(dir string, test string) error)
+
+//go:linkname runtime_coverage_writeTestCounters runtime/coverage.writeTestCounters
+func runtime_coverage_writeTestCounters(dir string, pkg string, test string) error
+
 //go:linkname runtime_coverage_snapshot runtime/coverage.snapshot
 func runtime_coverage_snapshot() float64
 
@@ -977,11 +983,16 @@ func coverTearDown(coverprofile string, gocoverdir string) (string, error) {
 	}
 	return "", nil
 }
+
+func coverPerTest(dir string, test string) error {
+	return runtime_coverage_writeTestCounters(dir, {{printf "%q" .Package.ImportPath}}, test)
+}
 {{end}}
 
 func main() {
 {{if .Cover}}
 	testing_registerCover2({{printf "%q" .Cover.Mode}}, coverTearDown, runtime_coverage_snapshot)
+	testing_registerCoverPerTest(coverPerTest)
 {{end}}
 	m := testing.MainStart(testdeps.TestDeps{}, tests, benchmarks, fuzzTargets, examples)
 {{with .TestMain}}
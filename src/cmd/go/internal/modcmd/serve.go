@@ -0,0 +1,337 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modcmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/cfg"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+var cmdServe = &base.Command{
+	UsageLine: "go mod serve [-http addr] [-dir dir]...",
+	Short:     "serve the module cache as a module proxy",
+	Long: `
+Serve serves modules from the module cache as a read-only module proxy
+that implements the GOPROXY protocol, for use by other go commands,
+for example on machines without network access.
+See https://golang.org/ref/mod#goproxy-protocol for the protocol.
+
+The -http flag sets the TCP address on which to serve.
+The default is localhost:8080. The address of the server is
+printed to standard error when it starts.
+
+By default, serve serves the modules downloaded to the module cache,
+$GOMODCACHE/cache/download. The -dir flag serves the modules in the
+given directory instead, which must have the same layout. Such a
+directory can be created by copying the module cache download directory
+after running 'go mod download', or by setting GOMODCACHE to the
+directory while downloading. The -dir flag may be repeated to serve
+several directories; for each request, the directories are searched
+in order.
+
+Serve does not serve vendor directories. A vendor directory holds only
+the packages needed to build the main module, not complete modules, so
+the module zip files served from it would not match the checksums
+recorded in go.sum files and in the checksum database.
+
+For each module, the server answers requests for the list of
+available versions ($module/@v/list), the latest version
+($module/@latest), and a version's .info, .mod, and .zip files
+($module/@v/$version.info and so on). The list includes only versions
+whose .info file is present, and excludes pseudo-versions. The latest
+version is the highest release version, or, if there are none, the
+highest pre-release version or pseudo-version.
+
+The server also proxies the checksum database (see 'go help
+module-auth'): it serves the lookups and tiles that the go command
+cached in the sumdb subdirectory of each directory while verifying
+the modules it downloaded, and the latest signed tree head from
+$GOPATH/pkg/sumdb (or the sumdb subdirectory). Clients can therefore
+verify the modules being served without access to the checksum
+database itself.
+	`,
+}
+
+var (
+	serveHTTP = cmdServe.Flag.String("http", "localhost:8080", "")
+	serveDirs []string // -dir flags
+)
+
+func init() {
+	cmdServe.Run = runServe // break init cycle
+	cmdServe.Flag.Var(flagFunc(func(dir string) { serveDirs = append(serveDirs, dir) }), "dir", "")
+	base.AddChdirFlag(&cmdServe.Flag)
+}
+
+func runServe(ctx context.Context, cmd *base.Command, args []string) {
+	if len(args) > 0 {
+		base.Fatalf("go: 'go mod serve' accepts no arguments")
+	}
+	dirs := serveDirs
+	if len(dirs) == 0 {
+		if cfg.GOMODCACHE == "" {
+			base.Fatalf("go: module cache not found: neither GOMODCACHE nor GOPATH is set")
+		}
+		dirs = []string{filepath.Join(cfg.GOMODCACHE, "cache/download")}
+	}
+	for i, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			base.Fatal(err)
+		}
+		if fi, err := os.Stat(abs); err != nil {
+			base.Fatal(err)
+		} else if !fi.IsDir() {
+			base.Fatalf("go: %s is not a directory", dir)
+		}
+		dirs[i] = abs
+	}
+
+	ln, err := net.Listen("tcp", *serveHTTP)
+	if err != nil {
+		base.Fatal(err)
+	}
+	fmt.Fprintf(os.Stderr, "go: serving %s at http://%s/\n", strings.Join(dirs, string(filepath.ListSeparator)), ln.Addr())
+	base.Fatal(http.Serve(ln, &proxyHandler{dirs: dirs, sumdbDir: cfg.SumdbDir}))
+}
+
+// A proxyHandler serves the module download directories dirs
+// using the GOPROXY protocol.
+type proxyHandler struct {
+	dirs     []string
+	sumdbDir string // directory holding the latest signed tree head of each checksum database, or ""
+}
+
+func (h *proxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/")
+
+	if rest, ok := strings.CutPrefix(path, "sumdb/"); ok {
+		h.serveSumdb(w, r, rest)
+		return
+	}
+
+	if enc, ok := strings.CutSuffix(path, "/@latest"); ok {
+		h.serveLatest(w, r, enc)
+		return
+	}
+	enc, file, ok := strings.Cut(path, "/@v/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if file == "list" {
+		h.serveList(w, r, enc)
+		return
+	}
+	ext := filepath.Ext(file)
+	switch ext {
+	case ".info", ".mod", ".zip":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if _, err := unescapeModule(enc, strings.TrimSuffix(file, ext)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	contentType := "text/plain; charset=UTF-8"
+	switch ext {
+	case ".info":
+		contentType = "application/json"
+	case ".zip":
+		contentType = "application/zip"
+	}
+	h.serveFile(w, r, contentType, filepath.Join(filepath.FromSlash(enc), "@v", file))
+}
+
+// unescapeModule returns the module with the given escaped path and version,
+// or an error if they are not valid.
+func unescapeModule(enc, encVersion string) (module.Version, error) {
+	path, err := module.UnescapePath(enc)
+	if err != nil {
+		return module.Version{}, err
+	}
+	version, err := module.UnescapeVersion(encVersion)
+	if err != nil {
+		return module.Version{}, err
+	}
+	if err := module.Check(path, version); err != nil {
+		return module.Version{}, err
+	}
+	return module.Version{Path: path, Version: version}, nil
+}
+
+// serveFile serves the file with the given name, relative to the
+// first directory that contains it.
+func (h *proxyHandler) serveFile(w http.ResponseWriter, r *http.Request, contentType, name string) {
+	var candidates []string
+	for _, dir := range h.dirs {
+		candidates = append(candidates, filepath.Join(dir, name))
+	}
+	serveFirst(w, r, contentType, candidates)
+}
+
+// serveFirst serves the first of the named files that exists.
+func serveFirst(w http.ResponseWriter, r *http.Request, contentType string, names []string) {
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		fi, err := f.Stat()
+		if err != nil || !fi.Mode().IsRegular() {
+			f.Close()
+			continue
+		}
+		w.Header().Set("Content-Type", contentType)
+		http.ServeContent(w, r, name, fi.ModTime(), f)
+		f.Close()
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// versions returns the versions of the module with escaped path enc
+// whose .info files are present in any of the directories.
+// It reports whether any directory has an entry for the module at all.
+func (h *proxyHandler) versions(enc string) (versions []string, found bool, err error) {
+	if _, err := module.UnescapePath(enc); err != nil {
+		return nil, false, err
+	}
+	seen := make(map[string]bool)
+	for _, dir := range h.dirs {
+		entries, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(enc), "@v"))
+		if err != nil {
+			continue
+		}
+		found = true
+		for _, e := range entries {
+			encVersion, ok := strings.CutSuffix(e.Name(), ".info")
+			if !ok || e.IsDir() {
+				continue
+			}
+			v, err := module.UnescapeVersion(encVersion)
+			if err != nil || !semver.IsValid(v) || seen[v] {
+				continue
+			}
+			seen[v] = true
+			versions = append(versions, v)
+		}
+	}
+	semver.Sort(versions)
+	return versions, found, nil
+}
+
+func (h *proxyHandler) serveList(w http.ResponseWriter, r *http.Request, enc string) {
+	versions, found, err := h.versions(enc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	for _, v := range versions {
+		if !module.IsPseudoVersion(v) {
+			fmt.Fprintln(w, v)
+		}
+	}
+}
+
+func (h *proxyHandler) serveLatest(w http.ResponseWriter, r *http.Request, enc string) {
+	versions, _, err := h.versions(enc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Prefer releases, then pre-releases, then pseudo-versions,
+	// as the go command does when resolving the "latest" query.
+	latest := ""
+	for _, v := range versions {
+		if module.IsPseudoVersion(v) {
+			continue
+		}
+		if semver.Prerelease(v) == "" || latest == "" || semver.Prerelease(latest) != "" {
+			latest = v
+		}
+	}
+	if latest == "" && len(versions) > 0 {
+		latest = versions[len(versions)-1]
+	}
+	if latest == "" {
+		http.NotFound(w, r)
+		return
+	}
+	encVersion, err := module.EscapeVersion(latest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.serveFile(w, r, "application/json", filepath.Join(filepath.FromSlash(enc), "@v", encVersion+".info"))
+}
+
+// serveSumdb serves a request for $GOPROXY/sumdb/path
+// from the checksum database data cached in the directories.
+func (h *proxyHandler) serveSumdb(w http.ResponseWriter, r *http.Request, path string) {
+	// path is <name>/supported, <name>/latest, <name>/lookup/..., or <name>/tile/...,
+	// where <name> is a host name with an optional path.
+	name, file := "", ""
+	for _, op := range []string{"/supported", "/latest", "/lookup/", "/tile/"} {
+		if i := strings.Index(path, op); i > 0 {
+			name, file = path[:i], path[i+1:]
+			break
+		}
+	}
+	if name == "" || !fs.ValidPath(path) || strings.Contains(path, "\\") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case file == "supported":
+		for _, dir := range h.dirs {
+			if fi, err := os.Stat(filepath.Join(dir, "sumdb", filepath.FromSlash(name))); err == nil && fi.IsDir() {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+		http.NotFound(w, r)
+
+	case file == "latest":
+		var candidates []string
+		for _, dir := range h.dirs {
+			candidates = append(candidates, filepath.Join(dir, "sumdb", filepath.FromSlash(name), "latest"))
+		}
+		if h.sumdbDir != "" {
+			candidates = append(candidates, filepath.Join(h.sumdbDir, filepath.FromSlash(name), "latest"))
+		}
+		serveFirst(w, r, "text/plain; charset=UTF-8", candidates)
+
+	case strings.HasPrefix(file, "lookup/"):
+		h.serveFile(w, r, "text/plain; charset=UTF-8", filepath.Join("sumdb", filepath.FromSlash(name), filepath.FromSlash(file)))
+
+	default:
+		h.serveFile(w, r, "application/octet-stream", filepath.Join("sumdb", filepath.FromSlash(name), filepath.FromSlash(file)))
+	}
+}
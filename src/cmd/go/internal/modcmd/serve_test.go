@@ -0,0 +1,93 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modcmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProxyHandler(t *testing.T) {
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	sumdbDir := t.TempDir()
+	files := map[string]string{
+		dir1 + "/example.com/m/@v/v1.0.0.info":                                  `{"Version":"v1.0.0"}`,
+		dir1 + "/example.com/m/@v/v1.0.0.mod":                                   "module example.com/m\n",
+		dir1 + "/example.com/m/@v/v1.0.0.zip":                                   "zip",
+		dir1 + "/example.com/m/@v/v1.1.0-pre.info":                              `{"Version":"v1.1.0-pre"}`,
+		dir1 + "/example.com/m/@v/v1.2.0.mod":                                   "module example.com/m\n", // no .info: not listed
+		dir2 + "/example.com/m/@v/v1.0.1.info":                                  `{"Version":"v1.0.1"}`,
+		dir2 + "/example.com/m/@v/v0.0.0-20240101000000-abcdefabcdef.info":      `{"Version":"v0.0.0-20240101000000-abcdefabcdef"}`,
+		dir2 + "/example.com/!upper/@v/v0.1.0-pre.info":                         `{"Version":"v0.1.0-pre"}`,
+		dir2 + "/example.com/pseudo/@v/v0.0.0-20240101000000-abcdefabcdef.info": `{"Version":"v0.0.0-20240101000000-abcdefabcdef"}`,
+		dir1 + "/sumdb/sum.golang.org/lookup/example.com/m@v1.0.0":              "lookup",
+		dir1 + "/sumdb/sum.golang.org/tile/8/0/000":                             "tile",
+		sumdbDir + "/sum.golang.org/latest":                                     "latest",
+	}
+	for name, data := range files {
+		name = filepath.FromSlash(name)
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := httptest.NewServer(&proxyHandler{dirs: []string{dir1, dir2}, sumdbDir: sumdbDir})
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/example.com/m/@v/list", 200, "v1.0.0\nv1.0.1\nv1.1.0-pre\n"},
+		{"/example.com/m/@latest", 200, `{"Version":"v1.0.1"}`},
+		{"/example.com/m/@v/v1.0.0.info", 200, `{"Version":"v1.0.0"}`},
+		{"/example.com/m/@v/v1.0.0.mod", 200, "module example.com/m\n"},
+		{"/example.com/m/@v/v1.0.0.zip", 200, "zip"},
+		{"/example.com/m/@v/v1.0.1.info", 200, `{"Version":"v1.0.1"}`},
+		{"/example.com/m/@v/v1.0.2.info", 404, ""},
+		{"/example.com/m/@v/v1.0.0.txt", 404, ""},
+		{"/example.com/m/@v/bad.info", 400, ""},
+		{"/example.com/m/@v/..%2f..%2fv1.0.0.info", 400, ""},
+		{"/example.com/!upper/@v/list", 200, "v0.1.0-pre\n"},
+		{"/example.com/!upper/@latest", 200, `{"Version":"v0.1.0-pre"}`},
+		{"/example.com/pseudo/@v/list", 200, ""},
+		{"/example.com/pseudo/@latest", 200, `{"Version":"v0.0.0-20240101000000-abcdefabcdef"}`},
+		{"/example.com/missing/@v/list", 404, ""},
+		{"/example.com/missing/@latest", 404, ""},
+		{"/sumdb/sum.golang.org/supported", 200, ""},
+		{"/sumdb/sum.golang.org/latest", 200, "latest"},
+		{"/sumdb/sum.golang.org/lookup/example.com/m@v1.0.0", 200, "lookup"},
+		{"/sumdb/sum.golang.org/tile/8/0/000", 200, "tile"},
+		{"/sumdb/sum.golang.org/tile/8/0/001", 404, ""},
+		{"/sumdb/other.example.com/supported", 404, ""},
+		{"/sumdb/sum.golang.org/tile/../../../x", 404, ""},
+	} {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.code {
+			t.Errorf("GET %s: status %d, want %d\n%s", tt.path, resp.StatusCode, tt.code, body)
+			continue
+		}
+		if tt.code == 200 && string(body) != tt.body {
+			t.Errorf("GET %s: body %q, want %q", tt.path, body, tt.body)
+		}
+	}
+}
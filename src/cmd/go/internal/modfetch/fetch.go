@@ -1035,6 +1035,9 @@ patterns for the GOVCS variable; see 'go help vcs'. For that usage,
 GOPRIVATE applies even in GOPATH mode. In that case, it matches import paths
 instead of module paths.
 
+Private module proxies and servers that require credentials can be
+configured with the GOAUTH variable; see 'go help goauth'.
+
 The 'go env -w' command (see 'go help env') can be used to set these variables
 for future go command invocations.
 
@@ -53,7 +53,7 @@ import (
 var CmdGet = &base.Command{
 	// Note: flags below are listed explicitly because they're the most common.
 	// Do not send CLs removing them because they're covered by [get flags].
-	UsageLine: "go get [-t] [-u] [-v] [build flags] [packages]",
+	UsageLine: "go get [-t] [-u] [-v] [-tool] [build flags] [packages]",
 	Short:     "add dependencies to current module and install them",
 	Long: `
 Get resolves its command-line arguments to packages at specific module versions,
@@ -80,6 +80,14 @@ To upgrade the Go toolchain to the latest patch release of the current Go toolch
 
 	go get toolchain@patch
 
+To track a package as a tool of the main module, runnable with 'go tool':
+
+	go get -tool example.com/cmd/tool
+
+To upgrade all the tools of the main module:
+
+	go get tool
+
 See https://golang.org/ref/mod#go-get for details.
 
 In earlier versions of Go, 'go get' was used to build and install packages.
@@ -108,6 +116,11 @@ but changes the default to select patch releases.
 When the -t and -u flags are used together, get will update
 test dependencies as well.
 
+The -tool flag instructs get to add a tool directive to go.mod for
+each package named on the command line, in addition to requiring
+the modules that provide the packages and their dependencies.
+When used with @none, get removes the tool directives instead.
+
 The -x flag prints commands as they are executed. This is useful for
 debugging version control commands when a module is downloaded directly
 from a repository.
@@ -213,6 +226,7 @@ var (
 	getFix      = CmdGet.Flag.Bool("fix", false, "")
 	getM        = CmdGet.Flag.Bool("m", false, "")
 	getT        = CmdGet.Flag.Bool("t", false, "")
+	getTool     = CmdGet.Flag.Bool("tool", false, "")
 	getU        upgradeFlag
 	getInsecure = CmdGet.Flag.Bool("insecure", false, "")
 	// -v is cfg.BuildV
@@ -374,6 +388,10 @@ func runGet(ctx context.Context, cmd *base.Command, args []string) {
 	}
 	r.checkPackageProblems(ctx, pkgPatterns)
 
+	if *getTool {
+		updateTools(ctx, queries, &opts)
+	}
+
 	// Everything succeeded. Update go.mod.
 	oldReqs := reqsFromGoMod(modload.ModFile())
 
@@ -419,9 +437,31 @@ func parseArgs(ctx context.Context, rawArgs []string) (dropToolchain bool, queri
 			case "toolchain":
 				dropToolchain = true
 				continue
+			case "tool":
+				base.Errorf("go: cannot use tool@none")
+				continue
 			}
 		}
 
+		if q.pattern == "tool" {
+			// "tool" matches the tools declared in go.mod:
+			// query each of them, at the requested version.
+			modload.LoadModFile(ctx)
+			for path := range modload.MainModules.Tools() {
+				arg := path
+				if q.rawVersion != "" {
+					arg += "@" + q.rawVersion
+				}
+				tq, err := newQuery(arg)
+				if err != nil {
+					base.Error(err)
+					continue
+				}
+				queries = append(queries, tq)
+			}
+			continue
+		}
+
 		// If there were no arguments, CleanPatterns returns ".". Set the raw
 		// string back to "" for better errors.
 		if len(rawArgs) == 0 {
@@ -1682,6 +1722,40 @@ func (r *resolver) checkPackageProblems(ctx context.Context, pkgPatterns []strin
 // explicitly required modules in go.mod. Most changes to indirect requirements
 // are not relevant to the user and are not logged.
 //
+// updateTools records in opts the tool directives to add to or drop from
+// go.mod for the packages matched by queries, as requested by 'go get -tool'.
+func updateTools(ctx context.Context, queries []*query, opts *modload.WriteOpts) {
+	pkgOpts := modload.PackageOpts{
+		VendorModulesInGOROOTSrc: true,
+		LoadTests:                *getT,
+		AllowErrors:              true,
+		SilenceNoGoErrors:        true,
+	}
+	for _, q := range queries {
+		if search.IsMetaPackage(q.pattern) || q.pattern == "toolchain" {
+			base.Fatalf("go: go get -tool does not work with \"%s\".", q.raw)
+		}
+
+		if q.version == "none" {
+			if q.isWildcard() {
+				for path := range modload.MainModules.Tools() {
+					if q.matchesPath(path) {
+						opts.DropTools = append(opts.DropTools, path)
+					}
+				}
+			} else {
+				opts.DropTools = append(opts.DropTools, q.pattern)
+			}
+			continue
+		}
+
+		matches, _ := modload.LoadPackages(ctx, pkgOpts, q.pattern)
+		for _, m := range matches {
+			opts.AddTools = append(opts.AddTools, m.Pkgs...)
+		}
+	}
+}
+
 // reportChanges should be called after WriteGoMod.
 func (r *resolver) reportChanges(oldReqs, newReqs []module.Version) {
 	type change struct {
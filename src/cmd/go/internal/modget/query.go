@@ -197,7 +197,7 @@ func (q *query) validate() error {
 		}
 	}
 
-	if search.IsMetaPackage(q.pattern) && q.pattern != "all" {
+	if search.IsMetaPackage(q.pattern) && q.pattern != "all" && q.pattern != "tool" {
 		if q.pattern != q.raw {
 			return fmt.Errorf("can't request explicit version of standard-library pattern %q", q.pattern)
 		}
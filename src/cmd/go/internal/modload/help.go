@@ -57,6 +57,13 @@ To add, upgrade, downgrade, or remove a specific module requirement, use
 'go get'. For details, see 'go help module-get' or
 https://golang.org/ref/mod#go-get.
 
+To track a package as a tool of the module, runnable with 'go tool',
+use 'go get -tool', which adds a tool directive to go.mod:
+
+	tool example.com/cmd/tool
+
+See 'go help tool'.
+
 To make other changes or to parse go.mod as JSON for use by other tools,
 use 'go mod edit'. See 'go help mod edit' or
 https://golang.org/ref/mod#go-mod-edit.
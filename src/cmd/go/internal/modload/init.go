@@ -107,6 +107,8 @@ type MainModuleSet struct {
 
 	modFiles map[module.Version]*modfile.File
 
+	tools map[string]bool
+
 	modContainingCWD module.Version
 
 	workFile *modfile.WorkFile
@@ -200,6 +202,16 @@ func (mms *MainModuleSet) ModFile(m module.Version) *modfile.File {
 	return mms.modFiles[m]
 }
 
+// Tools returns the package paths of the tools declared by
+// tool directives in the go.mod files of the main modules.
+// Callers should not modify the returned map.
+func (mms *MainModuleSet) Tools() map[string]bool {
+	if mms == nil {
+		return nil
+	}
+	return mms.tools
+}
+
 func (mms *MainModuleSet) WorkFile() *modfile.WorkFile {
 	return mms.workFile
 }
@@ -1171,6 +1183,7 @@ func makeMainModules(ms []module.Version, rootDirs []string, modFiles []*modfile
 		pathPrefix:      map[module.Version]string{},
 		modRoot:         map[module.Version]string{},
 		modFiles:        map[module.Version]*modfile.File{},
+		tools:           map[string]bool{},
 		indices:         map[module.Version]*modFileIndex{},
 		highestReplaced: map[string]string{},
 		workFile:        workFile,
@@ -1226,6 +1239,10 @@ func makeMainModules(ms []module.Version, rootDirs []string, modFiles []*modfile
 		}
 
 		if modFiles[i] != nil {
+			for _, t := range modFiles[i].Tool {
+				mainModules.tools[t.Path] = true
+			}
+
 			curModuleReplaces := make(map[module.Version]bool)
 			for _, r := range modFiles[i].Replace {
 				if replacedByWorkFile[r.Old.Path] {
@@ -1684,6 +1701,9 @@ type WriteOpts struct {
 	DropToolchain     bool // go get toolchain@none
 	ExplicitToolchain bool // go get has set explicit toolchain version
 
+	AddTools  []string // go get -tool example.com/m1
+	DropTools []string // go get -tool example.com/m1@none
+
 	// TODO(bcmills): Make 'go mod tidy' update the go version in the Requirements
 	// instead of writing directly to the modfile.File
 	TidyWroteGo bool // Go.Version field already updated by 'go mod tidy'
@@ -1790,6 +1810,14 @@ func commitRequirements(ctx context.Context, opts WriteOpts) (err error) {
 		modFile.AddToolchainStmt(toolchain)
 	}
 
+	// Update tool directives.
+	for _, path := range opts.DropTools {
+		modFile.DropTool(path)
+	}
+	for _, path := range opts.AddTools {
+		modFile.AddTool(path)
+	}
+
 	// Update require blocks.
 	if gover.Compare(goVersion, gover.SeparateIndirectVersion) < 0 {
 		modFile.SetRequire(list)
@@ -35,6 +35,7 @@ package modload
 // package is known to match the "all" meta-pattern.
 // A package matches the "all" pattern if:
 // 	- it is in the main module, or
+// 	- it is a tool declared in the go.mod file of the main module, or
 // 	- it is imported by any test in the main module, or
 // 	- it is imported by another package in "all", or
 // 	- the main module specifies a go version ≤ 1.15, and the package is imported
@@ -317,7 +318,7 @@ func LoadPackages(ctx context.Context, opts PackageOpts, patterns ...string) (ma
 
 			case m.Pattern() == "all":
 				if ld == nil {
-					// The initial roots are the packages in the main module.
+					// The initial roots are the packages and tools in the main module.
 					// loadFromRoots will expand that to "all".
 					m.Errs = m.Errs[:0]
 					matchModules := MainModules.Versions()
@@ -325,12 +326,16 @@ func LoadPackages(ctx context.Context, opts PackageOpts, patterns ...string) (ma
 						matchModules = []module.Version{opts.MainModule}
 					}
 					matchPackages(ctx, m, opts.Tags, omitStd, matchModules)
+					m.Pkgs = append(m.Pkgs, mainModuleTools()...)
 				} else {
 					// Starting with the packages in the main module,
 					// enumerate the full list of "all".
 					m.Pkgs = ld.computePatternAll()
 				}
 
+			case m.Pattern() == "tool":
+				m.Pkgs = mainModuleTools()
+
 			case m.Pattern() == "std" || m.Pattern() == "cmd":
 				if m.Pkgs == nil {
 					m.MatchPackages() // Locate the packages within GOROOT/src.
@@ -1815,7 +1820,7 @@ func (ld *loader) load(ctx context.Context, pkg *loadPkg) {
 	if pkg.dir == "" {
 		return
 	}
-	if MainModules.Contains(pkg.mod.Path) {
+	if MainModules.Contains(pkg.mod.Path) || MainModules.Tools()[pkg.path] {
 		// Go ahead and mark pkg as in "all". This provides the invariant that a
 		// package that is *only* imported by other packages in "all" is always
 		// marked as such before loading its imports.
@@ -1962,6 +1967,17 @@ func (ld *loader) computePatternAll() (all []string) {
 	return all
 }
 
+// mainModuleTools returns the sorted package paths of the tools
+// declared in the go.mod files of the main modules.
+func mainModuleTools() []string {
+	tools := make([]string, 0, len(MainModules.Tools()))
+	for path := range MainModules.Tools() {
+		tools = append(tools, path)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
 // checkMultiplePaths verifies that a given module path is used as itself
 // or as a replacement for another module, but not both at the same time.
 //
@@ -74,6 +74,7 @@ type modFileIndex struct {
 	require      map[module.Version]requireMeta
 	replace      map[module.Version]module.Version
 	exclude      map[module.Version]bool
+	tool         map[string]bool
 }
 
 type requireMeta struct {
@@ -436,6 +437,11 @@ func indexModFile(data []byte, modFile *modfile.File, mod module.Version, needsF
 		i.exclude[x.Mod] = true
 	}
 
+	i.tool = make(map[string]bool, len(modFile.Tool))
+	for _, t := range modFile.Tool {
+		i.tool[t.Path] = true
+	}
+
 	return i
 }
 
@@ -472,7 +478,8 @@ func (i *modFileIndex) modFileIsDirty(modFile *modfile.File) bool {
 		toolchain != i.toolchain ||
 		len(modFile.Require) != len(i.require) ||
 		len(modFile.Replace) != len(i.replace) ||
-		len(modFile.Exclude) != len(i.exclude) {
+		len(modFile.Exclude) != len(i.exclude) ||
+		len(modFile.Tool) != len(i.tool) {
 		return true
 	}
 
@@ -503,6 +510,12 @@ func (i *modFileIndex) modFileIsDirty(modFile *modfile.File) bool {
 		}
 	}
 
+	for _, t := range modFile.Tool {
+		if !i.tool[t.Path] {
+			return true
+		}
+	}
+
 	return false
 }
 
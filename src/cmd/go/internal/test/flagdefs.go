@@ -15,6 +15,7 @@ var passFlagToTest = map[string]bool{
 	"blockprofile":         true,
 	"blockprofilerate":     true,
 	"count":                true,
+	"coverpertest":         true,
 	"coverprofile":         true,
 	"cpu":                  true,
 	"cpuprofile":           true,
@@ -31,6 +32,8 @@ var passFlagToTest = map[string]bool{
 	"outputdir":            true,
 	"parallel":             true,
 	"run":                  true,
+	"shard":                true,
+	"shardtimes":           true,
 	"short":                true,
 	"shuffle":              true,
 	"skip":                 true,
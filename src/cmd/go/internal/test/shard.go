@@ -0,0 +1,133 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"bufio"
+	"encoding/json"
+	"hash/fnv"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/load"
+)
+
+// minShardWeight is the duration assumed for a package whose
+// recorded duration is shorter.
+const minShardWeight = time.Millisecond
+
+// shardPackages returns the packages in pkgs that are assigned to the
+// shard selected by -shard, for use with -shardpkgs.
+//
+// Without -shardtimes, each package is assigned by a hash of its
+// import path. Otherwise the packages are assigned greedily, longest
+// first, to the shard with the least total duration so far.
+// This is the same assignment the testing package uses for the
+// tests in a single package.
+func shardPackages(pkgs []*load.Package) []*load.Package {
+	var durations map[string]time.Duration
+	if testShardTimes != "" {
+		var err error
+		durations, err = readPackageDurations(testShardTimes.String())
+		if err != nil {
+			base.Fatalf("go: reading -shardtimes: %v", err)
+		}
+	}
+
+	var total time.Duration
+	var known int
+	for _, p := range pkgs {
+		if d, ok := durations[p.ImportPath]; ok {
+			total += max(d, minShardWeight)
+			known++
+		}
+	}
+
+	shards := make(map[*load.Package]int, len(pkgs))
+	if known == 0 {
+		for _, p := range pkgs {
+			h := fnv.New32a()
+			h.Write([]byte(p.ImportPath))
+			shards[p] = int(h.Sum32() % uint32(testShard.count))
+		}
+	} else {
+		average := total / time.Duration(known)
+		weight := func(p *load.Package) time.Duration {
+			if d, ok := durations[p.ImportPath]; ok {
+				return max(d, minShardWeight)
+			}
+			return average
+		}
+		sorted := slices.Clone(pkgs)
+		slices.SortFunc(sorted, func(a, b *load.Package) int {
+			if wa, wb := weight(a), weight(b); wa != wb {
+				if wa > wb {
+					return -1
+				}
+				return +1
+			}
+			return strings.Compare(a.ImportPath, b.ImportPath)
+		})
+		totals := make([]time.Duration, testShard.count)
+		for _, p := range sorted {
+			i := 0
+			for j := range totals {
+				if totals[j] < totals[i] {
+					i = j
+				}
+			}
+			shards[p] = i
+			totals[i] += weight(p)
+		}
+	}
+
+	var selected []*load.Package
+	for _, p := range pkgs {
+		if shards[p] == testShard.index {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// readPackageDurations returns the elapsed time of each package
+// recorded in file, the output of a previous 'go test -json' run.
+func readPackageDurations(file string) (map[string]time.Duration, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	durations := make(map[string]time.Duration)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var ev struct {
+			Action  string
+			Package string
+			Test    string
+			Elapsed *float64
+		}
+		if json.Unmarshal(scanner.Bytes(), &ev) != nil {
+			// Not a test event; go test -json may interleave other output.
+			continue
+		}
+		if ev.Package == "" || ev.Test != "" || ev.Elapsed == nil {
+			continue
+		}
+		switch ev.Action {
+		case "pass", "fail", "skip":
+			durations[ev.Package] = time.Duration(*ev.Elapsed * float64(time.Second))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return durations, nil
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"internal/coverage"
+	"internal/godebug"
 	"internal/platform"
 	"io"
 	"io/fs"
@@ -127,7 +128,9 @@ elapsed time in the summary line.
 The rule for a match in the cache is that the run involves the same
 test binary and the flags on the command line come entirely from a
 restricted set of 'cacheable' test flags, defined as -benchtime, -cpu,
--list, -parallel, -run, -short, -timeout, -failfast, -fullpath and -v.
+-list, -parallel, -run, -short, -timeout, -failfast, -fullpath, -shard,
+-shardtimes and -v. With -shardtimes, the content of the named file is
+part of the cache key as well.
 If a run of go test has any test or non-test flags outside this set,
 the result is not cached. To disable test caching, use any test flag
 or argument other than the cacheable flags. The idiomatic way to disable
@@ -324,6 +327,32 @@ control the execution of any test:
 	    integer N, then N will be used as the seed value. In both cases,
 	    the seed will be reported for reproducibility.
 
+	-shard i/n
+	    Run only shard i of n (0 <= i < n) of the tests, examples, and
+	    fuzz tests in each package. Every top-level test, as listed by
+	    -list, is assigned to exactly one shard, so running each of the
+	    n shards, for example on different machines, runs every test once.
+	    The assignment is deterministic and does not depend on the order
+	    of the tests, so -shuffle randomizes the order of the tests within
+	    a shard without changing which tests it contains. Without
+	    -shardtimes, each test is assigned by a hash of its name.
+	    Benchmarks are not sharded.
+
+	-shardpkgs
+	    With -shard, partition the packages instead of the tests:
+	    each package is tested entirely within a single shard, and
+	    the other shards do not build or run its tests.
+	    Without -shardtimes, each package is assigned by a hash of
+	    its import path.
+
+	-shardtimes file
+	    Balance the shards selected by -shard using the durations
+	    recorded in file, which holds the output of a previous
+	    'go test -json' run. Tests (or, with -shardpkgs, packages)
+	    are assigned longest first to the shard with the least total
+	    duration so far. Tests not recorded in the file are assumed to
+	    take the average recorded duration.
+
 	-skip regexp
 	    Run only those tests, examples, fuzz tests, and benchmarks that
 	    do not match the regular expression. Like for -run and -bench,
@@ -369,6 +398,17 @@ profile the tests during execution:
 	    if -test.blockprofile is set without this flag, all blocking events
 	    are recorded, equivalent to -test.blockprofilerate=1.
 
+	-coverpertest dir
+	    Write the coverage counters incremented by each top-level test
+	    to a separate counter data file in dir, along with the coverage
+	    meta-data file, for use with 'go tool covdata'. Each counter data
+	    file records the names of the test and its package. Counters
+	    incremented while tests run in parallel may be attributed to
+	    any of those tests.
+	    Sets -cover and, unless -covermode is set, sets -covermode to
+	    count (atomic when -race is enabled); -covermode=set is not
+	    allowed.
+
 	-coverprofile cover.out
 	    Write a coverage profile to the file after all tests have passed.
 	    Sets -cover.
@@ -542,6 +582,7 @@ var (
 	testC            bool                              // -c flag
 	testCoverPkgs    []*load.Package                   // -coverpkg flag
 	testCoverProfile string                            // -coverprofile flag
+	testCoverPerTest absFileFlag                       // -coverpertest flag
 	testFailFast     bool                              // -failfast flag
 	testFuzz         string                            // -fuzz flag
 	testJSON         bool                              // -json flag
@@ -549,6 +590,9 @@ var (
 	testO            string                            // -o flag
 	testOutputDir    outputdirFlag                     // -outputdir flag
 	testShuffle      shuffleFlag                       // -shuffle flag
+	testShard        shardFlag                         // -shard flag
+	testShardPkgs    bool                              // -shardpkgs flag
+	testShardTimes   absFileFlag                       // -shardtimes flag
 	testTimeout      time.Duration                     // -timeout flag
 	testV            testVFlag                         // -v flag
 	testVet          = vetFlag{flags: defaultVetFlags} // -vet flag
@@ -597,6 +641,8 @@ var (
 	testCacheExpire    time.Time                    // ignore cached test results before this time
 	testShouldFailFast atomic.Bool                  // signals pending tests to fail fast
 
+	gotestjsonbuildtext = godebug.New("gotestjsonbuildtext") // print build output as text with -json
+
 	testBlockProfile, testCPUProfile, testMemProfile, testMutexProfile, testTrace string // profiling flag that limits test to one package
 
 	testODir = false
@@ -678,6 +724,12 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 	pkgArgs, testArgs = testFlags(args)
 	modload.InitWorkfile() // The test command does custom flag processing; initialize workspaces after that.
 
+	if testJSON && gotestjsonbuildtext.Value() != "1" {
+		// Report build output and failures as JSON build events,
+		// interleaved with the test events.
+		cfg.BuildJSON = true
+	}
+
 	if cfg.DebugTrace != "" {
 		var close func() error
 		var err error
@@ -697,7 +749,26 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 
 	work.FindExecCmd() // initialize cached result
 
+	if testCoverPerTest != "" {
+		// Per-test coverage records counter increments,
+		// which set mode cannot represent.
+		cfg.BuildCover = true
+		if cfg.BuildCoverMode == "" && !cfg.BuildRace {
+			cfg.BuildCoverMode = "count"
+		}
+	}
 	work.BuildInit()
+	if testCoverPerTest != "" {
+		if !cfg.Experiment.CoverageRedesign {
+			base.Fatalf("cannot use -coverpertest flag with GOEXPERIMENT=nocoverageredesign")
+		}
+		if cfg.BuildCoverMode == "set" {
+			base.Fatalf("cannot use -coverpertest flag with -covermode=set")
+		}
+		if err := os.MkdirAll(testCoverPerTest.String(), 0777); err != nil {
+			base.Fatal(err)
+		}
+	}
 	work.VetFlags = testVet.flags
 	work.VetExplicit = testVet.explicit
 
@@ -707,6 +778,9 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 	if len(pkgs) == 0 {
 		base.Fatalf("no packages to test")
 	}
+	if testShardPkgs {
+		pkgs = shardPackages(pkgs)
+	}
 
 	if testFuzz != "" {
 		if !platform.FuzzSupported(cfg.Goos, cfg.Goarch) {
@@ -718,6 +792,12 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 		if testCoverProfile != "" {
 			base.Fatalf("cannot use -coverprofile flag with -fuzz flag")
 		}
+		if testCoverPerTest != "" {
+			base.Fatalf("cannot use -coverpertest flag with -fuzz flag")
+		}
+		if testShard.count > 0 {
+			base.Fatalf("cannot use -shard flag with -fuzz flag")
+		}
 		if profileFlag := testProfile(); profileFlag != "" {
 			base.Fatalf("cannot use %s flag with -fuzz flag", profileFlag)
 		}
@@ -994,9 +1074,9 @@ func runTest(ctx context.Context, cmd *base.Command, args []string) {
 			str := err.Error()
 			str = strings.TrimPrefix(str, "\n")
 			if p.ImportPath != "" {
-				base.Errorf("# %s\n%s", p.ImportPath, str)
+				load.DefaultPrinter().Errorf(p, "# %s\n%s", p.ImportPath, str)
 			} else {
-				base.Errorf("%s", str)
+				load.DefaultPrinter().Errorf(p, "%s", str)
 			}
 			fmt.Printf("FAIL\t%s [setup failed]\n", p.ImportPath)
 			continue
@@ -1329,6 +1409,33 @@ type runTestActor struct {
 	next chan<- struct{} // close next once the next test can start.
 }
 
+// failedBuild returns the package whose failure to build caused
+// the failure of action a, or nil if there is no such package.
+// It is the package of the first failed action found among
+// the dependencies of a whose own dependencies all succeeded.
+func failedBuild(a *work.Action) *load.Package {
+	seen := make(map[*work.Action]bool)
+	var find func(a *work.Action) *load.Package
+	find = func(a *work.Action) *load.Package {
+		if seen[a] {
+			return nil
+		}
+		seen[a] = true
+		for _, a1 := range a.Deps {
+			if a1.Failed {
+				if p := find(a1); p != nil {
+					return p
+				}
+				if a1.Package != nil {
+					return a1.Package
+				}
+			}
+		}
+		return nil
+	}
+	return find(a)
+}
+
 // runCache is the cache for running a single test.
 type runCache struct {
 	disableCache bool // cache should be disabled for this run
@@ -1374,8 +1481,9 @@ func (r *runTestActor) Act(b *work.Builder, ctx context.Context, a *work.Action)
 
 	var stdout io.Writer = os.Stdout
 	var err error
+	var json *test2json.Converter
 	if testJSON {
-		json := test2json.NewConverter(lockedStdout{}, a.Package.ImportPath, test2json.Timestamp)
+		json = test2json.NewConverter(lockedStdout{}, a.Package.ImportPath, test2json.Timestamp)
 		defer func() {
 			json.Exited(err)
 			json.Close()
@@ -1388,6 +1496,11 @@ func (r *runTestActor) Act(b *work.Builder, ctx context.Context, a *work.Action)
 
 	if a.Failed {
 		// We were unable to build the binary.
+		if json != nil {
+			if p := failedBuild(a); p != nil {
+				json.SetFailedBuild(p.Desc())
+			}
+		}
 		a.Failed = false
 		fmt.Fprintf(stdout, "FAIL\t%s [build failed]\n", a.Package.ImportPath)
 		// Tell the JSON converter that this was a failure, not a passing run.
@@ -1731,12 +1844,26 @@ func (c *runCache) tryCacheWithID(b *work.Builder, a *work.Action, id string) bo
 			"-test.timeout",
 			"-test.failfast",
 			"-test.v",
-			"-test.fullpath":
+			"-test.fullpath",
+			"-test.shard":
 			// These are cacheable.
 			// Note that this list is documented above,
 			// so if you add to this list, update the docs too.
 			cacheArgs = append(cacheArgs, arg)
 
+		case "-test.shardtimes":
+			// The shard assignment depends on the content of the
+			// timing file, so include that in the cache key too.
+			fh, err := hashOpen(arg[i+1:])
+			if err != nil {
+				if cache.DebugTest {
+					fmt.Fprintf(os.Stderr, "testcache: caching disabled for test argument: %s: %v\n", arg, err)
+				}
+				c.disableCache = true
+				return false
+			}
+			cacheArgs = append(cacheArgs, fmt.Sprintf("%s %x", arg, fh))
+
 		default:
 			// nothing else is cacheable
 			if cache.DebugTest {
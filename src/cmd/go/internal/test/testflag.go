@@ -46,6 +46,7 @@ func init() {
 	cf.StringVar(&testBlockProfile, "blockprofile", "", "")
 	cf.String("blockprofilerate", "", "")
 	cf.Int("count", 0, "")
+	cf.Var(&testCoverPerTest, "coverpertest", "")
 	cf.String("cpu", "", "")
 	cf.StringVar(&testCPUProfile, "cpuprofile", "", "")
 	cf.BoolVar(&testFailFast, "failfast", false, "")
@@ -67,6 +68,9 @@ func init() {
 	cf.StringVar(&testTrace, "trace", "", "")
 	cf.Var(&testV, "v", "")
 	cf.Var(&testShuffle, "shuffle", "")
+	cf.Var(&testShard, "shard", "")
+	cf.BoolVar(&testShardPkgs, "shardpkgs", false, "")
+	cf.Var(&testShardTimes, "shardtimes", "")
 
 	for name, ok := range passFlagToTest {
 		if ok {
@@ -176,6 +180,56 @@ func (f *vetFlag) Set(value string) error {
 	return nil
 }
 
+// absFileFlag implements a flag naming a file, which it records
+// as an absolute path: the test binary runs in the package directory,
+// not the working directory of the 'go' command.
+type absFileFlag string
+
+func (f *absFileFlag) String() string {
+	return string(*f)
+}
+
+func (f *absFileFlag) Set(value string) error {
+	if value == "" {
+		*f = ""
+		return nil
+	}
+	abs, err := filepath.Abs(value)
+	if err != nil {
+		return err
+	}
+	*f = absFileFlag(abs)
+	return nil
+}
+
+// shardFlag implements the -shard flag, of the form i/n.
+// The zero value, with count 0, means sharding is off.
+type shardFlag struct {
+	index, count int
+}
+
+func (f *shardFlag) String() string {
+	if f.count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", f.index, f.count)
+}
+
+func (f *shardFlag) Set(value string) error {
+	if value == "" {
+		*f = shardFlag{}
+		return nil
+	}
+	is, ns, ok := strings.Cut(value, "/")
+	index, err1 := strconv.Atoi(is)
+	count, err2 := strconv.Atoi(ns)
+	if !ok || err1 != nil || err2 != nil || count < 1 || index < 0 || index >= count {
+		return fmt.Errorf("-shard argument must be of the form i/n with 0 <= i < n")
+	}
+	*f = shardFlag{index: index, count: count}
+	return nil
+}
+
 type shuffleFlag struct {
 	on   bool
 	seed *int64
@@ -328,7 +382,12 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 		}
 
 		if short := strings.TrimPrefix(f.Name, "test."); passFlagToTest[short] {
-			explicitArgs = append(explicitArgs, fmt.Sprintf("-test.%s=%v", short, f.Value))
+			switch short {
+			case "shard", "shardtimes":
+				// Forwarded below, once we know whether -shardpkgs is set.
+			default:
+				explicitArgs = append(explicitArgs, fmt.Sprintf("-test.%s=%v", short, f.Value))
+			}
 
 			// This flag has been overridden explicitly, so don't forward its implicit
 			// value from GOFLAGS.
@@ -338,6 +397,10 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 
 		args = remainingArgs
 	}
+	if testShard.count == 0 && (testShardPkgs || testShardTimes != "") {
+		fmt.Fprintf(os.Stderr, "go: -shardpkgs and -shardtimes require -shard\n")
+		exitWithUsage()
+	}
 	if firstUnknownFlag != "" && testC {
 		fmt.Fprintf(os.Stderr, "go: unknown flag %s cannot be used with -c\n", firstUnknownFlag)
 		exitWithUsage()
@@ -354,6 +417,19 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 		delete(addFromGOFLAGS, "test.v")
 	}
 
+	// The sharding flags are forwarded to the test binary
+	// unless the go command is partitioning the packages itself.
+	delete(addFromGOFLAGS, "shard")
+	delete(addFromGOFLAGS, "test.shard")
+	delete(addFromGOFLAGS, "shardtimes")
+	delete(addFromGOFLAGS, "test.shardtimes")
+	if testShard.count > 0 && !testShardPkgs {
+		injectedFlags = append(injectedFlags, "-test.shard="+testShard.String())
+		if testShardTimes != "" {
+			injectedFlags = append(injectedFlags, "-test.shardtimes="+testShardTimes.String())
+		}
+	}
+
 	// Inject flags from GOFLAGS before the explicit command-line arguments.
 	// (They must appear before the flag terminator or first non-flag argument.)
 	// Also determine whether flags with awkward defaults have already been set.
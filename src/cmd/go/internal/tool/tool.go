@@ -8,6 +8,7 @@ package tool
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"go/build"
@@ -20,6 +21,10 @@ import (
 
 	"cmd/go/internal/base"
 	"cmd/go/internal/cfg"
+	"cmd/go/internal/load"
+	"cmd/go/internal/modload"
+	"cmd/go/internal/str"
+	"cmd/go/internal/work"
 )
 
 var CmdTool = &base.Command{
@@ -28,8 +33,18 @@ var CmdTool = &base.Command{
 	Short:     "run specified go tool",
 	Long: `
 Tool runs the go tool command identified by the arguments.
+
+Go ships with a number of builtin tools, and additional tools
+may be defined in the go.mod of the current module.
+
 With no arguments it prints the list of known tools.
 
+A tool defined in go.mod by a tool directive may be named either
+by its full package path or, if that is unambiguous, by the last
+element of the path (ignoring a major version suffix such as /v2).
+The tool is built, cached in the build cache, and then run.
+See 'go help gomod' for more about the tool directive.
+
 The -n flag causes tool to print the command that would be
 executed but not execute it.
 
@@ -57,19 +72,30 @@ func init() {
 
 func runTool(ctx context.Context, cmd *base.Command, args []string) {
 	if len(args) == 0 {
-		listTools()
+		listTools(ctx)
 		return
 	}
 	toolName := args[0]
-	// The tool name must be lower-case letters, numbers or underscores.
+	// The name of a builtin tool must be lower-case letters,
+	// numbers or underscores. Anything else can only name
+	// a tool defined in go.mod.
+	builtin := true
 	for _, c := range toolName {
 		switch {
 		case 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '_':
 		default:
-			fmt.Fprintf(os.Stderr, "go: bad tool name %q\n", toolName)
-			base.SetExitStatus(2)
+			builtin = false
+		}
+	}
+
+	if !builtin {
+		if tool := loadModTool(ctx, toolName); tool != "" {
+			buildAndRunModtool(ctx, tool, args[1:])
 			return
 		}
+		fmt.Fprintf(os.Stderr, "go: bad tool name %q\n", toolName)
+		base.SetExitStatus(2)
+		return
 	}
 
 	toolPath, err := base.ToolPath(toolName)
@@ -86,6 +112,11 @@ func runTool(ctx context.Context, cmd *base.Command, args []string) {
 			}
 		}
 
+		if tool := loadModTool(ctx, toolName); tool != "" {
+			buildAndRunModtool(ctx, tool, args[1:])
+			return
+		}
+
 		// Emit the usual error for the missing tool.
 		_ = base.Tool(toolName)
 	}
@@ -133,8 +164,9 @@ func runTool(ctx context.Context, cmd *base.Command, args []string) {
 	}
 }
 
-// listTools prints a list of the available tools in the tools directory.
-func listTools() {
+// listTools prints a list of the available tools in the tools directory,
+// followed by the tools defined in the go.mod of the main module, if any.
+func listTools(ctx context.Context) {
 	f, err := os.Open(build.ToolDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "go: no tool directory: %s\n", err)
@@ -162,6 +194,20 @@ func listTools() {
 		}
 		fmt.Println(name)
 	}
+
+	modload.InitWorkfile()
+	if !modload.Enabled() || !modload.HasModRoot() {
+		return
+	}
+	modload.LoadModFile(ctx)
+	tools := make([]string, 0, len(modload.MainModules.Tools()))
+	for tool := range modload.MainModules.Tools() {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		fmt.Println(tool)
+	}
 }
 
 func impersonateDistList(args []string) (handled bool) {
@@ -222,3 +268,100 @@ func impersonateDistList(args []string) (handled bool) {
 	os.Stdout.Write(out)
 	return true
 }
+
+// loadModTool returns the package path of the tool defined in the go.mod
+// of the main module that is named by name, or "" if there is none.
+// It exits with an error if name matches more than one tool.
+func loadModTool(ctx context.Context, name string) string {
+	modload.InitWorkfile()
+	if !modload.Enabled() || !modload.HasModRoot() {
+		return ""
+	}
+	modload.LoadModFile(ctx)
+
+	var matches []string
+	for tool := range modload.MainModules.Tools() {
+		if tool == name || defaultExecName(tool) == name {
+			matches = append(matches, tool)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return ""
+	case 1:
+		return matches[0]
+	}
+	sort.Strings(matches)
+	base.Fatal(fmt.Errorf("go: tool %q is ambiguous; choose one of:\n\t%s", name, strings.Join(matches, "\n\t")))
+	return ""
+}
+
+// defaultExecName returns the name of the executable that 'go install'
+// would produce for the package with the given import path.
+func defaultExecName(importPath string) string {
+	var p load.Package
+	p.ImportPath = importPath
+	return p.DefaultExecName()
+}
+
+// buildAndRunModtool builds the tool with the given package path,
+// caching the executable in the build cache, and runs it with args.
+func buildAndRunModtool(ctx context.Context, tool string, args []string) {
+	work.BuildInit()
+	b := work.NewBuilder("")
+	defer func() {
+		if err := b.Close(); err != nil {
+			base.Fatal(err)
+		}
+	}()
+
+	pkgOpts := load.PackageOpts{MainOnly: true}
+	p := load.PackagesAndErrors(ctx, pkgOpts, []string{tool})[0]
+	load.CheckPackageErrors([]*load.Package{p})
+
+	p.Internal.OmitDebug = true
+	p.Internal.ExeName = p.DefaultExecName()
+
+	a1 := b.LinkAction(work.ModeBuild, work.ModeBuild, p)
+	a1.CacheExecutable = true
+	a := &work.Action{Mode: "go tool", Actor: work.ActorFunc(runBuiltTool), Args: args, Deps: []*work.Action{a1}}
+	b.Do(ctx, a)
+}
+
+func runBuiltTool(b *work.Builder, ctx context.Context, a *work.Action) error {
+	cmdline := str.StringList(work.FindExecCmd(), a.Deps[0].BuiltTarget(), a.Args)
+
+	if toolN {
+		fmt.Println(strings.Join(cmdline, " "))
+		return nil
+	}
+
+	toolCmd := exec.Command(cmdline[0], cmdline[1:]...)
+	toolCmd.Stdin = os.Stdin
+	toolCmd.Stdout = os.Stdout
+	toolCmd.Stderr = os.Stderr
+	err := toolCmd.Start()
+	if err == nil {
+		c := make(chan os.Signal, 100)
+		signal.Notify(c)
+		go func() {
+			for sig := range c {
+				toolCmd.Process.Signal(sig)
+			}
+		}()
+		err = toolCmd.Wait()
+		signal.Stop(c)
+		close(c)
+	}
+	if err != nil {
+		// As for builtin tools, only print about the exit status if
+		// the command didn't even run or didn't exit cleanly.
+		var e *exec.ExitError
+		if !errors.As(err, &e) || !e.Exited() || cfg.BuildX {
+			fmt.Fprintf(os.Stderr, "go tool %s: %s\n", a.Deps[0].Package.ImportPath, err)
+		}
+		base.SetExitStatus(1)
+	}
+	return nil
+}
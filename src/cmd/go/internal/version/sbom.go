@@ -0,0 +1,374 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package version
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// This file converts the build information embedded in a binary
+// into a software bill of materials (SBOM) in one of two formats:
+//
+//	SPDX 2.3:        https://spdx.github.io/spdx-spec/v2.3/
+//	CycloneDX 1.5:   https://cyclonedx.org/docs/1.5/json/
+//
+// Both describe the binary's main module as the root component,
+// each module dependency as a component it depends on,
+// and the Go toolchain that built the binary as a build tool.
+// The build settings are recorded as annotations (SPDX) or
+// properties (CycloneDX) of the root component.
+//
+// A module's checksum in build info is a go.sum "h1:" hash,
+// the SHA-256 hash of a summary of the module's files
+// (see golang.org/x/mod/sumdb/dirhash). It is not the hash of any
+// file or archive, so it is not reported in the checksum fields of
+// either format, which consumers use to verify downloads; instead it
+// is recorded as an annotation (SPDX) or a "go:sum" property
+// (CycloneDX) of the component.
+
+// sbomTool returns the name and version of the go command,
+// in the form used by SPDX creators: "go-1.23.0".
+// For development versions, such as "devel go1.23-d8e64c3 Mon Oct 19...",
+// it omits the "devel" prefix and the date.
+func sbomTool() string {
+	v := runtime.Version()
+	if f := strings.Fields(v); len(f) > 1 && f[0] == "devel" {
+		v = f[1]
+	}
+	return "go-" + strings.TrimPrefix(v, "go")
+}
+
+// purl returns the package URL (https://github.com/package-url/purl-spec)
+// of the module m, or "" if m has no version, as is the case for
+// a main module built from a local directory or a directory replacement.
+func purl(m *debug.Module) string {
+	if m.Path == "" || m.Version == "" || m.Version == "(devel)" {
+		return ""
+	}
+	elems := strings.Split(m.Path, "/")
+	for i, e := range elems {
+		elems[i] = url.PathEscape(e)
+	}
+	return "pkg:golang/" + strings.Join(elems, "/") + "@" + url.PathEscape(m.Version)
+}
+
+// fileSHA256 returns the hexadecimal SHA-256 hash of the named file.
+func fileSHA256(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// effective returns the module whose code is linked into the binary
+// for m: its replacement, if any, or m itself.
+func effective(m *debug.Module) *debug.Module {
+	if m.Replace != nil {
+		return m.Replace
+	}
+	return m
+}
+
+// mainModule returns the main module of bi.
+// For binaries built outside a module, such as those built from
+// a list of files or in GOPATH mode, it uses the main package path.
+func mainModule(bi *debug.BuildInfo) *debug.Module {
+	if bi.Main.Path != "" {
+		return &bi.Main
+	}
+	return &debug.Module{Path: bi.Path}
+}
+
+// SPDX 2.3 JSON document structure.
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []*spdxPackage     `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+	Annotations           []spdxAnnotation  `json:"annotations,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxAnnotation struct {
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Annotator      string `json:"annotator"`
+	Comment        string `json:"comment"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxSBOM returns an SPDX 2.3 document describing the binary file
+// with the build information bi.
+func spdxSBOM(file string, bi *debug.BuildInfo) (*spdxDocument, error) {
+	fileHash, err := fileSHA256(file)
+	if err != nil {
+		return nil, err
+	}
+	created := time.Now().UTC().Format(time.RFC3339)
+	tool := "Tool: " + sbomTool()
+
+	newPackage := func(id string, m *debug.Module) *spdxPackage {
+		p := &spdxPackage{
+			Name:             m.Path,
+			SPDXID:           id,
+			VersionInfo:      m.Version,
+			DownloadLocation: "NOASSERTION",
+		}
+		if m.Sum != "" {
+			p.Annotations = append(p.Annotations, spdxAnnotation{
+				AnnotationDate: created,
+				AnnotationType: "OTHER",
+				Annotator:      tool,
+				Comment:        "go.sum hash " + m.Sum,
+			})
+		}
+		if u := purl(m); u != "" {
+			p.ExternalRefs = []spdxExternalRef{{"PACKAGE-MANAGER", "purl", u}}
+		}
+		return p
+	}
+
+	main := mainModule(bi)
+	root := newPackage("SPDXRef-Package-main", effective(main))
+	root.PrimaryPackagePurpose = "APPLICATION"
+	root.Checksums = []spdxChecksum{{"SHA256", fileHash}} // the binary itself
+	root.Comment = "Binary " + filepath.Base(file) + " built from package " + bi.Path
+	if main.Replace != nil {
+		root.Comment += "; replaces " + main.Path + " " + main.Version
+	}
+	for _, s := range bi.Settings {
+		root.Annotations = append(root.Annotations, spdxAnnotation{
+			AnnotationDate: created,
+			AnnotationType: "OTHER",
+			Annotator:      tool,
+			Comment:        "build setting " + s.Key + "=" + s.Value,
+		})
+	}
+
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              filepath.Base(file),
+		DocumentNamespace: "https://go.dev/spdx/" + url.PathEscape(filepath.Base(file)) + "-" + fileHash,
+		CreationInfo: spdxCreationInfo{
+			Created:  created,
+			Creators: []string{tool},
+		},
+		Packages: []*spdxPackage{root},
+		Relationships: []spdxRelationship{
+			{"SPDXRef-DOCUMENT", "DESCRIBES", root.SPDXID},
+		},
+	}
+
+	if bi.GoVersion != "" {
+		toolchain := &spdxPackage{
+			Name:             "go",
+			SPDXID:           "SPDXRef-Package-toolchain",
+			VersionInfo:      bi.GoVersion,
+			DownloadLocation: "https://go.dev/dl/",
+			ExternalRefs: []spdxExternalRef{
+				{"PACKAGE-MANAGER", "purl", "pkg:golang/stdlib@" + url.PathEscape(bi.GoVersion)},
+			},
+			PrimaryPackagePurpose: "APPLICATION",
+		}
+		doc.Packages = append(doc.Packages, toolchain)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{toolchain.SPDXID, "BUILD_TOOL_OF", root.SPDXID})
+	}
+
+	for i, dep := range bi.Deps {
+		p := newPackage(fmt.Sprintf("SPDXRef-Package-dep-%d", i), effective(dep))
+		p.PrimaryPackagePurpose = "LIBRARY"
+		if dep.Replace != nil {
+			p.Comment = "replaces " + dep.Path + " " + dep.Version
+		}
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{root.SPDXID, "DEPENDS_ON", p.SPDXID})
+	}
+	return doc, nil
+}
+
+// CycloneDX 1.5 JSON document structure.
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []*cdxComponent `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string        `json:"timestamp"`
+	Tools     cdxTools      `json:"tools"`
+	Component *cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []*cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	Hashes     []cdxHash     `json:"hashes,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Pedigree   *cdxPedigree  `json:"pedigree,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxPedigree struct {
+	Ancestors []*cdxComponent `json:"ancestors"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// cycloneDXSBOM returns a CycloneDX 1.5 BOM describing the binary file
+// with the build information bi.
+func cycloneDXSBOM(file string, bi *debug.BuildInfo) (*cdxBOM, error) {
+	fileHash, err := fileSHA256(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// newComponent returns the component for the module m,
+	// recording the module it replaces, if any.
+	newComponent := func(typ, ref string, m *debug.Module) *cdxComponent {
+		e := effective(m)
+		c := &cdxComponent{
+			Type:    typ,
+			BOMRef:  ref,
+			Name:    e.Path,
+			Version: e.Version,
+			PURL:    purl(e),
+		}
+		if e.Sum != "" {
+			c.Properties = append(c.Properties, cdxProperty{"go:sum", e.Sum})
+		}
+		if m.Replace != nil {
+			c.Pedigree = &cdxPedigree{Ancestors: []*cdxComponent{{
+				Type:    "library",
+				Name:    m.Path,
+				Version: m.Version,
+				PURL:    purl(m),
+			}}}
+		}
+		return c
+	}
+
+	root := newComponent("application", "main", mainModule(bi))
+	root.Hashes = []cdxHash{{"SHA-256", fileHash}} // the binary itself
+	root.Properties = append(root.Properties,
+		cdxProperty{"go:binary", filepath.Base(file)},
+		cdxProperty{"go:path", bi.Path},
+		cdxProperty{"go:version", bi.GoVersion},
+	)
+	for _, s := range bi.Settings {
+		root.Properties = append(root.Properties, cdxProperty{"go:build:" + s.Key, s.Value})
+	}
+
+	bom := &cdxBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools: cdxTools{Components: []*cdxComponent{{
+				Type:    "application",
+				Name:    "go",
+				Version: strings.TrimPrefix(sbomTool(), "go-"),
+			}}},
+			Component: root,
+		},
+		Components:   []*cdxComponent{},
+		Dependencies: []cdxDependency{{Ref: root.BOMRef, DependsOn: []string{}}},
+	}
+	if bi.GoVersion != "" {
+		// The toolchain contributes the standard library and runtime.
+		bom.Components = append(bom.Components, &cdxComponent{
+			Type:    "framework",
+			BOMRef:  "toolchain",
+			Name:    "go",
+			Version: bi.GoVersion,
+			PURL:    "pkg:golang/stdlib@" + url.PathEscape(bi.GoVersion),
+		})
+		bom.Dependencies[0].DependsOn = append(bom.Dependencies[0].DependsOn, "toolchain")
+	}
+	for i, dep := range bi.Deps {
+		c := newComponent("library", fmt.Sprintf("dep-%d", i), dep)
+		bom.Components = append(bom.Components, c)
+		bom.Dependencies[0].DependsOn = append(bom.Dependencies[0].DependsOn, c.BOMRef)
+	}
+	return bom, nil
+}
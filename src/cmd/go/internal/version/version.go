@@ -8,6 +8,7 @@ package version
 import (
 	"context"
 	"debug/buildinfo"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -21,7 +22,7 @@ import (
 )
 
 var CmdVersion = &base.Command{
-	UsageLine: "go version [-m] [-v] [file ...]",
+	UsageLine: "go version [-m] [-v] [-json] [-sbom format] [file ...]",
 	Short:     "print Go version",
 	Long: `Version prints the build information for Go binary files.
 
@@ -40,6 +41,23 @@ module version information, when available. In the output, the module
 information consists of multiple lines following the version line, each
 indented by a leading tab character.
 
+The -json flag is similar to -m but outputs a runtime/debug.BuildInfo
+structure in JSON format. If flag -json is specified without -m,
+go version reports an error.
+
+The -sbom flag causes go version to print a software bill of materials
+(SBOM) for each file, derived from its embedded build information, in
+the given format: "spdx" for an SPDX 2.3 JSON document, or "cyclonedx"
+for a CycloneDX 1.5 JSON BOM. The SBOM describes the main module as
+the root component, with the SHA-256 hash of the binary, the build
+settings, and the Go version that built it; each dependency module,
+with its go.sum hash and the module it replaces, if any; and the Go
+toolchain. A go.sum hash is not the hash of a file, so it is recorded
+as an annotation (SPDX) or a "go:sum" property (CycloneDX) of the
+module, not as a checksum. Because the information is read from the binary, an SBOM
+can be produced for any Go binary built with module support, without
+rebuilding it.
+
 See also: go doc runtime/debug.BuildInfo.
 `,
 }
@@ -50,8 +68,10 @@ func init() {
 }
 
 var (
-	versionM = CmdVersion.Flag.Bool("m", false, "")
-	versionV = CmdVersion.Flag.Bool("v", false, "")
+	versionM    = CmdVersion.Flag.Bool("m", false, "")
+	versionV    = CmdVersion.Flag.Bool("v", false, "")
+	versionJson = CmdVersion.Flag.Bool("json", false, "")
+	versionSBOM = CmdVersion.Flag.String("sbom", "", "")
 )
 
 func runVersion(ctx context.Context, cmd *base.Command, args []string) {
@@ -68,6 +88,10 @@ func runVersion(ctx context.Context, cmd *base.Command, args []string) {
 			argOnlyFlag = "-m"
 		} else if !base.InGOFLAGS("-v") && *versionV {
 			argOnlyFlag = "-v"
+		} else if !base.InGOFLAGS("-json") && *versionJson {
+			argOnlyFlag = "-json"
+		} else if !base.InGOFLAGS("-sbom") && *versionSBOM != "" {
+			argOnlyFlag = "-sbom"
 		}
 		if argOnlyFlag != "" {
 			fmt.Fprintf(os.Stderr, "go: 'go version' only accepts %s flag with arguments\n", argOnlyFlag)
@@ -82,6 +106,24 @@ func runVersion(ctx context.Context, cmd *base.Command, args []string) {
 		return
 	}
 
+	if !*versionM && *versionJson {
+		fmt.Fprintf(os.Stderr, "go: 'go version' -json flag requires -m flag\n")
+		base.SetExitStatus(2)
+		return
+	}
+	switch *versionSBOM {
+	case "", "spdx", "cyclonedx":
+	default:
+		fmt.Fprintf(os.Stderr, "go: 'go version' -sbom flag must be spdx or cyclonedx, not %q\n", *versionSBOM)
+		base.SetExitStatus(2)
+		return
+	}
+	if *versionSBOM != "" && *versionJson {
+		fmt.Fprintf(os.Stderr, "go: 'go version' -sbom and -json flags are mutually exclusive\n")
+		base.SetExitStatus(2)
+		return
+	}
+
 	for _, arg := range args {
 		info, err := os.Stat(arg)
 		if err != nil {
@@ -164,10 +206,39 @@ func scanFile(file string, info fs.FileInfo, mustPrint bool) {
 		return
 	}
 
-	fmt.Printf("%s: %s\n", file, bi.GoVersion)
-	bi.GoVersion = "" // suppress printing go version again
-	mod := bi.String()
-	if *versionM && len(mod) > 0 {
-		fmt.Printf("\t%s\n", strings.ReplaceAll(mod[:len(mod)-1], "\n", "\n\t"))
+	switch {
+	case *versionSBOM != "":
+		var sbom any
+		if *versionSBOM == "spdx" {
+			sbom, err = spdxSBOM(file, bi)
+		} else {
+			sbom, err = cycloneDXSBOM(file, bi)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			base.SetExitStatus(1)
+			return
+		}
+		printJSON(sbom)
+
+	case *versionM && *versionJson:
+		printJSON(bi)
+
+	default:
+		fmt.Printf("%s: %s\n", file, bi.GoVersion)
+		bi.GoVersion = "" // suppress printing go version again
+		mod := bi.String()
+		if *versionM && len(mod) > 0 {
+			fmt.Printf("\t%s\n", strings.ReplaceAll(mod[:len(mod)-1], "\n", "\n\t"))
+		}
+	}
+}
+
+// printJSON prints v to standard output as indented JSON.
+func printJSON(v any) {
+	bs, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		base.Fatal(err)
 	}
+	fmt.Printf("%s\n", bs)
 }
@@ -173,7 +173,10 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 		}
 	}
 
-	fetch := func(url *urlpkg.URL) (*http.Response, error) {
+	// send issues a GET request for url.
+	// If rejected is non-nil, it is the response to an earlier request
+	// for url that the server refused to authorize.
+	send := func(url *urlpkg.URL, rejected *http.Response) (*http.Response, error) {
 		// Note: The -v build flag does not mean "print logging information",
 		// despite its historical misuse for this in GOPATH-based go get.
 		// We print extra logging in -x mode instead, which traces what
@@ -187,7 +190,7 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 			return nil, err
 		}
 		if url.Scheme == "https" {
-			auth.AddCredentials(req)
+			auth.AddCredentials(req, rejected)
 		}
 		t, intercepted := interceptURL(req.URL)
 		if intercepted {
@@ -231,6 +234,21 @@ func get(security SecurityMode, url *urlpkg.URL) (*Response, error) {
 		return res, err
 	}
 
+	fetch := func(url *urlpkg.URL) (*http.Response, error) {
+		res, err := send(url, nil)
+		if err == nil && url.Scheme == "https" &&
+			(res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden) {
+			// Give the GOAUTH commands a chance to provide
+			// credentials for this URL, and try once more.
+			if cfg.BuildX {
+				fmt.Fprintf(os.Stderr, "# get %s: %v; retrying with GOAUTH credentials\n", url.Redacted(), res.Status)
+			}
+			res.Body.Close()
+			res, err = send(url, res)
+		}
+		return res, err
+	}
+
 	var (
 		fetched *urlpkg.URL
 		res     *http.Response
@@ -92,10 +92,13 @@ type Action struct {
 
 	TryCache func(*Builder, *Action) bool // callback for cache bypass
 
+	CacheExecutable bool // Whether to cache executables produced by link steps
+
 	// Generated files, directories.
 	Objdir   string         // directory for intermediate objects
 	Target   string         // goal of the action: the created package or executable
 	built    string         // the actual created package or executable
+	cached   bool           // whether built was reused from the cache or an up-to-date target
 	actionID cache.ActionID // cache ID of action input
 	buildID  string         // build ID of action output
 
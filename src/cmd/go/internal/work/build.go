@@ -26,7 +26,7 @@ import (
 )
 
 var CmdBuild = &base.Command{
-	UsageLine: "go build [-o output] [build flags] [packages]",
+	UsageLine: "go build [-o output] [-json] [build flags] [packages]",
 	Short:     "compile packages and dependencies",
 	Long: `
 Build compiles the packages named by the import paths,
@@ -58,6 +58,10 @@ in the last two paragraphs. If the named output is an existing directory or
 ends with a slash or backslash, then any resulting executables
 will be written to that directory.
 
+The -json flag causes build to report its progress, output and failures
+as a stream of JSON events on standard output instead of text on
+standard error. See 'go help buildjson' for the encoding details.
+
 The build flags are shared by the build, clean, get, install, list, run,
 and test commands:
 
@@ -239,6 +243,8 @@ func init() {
 	CmdInstall.Run = runInstall
 
 	CmdBuild.Flag.StringVar(&cfg.BuildO, "o", "", "output file or directory")
+	CmdBuild.Flag.BoolVar(&cfg.BuildJSON, "json", false, "")
+	CmdInstall.Flag.BoolVar(&cfg.BuildJSON, "json", false, "")
 
 	AddBuildFlags(CmdBuild, DefaultBuildFlags)
 	AddBuildFlags(CmdInstall, DefaultBuildFlags)
@@ -559,7 +565,7 @@ func runBuild(ctx context.Context, cmd *base.Command, args []string) {
 }
 
 var CmdInstall = &base.Command{
-	UsageLine: "go install [build flags] [packages]",
+	UsageLine: "go install [-json] [build flags] [packages]",
 	Short:     "compile and install packages and dependencies",
 	Long: `
 Install compiles and installs the packages named by the import paths.
@@ -613,6 +619,9 @@ Starting in Go 1.20, the standard library is built and cached but not installed.
 Setting GODEBUG=installgoroot=all restores the use of
 $GOROOT/pkg/$GOOS_$GOARCH.
 
+The -json flag reports the progress, output and failures of the build
+as JSON events, as for 'go build -json'. See 'go help buildjson'.
+
 For more about build flags, see 'go help build'.
 
 For more about specifying packages, see 'go help packages'.
@@ -5,7 +5,6 @@
 package work
 
 import (
-	"fmt"
 	"internal/testenv"
 	"io/fs"
 	"os"
@@ -226,9 +225,7 @@ func TestRespectSetgidDir(t *testing.T) {
 	// of `(*Shell).ShowCmd` afterwards as a sanity check.
 	cfg.BuildX = true
 	var cmdBuf strings.Builder
-	sh := NewShell("", func(a ...any) (int, error) {
-		return cmdBuf.WriteString(fmt.Sprint(a...))
-	})
+	sh := NewShell("", &load.TextPrinter{Writer: &cmdBuf})
 
 	setgiddir, err := os.MkdirTemp("", "SetGroupID")
 	if err != nil {
@@ -460,6 +460,7 @@ func (b *Builder) useCache(a *Action, actionHash cache.ActionID, target string,
 			a.built = target
 			// Poison a.Target to catch uses later in the build.
 			a.Target = "DO NOT USE - " + a.Mode
+			a.cached = true
 			return true
 		}
 		// Special case for building a main package: if the only thing we
@@ -497,6 +498,7 @@ func (b *Builder) useCache(a *Action, actionHash cache.ActionID, target string,
 					if a.json != nil {
 						a.json.BuildID = a.buildID
 					}
+					a.cached = true
 					return true
 				}
 				// Otherwise restore old build ID for main build.
@@ -522,6 +524,7 @@ func (b *Builder) useCache(a *Action, actionHash cache.ActionID, target string,
 		// Poison a.Target to catch uses later in the build.
 		a.Target = "DO NOT USE -  pseudo-cache Target"
 		a.built = "DO NOT USE - pseudo-cache built"
+		a.cached = true
 		return true
 	}
 
@@ -542,6 +545,7 @@ func (b *Builder) useCache(a *Action, actionHash cache.ActionID, target string,
 				// Clearer than explaining that something else is stale.
 				p.StaleReason = "not installed but available in build cache"
 			}
+			a.cached = true
 			return true
 		}
 	}
@@ -677,7 +681,8 @@ func (b *Builder) updateBuildID(a *Action, target string, rewrite bool) error {
 		}
 	}
 
-	// Cache package builds, but not binaries (link steps).
+	// Cache package builds, and binaries (link steps) only when
+	// explicitly requested, as 'go tool' does for module tools.
 	// The expectation is that binaries are not reused
 	// nearly as often as individual packages, and they're
 	// much larger, so the cache-footprint-to-utility ratio
@@ -710,6 +715,20 @@ func (b *Builder) updateBuildID(a *Action, target string, rewrite bool) error {
 			}
 		}
 	}
+	if c, ok := c.(*cache.DiskCache); a.Mode == "link" && a.CacheExecutable && ok {
+		r, err := os.Open(target)
+		if err == nil {
+			name := a.Package.Internal.ExeName
+			if name == "" {
+				name = a.Package.DefaultExecName()
+			}
+			outputID, _, err := c.PutExecutable(a.actionID, name+cfg.ExeSuffix, r)
+			r.Close()
+			if err == nil && cfg.BuildX {
+				sh.ShowCmd("", "%s # internal", joinUnambiguously(str.StringList("cp", target, c.OutputFile(outputID))))
+			}
+		}
+	}
 
 	return nil
 }
@@ -149,7 +149,16 @@ func (b *Builder) Do(ctx context.Context, root *Action) {
 			for _, d := range a.Deps {
 				trace.Flow(ctx, d.traceSpan, a.traceSpan)
 			}
+			// With -json, report the start and successful end of
+			// compile and link steps; failures are reported below.
+			reportEvents := cfg.BuildJSON && a.Package != nil && (a.Mode == "build" || a.Mode == "link")
+			if reportEvents {
+				b.Shell(a).event("build-start", false)
+			}
 			err = a.Actor.Act(b, ctx, a)
+			if reportEvents && err == nil {
+				b.Shell(a).event("build-end", a.cached)
+			}
 			span.Done()
 		}
 		if a.json != nil {
@@ -172,7 +181,7 @@ func (b *Builder) Do(ctx context.Context, root *Action) {
 				if a.Package != nil && (!errors.As(err, &ipe) || ipe.ImportPath() != a.Package.ImportPath) {
 					err = fmt.Errorf("%s: %v", a.Package.ImportPath, err)
 				}
-				base.Errorf("%s", err)
+				b.Shell(a).Errorf("%s", err)
 			}
 			a.Failed = true
 		}
@@ -42,7 +42,7 @@ type shellShared struct {
 	workDir string // $WORK, immutable
 
 	printLock sync.Mutex
-	printFunc func(args ...any) (int, error)
+	printer   load.Printer
 	scriptDir string // current directory in printed script
 
 	mkdirCache par.Cache[string, error] // a cache of created directories
@@ -50,31 +50,53 @@ type shellShared struct {
 
 // NewShell returns a new Shell.
 //
-// Shell will internally serialize calls to the print function.
-// If print is nil, it defaults to printing to stderr.
-func NewShell(workDir string, print func(a ...any) (int, error)) *Shell {
-	if print == nil {
-		print = func(a ...any) (int, error) {
-			return fmt.Fprint(os.Stderr, a...)
-		}
+// Shell will internally serialize calls to the printer.
+// If printer is nil, it uses load.DefaultPrinter.
+func NewShell(workDir string, printer load.Printer) *Shell {
+	if printer == nil {
+		printer = load.DefaultPrinter()
 	}
 	shared := &shellShared{
-		workDir:   workDir,
-		printFunc: print,
+		workDir: workDir,
+		printer: printer,
 	}
 	return &Shell{shellShared: shared}
 }
 
+func (sh *Shell) pkg() *load.Package {
+	if sh.action == nil {
+		return nil
+	}
+	return sh.action.Package
+}
+
 // Print emits a to this Shell's output stream, formatting it like fmt.Print.
 // It is safe to call concurrently.
 func (sh *Shell) Print(a ...any) {
 	sh.printLock.Lock()
 	defer sh.printLock.Unlock()
-	sh.printFunc(a...)
+	sh.printer.Printf(sh.pkg(), "%s", fmt.Sprint(a...))
 }
 
 func (sh *Shell) printLocked(a ...any) {
-	sh.printFunc(a...)
+	sh.printer.Printf(sh.pkg(), "%s", fmt.Sprint(a...))
+}
+
+// Errorf reports an error on sh's output stream and
+// that building sh's package failed. See [load.Printer.Errorf].
+// It is safe to call concurrently.
+func (sh *Shell) Errorf(format string, a ...any) {
+	sh.printLock.Lock()
+	defer sh.printLock.Unlock()
+	sh.printer.Errorf(sh.pkg(), format, a...)
+}
+
+// event reports a build action event on sh's output stream.
+// See [load.Printer.Event].
+func (sh *Shell) event(action string, cached bool) {
+	sh.printLock.Lock()
+	defer sh.printLock.Unlock()
+	sh.printer.Event(sh.pkg(), sh.action.Mode, action, cached)
 }
 
 // WithAction returns a Shell identical to sh, but bound to Action a.
@@ -46,6 +46,7 @@ var runtimePkgs = []string{
 	"runtime/internal/atomic",
 	"runtime/internal/math",
 	"runtime/internal/sys",
+	"internal/runtime/cgroup",
 	"internal/runtime/syscall",
 
 	"internal/abi",
@@ -149,6 +149,21 @@ func (f *elfFile) dwarf() (*dwarf.Data, error) {
 	return f.elf.DWARF()
 }
 
+func (f *elfFile) sections() ([]Section, error) {
+	var sects []Section
+	for _, s := range f.elf.Sections {
+		if s.Type == elf.SHT_NULL {
+			continue
+		}
+		size := s.FileSize
+		if s.Type == elf.SHT_NOBITS {
+			size = 0
+		}
+		sects = append(sects, Section{Name: s.Name, Addr: s.Addr, Size: size})
+	}
+	return sects, nil
+}
+
 func (f *elfFile) symbolData(start, end string) []byte {
 	elfSyms, err := f.elf.Symbols()
 	if err != nil {
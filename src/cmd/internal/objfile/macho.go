@@ -137,3 +137,16 @@ func (f *machoFile) loadAddress() (uint64, error) {
 func (f *machoFile) dwarf() (*dwarf.Data, error) {
 	return f.macho.DWARF()
 }
+
+func (f *machoFile) sections() ([]Section, error) {
+	const sZerofill = 0x1 // section type of zero-filled sections such as __bss
+	var sects []Section
+	for _, s := range f.macho.Sections {
+		size := s.Size
+		if s.Flags&0xff == sZerofill {
+			size = 0
+		}
+		sects = append(sects, Section{Name: s.Name, Addr: s.Addr, Size: size})
+	}
+	return sects, nil
+}
@@ -200,3 +200,15 @@ func (f *peFile) imageBase() (uint64, error) {
 func (f *peFile) dwarf() (*dwarf.Data, error) {
 	return f.pe.DWARF()
 }
+
+func (f *peFile) sections() ([]Section, error) {
+	base, err := f.imageBase()
+	if err != nil {
+		return nil, err
+	}
+	var sects []Section
+	for _, s := range f.pe.Sections {
+		sects = append(sects, Section{Name: s.Name, Addr: base + uint64(s.VirtualAddress), Size: uint64(s.Size)})
+	}
+	return sects, nil
+}
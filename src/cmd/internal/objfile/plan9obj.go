@@ -154,3 +154,11 @@ func (f *plan9File) loadAddress() (uint64, error) {
 func (f *plan9File) dwarf() (*dwarf.Data, error) {
 	return nil, errors.New("no DWARF data in Plan 9 file")
 }
+
+func (f *plan9File) sections() ([]Section, error) {
+	var sects []Section
+	for _, s := range f.plan9.Sections {
+		sects = append(sects, Section{Name: s.Name, Size: uint64(s.Size)})
+	}
+	return sects, nil
+}
@@ -161,3 +161,15 @@ func (f *xcoffFile) loadAddress() (uint64, error) {
 func (f *xcoffFile) dwarf() (*dwarf.Data, error) {
 	return f.xcoff.DWARF()
 }
+
+func (f *xcoffFile) sections() ([]Section, error) {
+	var sects []Section
+	for _, s := range f.xcoff.Sections {
+		size := s.Size
+		if s.Type == xcoff.STYP_BSS {
+			size = 0
+		}
+		sects = append(sects, Section{Name: s.Name, Addr: s.VirtualAddress, Size: size})
+	}
+	return sects, nil
+}
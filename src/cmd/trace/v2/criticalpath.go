@@ -0,0 +1,456 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trace
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"internal/trace/traceviewer"
+	tracev2 "internal/trace/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The critical path of a task or region is the chain of goroutine
+// activity that determined when it ended. It is computed backwards from
+// the goroutine and time at which the task or region ended: whenever
+// that goroutine was waiting and was woken by another goroutine, or
+// was created by one, the path continues with the goroutine that woke
+// or created it. The time on the path is then broken down by what the
+// goroutines on it were doing.
+
+// A pathSegment is a part of a critical path, during which one
+// goroutine was in one state.
+type pathSegment struct {
+	Goroutine  tracev2.GoID
+	Start, End tracev2.Time
+
+	// Category is the name of the non-overlapping statistic the
+	// segment counts towards, such as "Execution time".
+	Category string
+
+	// WokenBy is the goroutine that woke or created the goroutine
+	// of the segment, if the path continues with it before the segment.
+	WokenBy tracev2.GoID
+
+	// Wait describes the wait ended by WokenBy, if any.
+	Wait *pathWait
+}
+
+// A pathWait is a wait of a goroutine on the critical path, ended by
+// another goroutine: the path covers the wait with the activity of
+// that goroutine.
+type pathWait struct {
+	Reason   string
+	Duration time.Duration
+	Stack    tracev2.Stack // where the goroutine waited
+}
+
+// gTimeline is what the critical path needs to know of a goroutine.
+type gTimeline struct {
+	transitions []gTransition // in time order
+	ranges      []gRange      // in start time order
+}
+
+// gTransition is a state transition of a goroutine.
+type gTransition struct {
+	time     tracev2.Time
+	from, to tracev2.GoState
+	reason   string
+	stack    tracev2.Stack // stack of the goroutine, if it made the transition
+	by       tracev2.GoID  // goroutine that caused the transition, if another
+}
+
+// gRange is a range of time, such as a GC mark assist, that a goroutine
+// spent on behalf of the runtime while running.
+type gRange struct {
+	name       string
+	start, end tracev2.Time
+}
+
+// goroutineTimelines collects the state transitions and the ranges of
+// all the goroutines in the trace.
+func goroutineTimelines(t *parsedTrace) map[tracev2.GoID]*gTimeline {
+	timelines := make(map[tracev2.GoID]*gTimeline)
+	timeline := func(id tracev2.GoID) *gTimeline {
+		tl := timelines[id]
+		if tl == nil {
+			tl = new(gTimeline)
+			timelines[id] = tl
+		}
+		return tl
+	}
+	open := make(map[tracev2.GoID]map[string]tracev2.Time) // active ranges
+	for i := range t.events {
+		ev := &t.events[i]
+		switch ev.Kind() {
+		case tracev2.EventStateTransition:
+			st := ev.StateTransition()
+			if st.Resource.Kind != tracev2.ResourceGoroutine {
+				continue
+			}
+			id := st.Resource.Goroutine()
+			from, to := st.Goroutine()
+			tr := gTransition{time: ev.Time(), from: from, to: to, reason: st.Reason, by: tracev2.NoGoroutine}
+			if g := ev.Goroutine(); g == id {
+				tr.stack = ev.Stack()
+			} else {
+				tr.by = g
+			}
+			tl := timeline(id)
+			tl.transitions = append(tl.transitions, tr)
+		case tracev2.EventRangeBegin, tracev2.EventRangeActive:
+			r := ev.Range()
+			if r.Scope.Kind != tracev2.ResourceGoroutine {
+				continue
+			}
+			id := r.Scope.Goroutine()
+			if open[id] == nil {
+				open[id] = make(map[string]tracev2.Time)
+			}
+			open[id][r.Name] = ev.Time()
+		case tracev2.EventRangeEnd:
+			r := ev.Range()
+			if r.Scope.Kind != tracev2.ResourceGoroutine {
+				continue
+			}
+			id := r.Scope.Goroutine()
+			start, ok := open[id][r.Name]
+			if !ok {
+				// The range began before the trace.
+				start = t.startTime()
+			}
+			delete(open[id], r.Name)
+			tl := timeline(id)
+			tl.ranges = append(tl.ranges, gRange{name: r.Name, start: start, end: ev.Time()})
+		}
+	}
+	// Close the ranges still active at the end of the trace.
+	for id, names := range open {
+		for name, start := range names {
+			tl := timeline(id)
+			tl.ranges = append(tl.ranges, gRange{name: name, start: start, end: t.endTime()})
+		}
+	}
+	for _, tl := range timelines {
+		slices.SortFunc(tl.ranges, func(a, b gRange) int {
+			return cmp.Compare(a.start, b.start)
+		})
+	}
+	return timelines
+}
+
+// criticalPath returns the critical path of the activity that goroutine
+// g finished at time end, back to time start, in time order.
+func criticalPath(timelines map[tracev2.GoID]*gTimeline, g tracev2.GoID, start, end tracev2.Time) []pathSegment {
+	var path []pathSegment // in reverse time order
+	add := func(seg pathSegment) {
+		if seg.Start < seg.End || seg.WokenBy != tracev2.NoGoroutine {
+			path = append(path, seg)
+		}
+	}
+	cur, t := g, end
+	for t > start {
+		var trs []gTransition
+		if tl := timelines[cur]; tl != nil {
+			trs = tl.transitions
+		}
+		// Find the state of cur just before t.
+		i := sort.Search(len(trs), func(i int) bool { return trs[i].time >= t }) - 1
+		if i < 0 {
+			add(pathSegment{Goroutine: cur, Start: start, End: t, Category: "Unknown time", WokenBy: tracev2.NoGoroutine})
+			break
+		}
+		tr := trs[i]
+		segStart := max(tr.time, start)
+		seg := pathSegment{Goroutine: cur, Start: segStart, End: t, WokenBy: tracev2.NoGoroutine}
+		next := cur
+		switch tr.to {
+		case tracev2.GoRunning:
+			// Split the execution by the runtime's ranges.
+			parts := runningRanges(timelines[cur].ranges, segStart, t)
+			for j := len(parts) - 1; j >= 0; j-- {
+				r := parts[j]
+				add(pathSegment{Goroutine: cur, Start: r.start, End: r.end, Category: r.name, WokenBy: tracev2.NoGoroutine})
+			}
+			t = segStart
+			continue
+		case tracev2.GoRunnable:
+			seg.Category = "Sched wait time"
+			if tr.by != tracev2.NoGoroutine && tr.time > start {
+				// Follow the goroutine that woke or created cur.
+				seg.WokenBy = tr.by
+				next = tr.by
+				if tr.from == tracev2.GoWaiting && i > 0 {
+					w := trs[i-1]
+					seg.Wait = &pathWait{Reason: w.reason, Duration: tr.time.Sub(w.time), Stack: w.stack}
+				}
+			}
+		case tracev2.GoWaiting:
+			seg.Category = "Block time (" + tr.reason + ")"
+		case tracev2.GoSyscall:
+			seg.Category = "Syscall execution time"
+		default:
+			seg.Category = "Unknown time"
+		}
+		add(seg)
+		cur, t = next, segStart
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// runningRanges splits the interval [start, end] in which a goroutine
+// ran into the parts it spent in the given ranges, and the parts it
+// spent executing its own code, in time order.
+func runningRanges(ranges []gRange, start, end tracev2.Time) []gRange {
+	var parts []gRange
+	t := start
+	for _, r := range ranges {
+		if r.end <= t || r.start >= end {
+			continue
+		}
+		if r.start > t {
+			parts = append(parts, gRange{"Execution time", t, r.start})
+			t = r.start
+		}
+		rEnd := min(r.end, end)
+		parts = append(parts, gRange{r.name, t, rEnd})
+		t = rEnd
+	}
+	if t < end {
+		parts = append(parts, gRange{"Execution time", t, end})
+	}
+	return parts
+}
+
+// CriticalPathHandlerFunc returns a HandlerFunc that reports the
+// critical path of the activity of a goroutine over an interval,
+// such as a task or a region.
+func CriticalPathHandlerFunc(t *parsedTrace) http.HandlerFunc {
+	// The timelines are built on the first request,
+	// and shared by all the requests.
+	timelines := sync.OnceValue(func() map[tracev2.GoID]*gTimeline {
+		return goroutineTimelines(t)
+	})
+	return func(w http.ResponseWriter, r *http.Request) {
+		goid, err := strconv.ParseInt(r.FormValue("goid"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse goid parameter %q: %v", r.FormValue("goid"), err), http.StatusBadRequest)
+			return
+		}
+		start, err := strconv.ParseInt(r.FormValue("start"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse start parameter %q: %v", r.FormValue("start"), err), http.StatusBadRequest)
+			return
+		}
+		end, err := strconv.ParseInt(r.FormValue("end"), 10, 64)
+		if err != nil || end < start {
+			http.Error(w, fmt.Sprintf("invalid end parameter %q", r.FormValue("end")), http.StatusBadRequest)
+			return
+		}
+
+		path := criticalPath(timelines(), tracev2.GoID(goid), tracev2.Time(start), tracev2.Time(end))
+
+		type stat struct {
+			Name    string
+			Time    time.Duration
+			Percent float64
+		}
+		type wait struct {
+			Reason   string
+			Location string
+			Count    int
+			Time     time.Duration
+		}
+		type segment struct {
+			pathSegment
+			WhenString string
+			Duration   time.Duration
+			Wait       string
+		}
+		total := tracev2.Time(end).Sub(tracev2.Time(start))
+		statTimes := make(map[string]time.Duration)
+		waitStats := make(map[[2]string]*wait)
+		var segments []segment
+		goroutines := make(map[tracev2.GoID]bool)
+		for _, seg := range path {
+			d := seg.End.Sub(seg.Start)
+			statTimes[seg.Category] += d
+			goroutines[seg.Goroutine] = true
+			s := segment{
+				pathSegment: seg,
+				WhenString:  fmt.Sprintf("%2.9f", seg.Start.Sub(t.startTime()).Seconds()),
+				Duration:    d,
+			}
+			if seg.Wait != nil {
+				loc := stackLocation(seg.Wait.Stack)
+				s.Wait = fmt.Sprintf("waited %v (%s) at %s", seg.Wait.Duration, seg.Wait.Reason, loc)
+				key := [2]string{seg.Wait.Reason, loc}
+				ws := waitStats[key]
+				if ws == nil {
+					ws = &wait{Reason: seg.Wait.Reason, Location: loc}
+					waitStats[key] = ws
+				}
+				ws.Count++
+				ws.Time += seg.Wait.Duration
+			}
+			segments = append(segments, s)
+		}
+		var stats []stat
+		for name, d := range statTimes {
+			stats = append(stats, stat{name, d, 100 * float64(d) / float64(max(total, 1))})
+		}
+		slices.SortFunc(stats, func(a, b stat) int {
+			return cmp.Or(cmp.Compare(b.Time, a.Time), cmp.Compare(a.Name, b.Name))
+		})
+		var waits []*wait
+		for _, ws := range waitStats {
+			waits = append(waits, ws)
+		}
+		slices.SortFunc(waits, func(a, b *wait) int {
+			return cmp.Or(cmp.Compare(b.Time, a.Time), cmp.Compare(a.Reason, b.Reason), cmp.Compare(a.Location, b.Location))
+		})
+
+		err = templCriticalPath.Execute(w, struct {
+			Name       string
+			Goroutine  int64
+			Total      time.Duration
+			Goroutines int
+			Stats      []stat
+			Waits      []*wait
+			Segments   []segment
+		}{
+			Name:       r.FormValue("name"),
+			Goroutine:  goid,
+			Total:      total,
+			Goroutines: len(goroutines),
+			Stats:      stats,
+			Waits:      waits,
+			Segments:   segments,
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// stackLocation returns the location of the innermost frame of s
+// outside of the runtime, or of its innermost frame.
+func stackLocation(s tracev2.Stack) string {
+	if s == tracev2.NoStack {
+		return "unknown location"
+	}
+	var loc string
+	s.Frames(func(f tracev2.StackFrame) bool {
+		l := fmt.Sprintf("%s (%s:%d)", f.Func, f.File, f.Line)
+		if loc == "" {
+			loc = l
+		}
+		if !isRuntimeFunc(f.Func) {
+			loc = l
+			return false
+		}
+		return true
+	})
+	return loc
+}
+
+// isRuntimeFunc reports whether fn is a function of the runtime or of
+// the packages implementing blocking operations on top of it.
+func isRuntimeFunc(fn string) bool {
+	for _, prefix := range []string{"runtime.", "sync.", "internal/", "time.Sleep"} {
+		if len(fn) >= len(prefix) && fn[:len(prefix)] == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// criticalPathURL returns the URL of the critical path of the activity
+// of goroutine g over the interval i.
+func criticalPathURL(name string, g tracev2.GoID, i interval) string {
+	return fmt.Sprintf("/criticalpath?name=%s&goid=%d&start=%d&end=%d", template.URLQueryEscaper(name), g, i.start, i.end)
+}
+
+var templCriticalPath = template.Must(template.New("").Funcs(template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.1f%%", f) },
+}).Parse(`
+<!DOCTYPE html>
+<title>Critical path{{if .Name}}: {{.Name}}{{end}}</title>
+<style>` + traceviewer.CommonStyle + `
+th {
+  background-color: #050505;
+  color: #fff;
+}
+table {
+  border-collapse: collapse;
+}
+td,
+th {
+  padding-left: 8px;
+  padding-right: 8px;
+  padding-top: 4px;
+  padding-bottom: 4px;
+}
+td.when,
+td.duration {
+  text-align: right;
+  font-family: monospace;
+}
+</style>
+<body>
+<h2>Critical path{{if .Name}}: {{.Name}}{{end}}</h2>
+
+The critical path is the chain of goroutine activity that determined
+when goroutine {{.Goroutine}} finished the {{.Total}} interval below. It
+is followed backwards from that goroutine: when a goroutine on the path
+was woken or created by another goroutine, the path continues with the
+goroutine that woke it, and the time it waited is attributed to the
+activity of that goroutine. The path goes through {{.Goroutines}}
+goroutine(s).
+<br>
+<br>
+
+<h3>Breakdown</h3>
+<table>
+<tr><th>Time</th><th>Share</th><th>Activity</th></tr>
+{{range .Stats}}
+<tr><td class="duration">{{.Time}}</td><td class="duration">{{percent .Percent}}</td><td>{{.Name}}</td></tr>
+{{end}}
+</table>
+
+{{if .Waits}}
+<h3>Waits ended by goroutines on the path</h3>
+<table>
+<tr><th>Time</th><th>Count</th><th>Reason</th><th>Location</th></tr>
+{{range .Waits}}
+<tr><td class="duration">{{.Time}}</td><td class="duration">{{.Count}}</td><td>{{.Reason}}</td><td>{{.Location}}</td></tr>
+{{end}}
+</table>
+{{end}}
+
+<h3>Path</h3>
+<table>
+<tr><th>When</th><th>Duration</th><th>Goroutine</th><th>Activity</th><th>Details</th></tr>
+{{range .Segments}}
+<tr>
+  <td class="when">{{.WhenString}}</td>
+  <td class="duration">{{.Duration}}</td>
+  <td><a href="/goroutine?id={{.Goroutine}}">{{.Goroutine}}</a></td>
+  <td>{{.Category}}</td>
+  <td>{{if ne .WokenBy -1}}woken by goroutine <a href="/goroutine?id={{.WokenBy}}">{{.WokenBy}}</a>{{if .Wait}}, {{.Wait}}{{end}}{{end}}</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,94 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trace
+
+import (
+	"bytes"
+	"context"
+	tracev2 "internal/trace/v2"
+	"net/http/httptest"
+	rtrace "runtime/trace"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCriticalPath(t *testing.T) {
+	if rtrace.IsEnabled() {
+		t.Skip("skipping because -test.trace is set")
+	}
+	var buf bytes.Buffer
+	if err := rtrace.Start(&buf); err != nil {
+		t.Fatalf("failed to start tracing: %v", err)
+	}
+	ctx, task := rtrace.NewTask(context.Background(), "criticalPathTask")
+	c := make(chan int)
+	go func() {
+		// Work on behalf of the task, then hand the result over.
+		var sum int
+		for start := time.Now(); time.Since(start) < 10*time.Millisecond; {
+			sum++
+		}
+		rtrace.Log(ctx, "worker", "done")
+		c <- sum
+	}()
+	<-c
+	task.End()
+	rtrace.Stop()
+
+	parsed, err := parseTrace(&buf)
+	if err != nil {
+		t.Fatalf("failed to parse trace: %v", err)
+	}
+	var end *tracev2.Event
+	var span interval
+	for _, s := range parsed.summary.Tasks {
+		if s.Name == "criticalPathTask" && s.Complete() {
+			end = s.End
+			span = taskInterval(parsed, s)
+		}
+	}
+	if end == nil {
+		t.Fatal("task not found in trace")
+	}
+	g := end.Goroutine()
+	path := criticalPath(goroutineTimelines(parsed), g, span.start, span.end)
+	if len(path) == 0 {
+		t.Fatal("empty critical path")
+	}
+
+	// The path must go through the worker goroutine, from the wait
+	// of the task's goroutine on the channel.
+	var woken bool
+	var workerTime time.Duration
+	for i, seg := range path {
+		if i > 0 && seg.Start < path[i-1].End {
+			t.Errorf("segment %d starts at %d, before the end of segment %d at %d", i, seg.Start, i-1, path[i-1].End)
+		}
+		if seg.Goroutine == g && seg.Wait != nil && strings.Contains(seg.Wait.Reason, "chan") {
+			woken = seg.WokenBy != g && seg.WokenBy != tracev2.NoGoroutine
+		}
+		if seg.Goroutine != g && seg.Category == "Execution time" {
+			workerTime += seg.End.Sub(seg.Start)
+		}
+	}
+	if !woken {
+		t.Errorf("critical path does not follow the channel send to the task's goroutine:\n%+v", path)
+	}
+	if workerTime < 5*time.Millisecond {
+		t.Errorf("critical path includes %v of execution of other goroutines, want at least 5ms", workerTime)
+	}
+
+	// Check that the page renders.
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", criticalPathURL("criticalPathTask", g, span), nil)
+	CriticalPathHandlerFunc(parsed).ServeHTTP(w, r)
+	if w.Code != 200 {
+		t.Fatalf("critical path page: got status %d: %s", w.Code, w.Body)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "Execution time") {
+		t.Errorf("critical path page has no execution time:\n%s", body)
+	}
+}
@@ -107,6 +107,9 @@ func Main(traceFile, httpAddr, pprof string, debug int) error {
 	mux.HandleFunc("/usertasks", UserTasksHandlerFunc(parsed))
 	mux.HandleFunc("/usertask", UserTaskHandlerFunc(parsed))
 
+	// Critical path endpoint.
+	mux.HandleFunc("/criticalpath", CriticalPathHandlerFunc(parsed))
+
 	err = http.Serve(ln, mux)
 	return fmt.Errorf("failed to start http server: %w", err)
 }
@@ -170,6 +170,7 @@ func UserRegionHandlerFunc(t *parsedTrace) http.HandlerFunc {
 			Goroutine           tracev2.GoID
 			NonOverlappingStats map[string]time.Duration
 			HasRangeTime        bool
+			CriticalPathURL     string
 		}
 		var regions []region
 		var maxTotal time.Duration
@@ -194,6 +195,7 @@ func UserRegionHandlerFunc(t *parsedTrace) http.HandlerFunc {
 					Goroutine:           g.ID,
 					NonOverlappingStats: nonOverlappingStats,
 					HasRangeTime:        totalRangeTime != 0,
+					CriticalPathURL:     criticalPathURL(fmt.Sprintf("region %q", r.Name), g.ID, regionInterval(t, r)),
 				})
 				if maxTotal < r.TotalTime {
 					maxTotal = r.TotalTime
@@ -385,7 +387,7 @@ All of the columns except total time are non-overlapping.
 	<tr>
 		<td> <a href="/trace?goid={{.Goroutine}}">{{.Goroutine}}</a> </td>
 		<td> {{if .TaskID}}<a href="/trace?focustask={{.TaskID}}">{{.TaskID}}</a>{{end}} </td>
-		<td> {{ .TotalTime.String }} </td>
+		<td> {{ .TotalTime.String }} <a href="{{.CriticalPathURL}}">(critical path)</a> </td>
 		<td>
 			<div class="stacked-bar-graph">
 			{{$Region := .}}
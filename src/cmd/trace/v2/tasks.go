@@ -131,13 +131,14 @@ func UserTaskHandlerFunc(t *parsedTrace) http.HandlerFunc {
 			// TODO: include stack trace of creation time
 		}
 		type task struct {
-			WhenString string
-			ID         tracev2.TaskID
-			Duration   time.Duration
-			Complete   bool
-			Events     []event
-			Start, End time.Duration // Time since the beginning of the trace
-			GCTime     time.Duration
+			WhenString      string
+			ID              tracev2.TaskID
+			Duration        time.Duration
+			Complete        bool
+			Events          []event
+			Start, End      time.Duration // Time since the beginning of the trace
+			GCTime          time.Duration
+			CriticalPathURL string
 		}
 		var tasks []task
 		for _, summary := range t.summary.Tasks {
@@ -187,16 +188,21 @@ func UserTaskHandlerFunc(t *parsedTrace) http.HandlerFunc {
 			}
 			taskSpan := taskInterval(t, summary)
 			taskStart := taskSpan.start.Sub(t.startTime())
+			var criticalPath string
+			if summary.Complete() {
+				criticalPath = criticalPathURL(fmt.Sprintf("task %d (%s)", summary.ID, summary.Name), summary.End.Goroutine(), taskSpan)
+			}
 
 			// Produce the task summary.
 			tasks = append(tasks, task{
-				WhenString: fmt.Sprintf("%2.9fs", taskStart.Seconds()),
-				Duration:   taskSpan.duration(),
-				ID:         summary.ID,
-				Complete:   summary.Complete(),
-				Events:     events,
-				Start:      taskStart,
-				End:        taskStart + taskSpan.duration(),
+				WhenString:      fmt.Sprintf("%2.9fs", taskStart.Seconds()),
+				Duration:        taskSpan.duration(),
+				ID:              summary.ID,
+				Complete:        summary.Complete(),
+				Events:          events,
+				Start:           taskStart,
+				End:             taskStart + taskSpan.duration(),
+				CriticalPathURL: criticalPath,
 			})
 		}
 		// Sort the tasks by duration.
@@ -289,6 +295,7 @@ Search log text: <form onsubmit="window.location.search+='&logtext='+window.logt
 			<a href="/trace?focustask={{$el.ID}}#{{asMillisecond $el.Start}}:{{asMillisecond $el.End}}">Task {{$el.ID}}</a>
 			<a href="/trace?taskid={{$el.ID}}#{{asMillisecond $el.Start}}:{{asMillisecond $el.End}}">(goroutine view)</a>
 			({{if .Complete}}complete{{else}}incomplete{{end}})
+			{{if .CriticalPathURL}}<a href="{{.CriticalPathURL}}">(critical path)</a>{{end}}
 		</td>
 	</tr>
 	{{range $el.Events}}
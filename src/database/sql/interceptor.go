@@ -0,0 +1,185 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"errors"
+)
+
+// An Interceptor observes, and may modify, the operations a [DB]
+// performs on its driver, for example to trace or log queries or to
+// collect metrics. It is added to a DB with [DB.AddInterceptor].
+//
+// Each field is an optional hook wrapping one kind of operation. A hook
+// is passed the parameters of the operation and a next function that
+// performs it: the hook may inspect or replace the parameters, time
+// the call to next and inspect or replace the error it returns. A hook
+// must call next at most once; if it returns without calling next, the
+// operation is not performed and the hook's error, or [ErrIntercepted]
+// if it is nil, is returned to the caller. If a hook returns an error
+// after next succeeded, the result of the operation is discarded. If a
+// hook returns nil after next failed, the error of the operation is
+// still returned to the caller, as there is no result to return.
+//
+// Hooks are called with the operation's driver connection reserved,
+// and possibly locked; they must not use the [Conn], [Tx], [Stmt] or
+// [Rows] involved in the operation.
+//
+// Interceptors do not wrap the values provided by the driver, so any
+// optional interfaces the driver implements remain in use.
+type Interceptor struct {
+	// Conn wraps obtaining a connection for an operation, which may
+	// reuse an idle connection, wait for one or open a new one.
+	Conn func(ctx context.Context, next func(context.Context) error) error
+
+	// Prepare wraps preparing a statement on a connection, by the
+	// Prepare methods of DB, Conn and Tx, and when a [Stmt] needs to
+	// be prepared on another connection.
+	Prepare func(ctx context.Context, query string, next func(context.Context, string) error) error
+
+	// Exec wraps executing a statement by the Exec methods of DB,
+	// Conn, Tx and Stmt. For a Stmt, query is the query the statement
+	// was prepared with, and it cannot be changed.
+	Exec func(ctx context.Context, query string, args []any, next func(context.Context, string, []any) error) error
+
+	// Query wraps executing a query by the Query and QueryRow methods
+	// of DB, Conn, Tx and Stmt. For a Stmt, query is the query the
+	// statement was prepared with, and it cannot be changed.
+	// The hook returns when the query has been started; reading its
+	// rows is not part of the operation.
+	Query func(ctx context.Context, query string, args []any, next func(context.Context, string, []any) error) error
+
+	// Begin wraps starting a transaction. The options may be nil.
+	Begin func(ctx context.Context, opts *TxOptions, next func(context.Context, *TxOptions) error) error
+
+	// Commit and Rollback wrap ending a transaction. The context
+	// carries the values of the context the transaction was started
+	// with, but it is never canceled. If Commit or Rollback does not
+	// call next, the connection of the transaction is closed.
+	Commit   func(ctx context.Context, next func(context.Context) error) error
+	Rollback func(ctx context.Context, next func(context.Context) error) error
+}
+
+// ErrIntercepted is returned by operations that an [Interceptor]
+// hook did not perform, if the hook did not return an error.
+var ErrIntercepted = errors.New("sql: operation not performed by interceptor")
+
+// AddInterceptor adds ic to the interceptors of db. The hooks of the
+// interceptors wrap each other in the order they were added, the
+// interceptor added first being the outermost.
+//
+// AddInterceptor may be called concurrently with other methods;
+// it affects the operations started after it returns.
+func (db *DB) AddInterceptor(ic *Interceptor) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var ics []*Interceptor
+	if p := db.interceptors.Load(); p != nil {
+		ics = *p
+	}
+	ics = append(ics[:len(ics):len(ics)], ic)
+	db.interceptors.Store(&ics)
+}
+
+// loadInterceptors returns the interceptors of db, outermost first.
+func (db *DB) loadInterceptors() []*Interceptor {
+	if p := db.interceptors.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// A hook is the form of the hooks of an [Interceptor] used by
+// intercept, with the parameters of the operation in a single value.
+type hook[P any] func(ctx context.Context, p P, next func(context.Context, P) error) error
+
+// intercept calls f with p, wrapped by the hooks of the interceptors
+// of db selected by sel, and reports whether f was called. If the hooks
+// return no error, it returns ErrIntercepted if f was not called, and
+// the error of f if it failed, since the results of the operation are
+// then not available.
+func intercept[P any](ctx context.Context, db *DB, sel func(*Interceptor) hook[P], p P, f func(context.Context, P) error) (called bool, err error) {
+	var ferr error
+	next := func(ctx context.Context, p P) error {
+		called = true
+		ferr = f(ctx, p)
+		return ferr
+	}
+	ics := db.loadInterceptors()
+	for i := len(ics) - 1; i >= 0; i-- {
+		if h := sel(ics[i]); h != nil {
+			inner := next
+			next = func(ctx context.Context, p P) error { return h(ctx, p, inner) }
+		}
+	}
+	err = next(ctx, p)
+	if err == nil {
+		if !called {
+			err = ErrIntercepted
+		} else {
+			err = ferr
+		}
+	}
+	return called, err
+}
+
+// queryParams are the parameters of the Exec and Query hooks.
+type queryParams struct {
+	query string
+	args  []any
+}
+
+// Hook selectors for intercept.
+
+func (ic *Interceptor) connHook() hook[struct{}] {
+	return ctxHook(ic.Conn)
+}
+
+func (ic *Interceptor) prepareHook() hook[string] {
+	return hook[string](ic.Prepare)
+}
+
+func (ic *Interceptor) execHook() hook[queryParams] {
+	return queryHook(ic.Exec)
+}
+
+func (ic *Interceptor) queryHook() hook[queryParams] {
+	return queryHook(ic.Query)
+}
+
+func (ic *Interceptor) beginHook() hook[*TxOptions] {
+	return hook[*TxOptions](ic.Begin)
+}
+
+func (ic *Interceptor) commitHook() hook[struct{}] {
+	return ctxHook(ic.Commit)
+}
+
+func (ic *Interceptor) rollbackHook() hook[struct{}] {
+	return ctxHook(ic.Rollback)
+}
+
+func ctxHook(h func(context.Context, func(context.Context) error) error) hook[struct{}] {
+	if h == nil {
+		return nil
+	}
+	return func(ctx context.Context, _ struct{}, next func(context.Context, struct{}) error) error {
+		return h(ctx, func(ctx context.Context) error {
+			return next(ctx, struct{}{})
+		})
+	}
+}
+
+func queryHook(h func(context.Context, string, []any, func(context.Context, string, []any) error) error) hook[queryParams] {
+	if h == nil {
+		return nil
+	}
+	return func(ctx context.Context, p queryParams, next func(context.Context, queryParams) error) error {
+		return h(ctx, p.query, p.args, func(ctx context.Context, query string, args []any) error {
+			return next(ctx, queryParams{query, args})
+		})
+	}
+}
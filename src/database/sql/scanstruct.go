@@ -0,0 +1,147 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"internal/structfield"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+var scannerReflectType = reflect.TypeFor[Scanner]()
+
+// structFields holds the fields of struct types that columns can be
+// scanned into, named by their db struct tags. Embedded structs
+// implementing Scanner are scanned into as a whole.
+var structFields = structfield.Cache{
+	Tag: "db",
+	Opaque: func(t reflect.Type) bool {
+		return reflect.PointerTo(t).Implements(scannerReflectType)
+	},
+}
+
+// structColumns maps the columns of a result set to struct fields.
+type structColumns struct {
+	typ    reflect.Type
+	fields [][]int // field index for each column
+}
+
+func newStructColumns(t reflect.Type, columns []string) (*structColumns, error) {
+	fields := structFields.Fields(t)
+	c := &structColumns{typ: t, fields: make([][]int, len(columns))}
+	var missing []string
+	for i, name := range columns {
+		j := slices.IndexFunc(fields, func(f structfield.Field) bool { return f.Name == name })
+		if j < 0 {
+			j = slices.IndexFunc(fields, func(f structfield.Field) bool { return strings.EqualFold(f.Name, name) })
+		}
+		if j < 0 {
+			missing = append(missing, fmt.Sprintf("%q", name))
+			continue
+		}
+		c.fields[i] = fields[j].Index
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("sql: no field of %v matches column %s", t, strings.Join(missing, ", "))
+	}
+	return c, nil
+}
+
+// scansAsStruct reports whether a column is scanned into values of
+// type t field by field, rather than as a whole.
+func scansAsStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeFor[time.Time]() &&
+		!reflect.PointerTo(t).Implements(scannerReflectType)
+}
+
+var errScanStructDest = errors.New("sql: ScanStruct destination must be a non-nil pointer to a struct")
+
+// ScanStruct copies the columns in the current row into the fields of
+// the struct pointed to by dest.
+//
+// Each column is stored in the struct field with the same name, given
+// by the field's db struct tag or, without a tag, by the field's name.
+// Exact matches are preferred, but case-insensitive matches are
+// accepted. Fields tagged "-" and unexported fields are ignored, and
+// the fields of embedded structs are treated as fields of dest, unless
+// the embedded struct implements [Scanner]. ScanStruct returns an error
+// if a column does not match any field; fields not matching any column
+// are left unchanged.
+//
+// The columns are converted to the types of the fields as described
+// for [Rows.Scan].
+func (rs *Rows) ScanStruct(dest any) error {
+	args, err := rs.structDest(dest)
+	if err != nil {
+		return err
+	}
+	return rs.Scan(args...)
+}
+
+// structDest returns the addresses of the fields of the struct pointed
+// to by dest that the columns of rs are scanned into.
+func (rs *Rows) structDest(dest any) ([]any, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, errScanStructDest
+	}
+	v = v.Elem()
+	if rs.structColumns == nil || rs.structColumns.typ != v.Type() {
+		columns, err := rs.Columns()
+		if err != nil {
+			return nil, err
+		}
+		c, err := newStructColumns(v.Type(), columns)
+		if err != nil {
+			return nil, err
+		}
+		rs.structColumns = c
+	}
+	args := make([]any, len(rs.structColumns.fields))
+	for i, index := range rs.structColumns.fields {
+		fv, err := structfield.ByIndex(v, index)
+		if err != nil {
+			return nil, fmt.Errorf("sql: %w", err)
+		}
+		args[i] = fv.Addr().Interface()
+	}
+	return args, nil
+}
+
+// ScanStruct copies the columns from the matched row into the fields of
+// the struct pointed to by dest, as described for [Rows.ScanStruct].
+// If more than one row matches the query, ScanStruct uses the first row
+// and discards the rest. If no row matches the query, ScanStruct returns
+// [ErrNoRows].
+func (r *Row) ScanStruct(dest any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return ErrNoRows
+	}
+	args, err := r.rows.structDest(dest)
+	if err != nil {
+		return err
+	}
+	// See Row.Scan.
+	if scanArgsContainRawBytes(args) {
+		return errors.New("sql: RawBytes isn't allowed on Row.ScanStruct")
+	}
+	if err := r.rows.Scan(args...); err != nil {
+		return err
+	}
+	// Make sure the query can be processed to completion with no errors.
+	return r.rows.Close()
+}
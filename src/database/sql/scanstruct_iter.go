@@ -0,0 +1,51 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.rangefunc
+
+package sql
+
+import (
+	"iter"
+	"reflect"
+)
+
+// ScanRows returns an iterator over the remaining rows of rows, each
+// scanned into a value of type T. If T is a struct type, the columns
+// are scanned into its fields as by [Rows.ScanStruct], unless T is
+// [time.Time] or *T implements [Scanner]; otherwise the result set must
+// have a single column, which is scanned into the value as by
+// [Rows.Scan].
+//
+// If a row cannot be scanned, or if an error occurs while iterating,
+// the error is yielded with the zero value of T and iteration stops.
+// Rows is closed when iteration stops, including when the loop body
+// breaks out of the loop early.
+func ScanRows[T any](rows *Rows) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer rows.Close()
+		isStruct := scansAsStruct(reflect.TypeFor[T]())
+		for rows.Next() {
+			var v T
+			var err error
+			if isStruct {
+				err = rows.ScanStruct(&v)
+			} else {
+				err = rows.Scan(&v)
+			}
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
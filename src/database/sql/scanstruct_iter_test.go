@@ -0,0 +1,122 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.rangefunc
+
+package sql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScanRows(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	rows, err := db.Query("SELECT|people|name,age|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []person
+	for p, err := range ScanRows[person](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, p)
+	}
+	want := []person{
+		{personName: personName{"Alice"}, Age: 1},
+		{personName: personName{"Bob"}, Age: 2},
+		{personName: personName{"Chris"}, Age: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanRows:\n got: %+v\nwant: %+v", got, want)
+	}
+
+	// Breaking out of the loop closes the rows.
+	rows, err = db.Query("SELECT|people|name|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name, err := range ScanRows[string](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+		break
+	}
+	if !reflect.DeepEqual(names, []string{"Alice"}) {
+		t.Errorf("ScanRows[string] = %q, want [Alice]", names)
+	}
+	if err := rows.Scan(new(string)); err != errRowsClosed {
+		t.Errorf("Scan after break: got error %v, want %v", err, errRowsClosed)
+	}
+
+	// Struct types that are scanned as a whole.
+	rows, err = db.Query("SELECT|people|name|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nulls []NullString
+	for name, err := range ScanRows[NullString](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		nulls = append(nulls, name)
+	}
+	if len(nulls) != 3 || nulls[0] != (NullString{"Alice", true}) {
+		t.Errorf("ScanRows[NullString] = %v, want 3 names starting with Alice", nulls)
+	}
+	rows, err = db.Query("SELECT|people|bdate|age=?", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dates []time.Time
+	for d, err := range ScanRows[time.Time](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		dates = append(dates, d)
+	}
+	if len(dates) != 1 || !dates[0].Equal(chrisBirthday) {
+		t.Errorf("ScanRows[time.Time] = %v, want [%v]", dates, chrisBirthday)
+	}
+	rows, err = db.Query("SELECT|people|bdate|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nullDates []NullTime
+	for d, err := range ScanRows[NullTime](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		nullDates = append(nullDates, d)
+	}
+	wantDates := []NullTime{{}, {}, {chrisBirthday, true}}
+	if !reflect.DeepEqual(nullDates, wantDates) {
+		t.Errorf("ScanRows[NullTime] = %v, want %v", nullDates, wantDates)
+	}
+
+	// A scan error is yielded and stops the iteration.
+	rows, err = db.Query("SELECT|people|name,bdate|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, err := range ScanRows[personName](rows) {
+		n++
+		if err == nil {
+			t.Error("ScanRows[personName] succeeded with unmatched column")
+		}
+	}
+	if n != 1 {
+		t.Errorf("ScanRows[personName] yielded %d values, want 1", n)
+	}
+	if numOpen := db.numOpenConns(); numOpen != 1 {
+		t.Errorf("%d open connections, want 1", numOpen)
+	}
+}
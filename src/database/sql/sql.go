@@ -520,6 +520,8 @@ type DB struct {
 	maxLifetimeClosed int64 // Total number of connections closed due to max connection lifetime limit.
 
 	stop func() // stop cancels the connection opener.
+
+	interceptors atomic.Pointer[[]*Interceptor] // see AddInterceptor
 }
 
 // connReuseStrategy determines how (*DB).conn returns database connections.
@@ -606,7 +608,7 @@ func (dc *driverConn) validateConnection(needsReset bool) bool {
 // prepareLocked prepares the query on dc. When cg == nil the dc must keep track of
 // the prepared statements in a pool.
 func (dc *driverConn) prepareLocked(ctx context.Context, cg stmtConnGrabber, query string) (*driverStmt, error) {
-	si, err := ctxDriverPrepare(ctx, dc.ci, query)
+	si, err := dc.driverPrepareLocked(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -628,6 +630,25 @@ func (dc *driverConn) prepareLocked(ctx context.Context, cg stmtConnGrabber, que
 	return ds, nil
 }
 
+// driverPrepareLocked prepares the query on dc,
+// as wrapped by the Prepare hooks of dc.db's interceptors.
+func (dc *driverConn) driverPrepareLocked(ctx context.Context, query string) (si driver.Stmt, err error) {
+	if dc.db.loadInterceptors() == nil {
+		return ctxDriverPrepare(ctx, dc.ci, query)
+	}
+	_, err = intercept(ctx, dc.db, (*Interceptor).prepareHook, query, func(ctx context.Context, query string) error {
+		si, err = ctxDriverPrepare(ctx, dc.ci, query)
+		return err
+	})
+	if err != nil {
+		if si != nil {
+			si.Close()
+		}
+		return nil, err
+	}
+	return si, nil
+}
+
 // the dc.db's Mutex is held.
 func (dc *driverConn) closeDBLocked() func() error {
 	dc.Lock()
@@ -1305,8 +1326,27 @@ func (db *DB) nextRequestKeyLocked() uint64 {
 	return next
 }
 
-// conn returns a newly-opened or cached *driverConn.
-func (db *DB) conn(ctx context.Context, strategy connReuseStrategy) (*driverConn, error) {
+// conn returns a newly-opened or cached *driverConn,
+// as wrapped by the Conn hooks of db's interceptors.
+func (db *DB) conn(ctx context.Context, strategy connReuseStrategy) (dc *driverConn, err error) {
+	if db.loadInterceptors() == nil {
+		return db.acquireConn(ctx, strategy)
+	}
+	called, err := intercept(ctx, db, (*Interceptor).connHook, struct{}{}, func(ctx context.Context, _ struct{}) error {
+		dc, err = db.acquireConn(ctx, strategy)
+		return err
+	})
+	if called && err != nil && dc != nil {
+		db.putConn(dc, nil, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// acquireConn is like conn, without interceptors.
+func (db *DB) acquireConn(ctx context.Context, strategy connReuseStrategy) (*driverConn, error) {
 	db.mu.Lock()
 	if db.closed {
 		db.mu.Unlock()
@@ -1687,6 +1727,21 @@ func (db *DB) execDC(ctx context.Context, dc *driverConn, release func(error), q
 	defer func() {
 		release(err)
 	}()
+	if db.loadInterceptors() == nil {
+		return execConn(ctx, dc, query, args)
+	}
+	_, err = intercept(ctx, db, (*Interceptor).execHook, queryParams{query, args}, func(ctx context.Context, p queryParams) error {
+		res, err = execConn(ctx, dc, p.query, p.args)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// execConn executes a query on the given connection.
+func execConn(ctx context.Context, dc *driverConn, query string, args []any) (res Result, err error) {
 	execerCtx, ok := dc.ci.(driver.ExecerContext)
 	var execer driver.Execer
 	if !ok {
@@ -1758,7 +1813,29 @@ func (db *DB) query(ctx context.Context, query string, args []any, strategy conn
 // The connection gets released by the releaseConn function.
 // The ctx context is from a query method and the txctx context is from an
 // optional transaction context.
-func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn func(error), query string, args []any) (*Rows, error) {
+func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn func(error), query string, args []any) (rows *Rows, err error) {
+	if db.loadInterceptors() == nil {
+		return queryConn(ctx, txctx, dc, releaseConn, query, args)
+	}
+	called, err := intercept(ctx, db, (*Interceptor).queryHook, queryParams{query, args}, func(ctx context.Context, p queryParams) error {
+		rows, err = queryConn(ctx, txctx, dc, releaseConn, p.query, p.args)
+		return err
+	})
+	if !called {
+		releaseConn(err)
+		return nil, err
+	}
+	if err != nil {
+		if rows != nil {
+			rows.Close()
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
+// queryConn is like queryDC, without interceptors.
+func queryConn(ctx, txctx context.Context, dc *driverConn, releaseConn func(error), query string, args []any) (*Rows, error) {
 	queryerCtx, ok := dc.ci.(driver.QueryerContext)
 	var queryer driver.Queryer
 	if !ok {
@@ -1889,12 +1966,25 @@ func (db *DB) begin(ctx context.Context, opts *TxOptions, strategy connReuseStra
 func (db *DB) beginDC(ctx context.Context, dc *driverConn, release func(error), opts *TxOptions) (tx *Tx, err error) {
 	var txi driver.Tx
 	keepConnOnRollback := false
-	withLock(dc, func() {
-		_, hasSessionResetter := dc.ci.(driver.SessionResetter)
-		_, hasConnectionValidator := dc.ci.(driver.Validator)
-		keepConnOnRollback = hasSessionResetter && hasConnectionValidator
-		txi, err = ctxDriverBegin(ctx, opts, dc.ci)
-	})
+	begin := func(ctx context.Context, opts *TxOptions) error {
+		withLock(dc, func() {
+			_, hasSessionResetter := dc.ci.(driver.SessionResetter)
+			_, hasConnectionValidator := dc.ci.(driver.Validator)
+			keepConnOnRollback = hasSessionResetter && hasConnectionValidator
+			txi, err = ctxDriverBegin(ctx, opts, dc.ci)
+		})
+		return err
+	}
+	if db.loadInterceptors() == nil {
+		err = begin(ctx, opts)
+	} else {
+		_, err = intercept(ctx, db, (*Interceptor).beginHook, opts, begin)
+		if err != nil && txi != nil {
+			withLock(dc, func() {
+				txi.Rollback()
+			})
+		}
+	}
 	if err != nil {
 		release(err)
 		return nil, err
@@ -2298,17 +2388,35 @@ func (tx *Tx) Commit() error {
 	tx.closemu.Lock()
 	tx.closemu.Unlock()
 
-	var err error
-	withLock(tx.dc, func() {
-		err = tx.txi.Commit()
-	})
+	called, err := tx.end((*Interceptor).commitHook, tx.txi.Commit)
 	if !errors.Is(err, driver.ErrBadConn) {
 		tx.closePrepared()
 	}
+	if !called {
+		// The transaction is still open: discard the connection.
+		tx.close(driver.ErrBadConn)
+		return err
+	}
 	tx.close(err)
 	return err
 }
 
+// end ends the transaction with f, which is tx.txi.Commit or
+// tx.txi.Rollback, as wrapped by the hooks of tx.db's interceptors
+// selected by sel. It reports whether f was called.
+func (tx *Tx) end(sel func(*Interceptor) hook[struct{}], f func() error) (called bool, err error) {
+	call := func(context.Context, struct{}) error {
+		withLock(tx.dc, func() {
+			err = f()
+		})
+		return err
+	}
+	if tx.db.loadInterceptors() == nil {
+		return true, call(tx.ctx, struct{}{})
+	}
+	return intercept(context.WithoutCancel(tx.ctx), tx.db, sel, struct{}{}, call)
+}
+
 var rollbackHook func()
 
 // rollback aborts the transaction and optionally forces the pool to discard
@@ -2330,16 +2438,18 @@ func (tx *Tx) rollback(discardConn bool) error {
 	tx.closemu.Lock()
 	tx.closemu.Unlock()
 
-	var err error
-	withLock(tx.dc, func() {
-		err = tx.txi.Rollback()
-	})
+	called, err := tx.end((*Interceptor).rollbackHook, tx.txi.Rollback)
 	if !errors.Is(err, driver.ErrBadConn) {
 		tx.closePrepared()
 	}
 	if discardConn {
 		err = driver.ErrBadConn
 	}
+	if !called {
+		// The transaction is still open: discard the connection.
+		tx.close(driver.ErrBadConn)
+		return err
+	}
 	tx.close(err)
 	return err
 }
@@ -2426,7 +2536,7 @@ func (tx *Tx) StmtContext(ctx context.Context, stmt *Stmt) *Stmt {
 		// code-complexity for this.
 		stmt.mu.Unlock()
 		withLock(dc, func() {
-			si, err = ctxDriverPrepare(ctx, dc.ci, stmt.query)
+			si, err = dc.driverPrepareLocked(ctx, stmt.query)
 		})
 		if err != nil {
 			return &Stmt{stickyErr: err}
@@ -2641,7 +2751,17 @@ func (s *Stmt) ExecContext(ctx context.Context, args ...any) (Result, error) {
 			return err
 		}
 
-		res, err = resultFromStatement(ctx, dc.ci, ds, args...)
+		if s.db.loadInterceptors() == nil {
+			res, err = resultFromStatement(ctx, dc.ci, ds, args...)
+		} else {
+			_, err = intercept(ctx, s.db, (*Interceptor).execHook, queryParams{s.query, args}, func(ctx context.Context, p queryParams) error {
+				res, err = resultFromStatement(ctx, dc.ci, ds, p.args...)
+				return err
+			})
+			if err != nil {
+				res = nil
+			}
+		}
 		releaseConn(err)
 		return err
 	})
@@ -2783,7 +2903,20 @@ func (s *Stmt) QueryContext(ctx context.Context, args ...any) (*Rows, error) {
 			return err
 		}
 
-		rowsi, err = rowsiFromStatement(ctx, dc.ci, ds, args...)
+		if s.db.loadInterceptors() == nil {
+			rowsi, err = rowsiFromStatement(ctx, dc.ci, ds, args...)
+		} else {
+			rowsi = nil
+			_, err = intercept(ctx, s.db, (*Interceptor).queryHook, queryParams{s.query, args}, func(ctx context.Context, p queryParams) error {
+				rowsi, err = rowsiFromStatement(ctx, dc.ci, ds, p.args...)
+				return err
+			})
+			if err != nil && rowsi != nil {
+				withLock(dc, func() {
+					rowsi.Close()
+				})
+			}
+		}
 		if err == nil {
 			// Note: ownership of ci passes to the *Rows, to be freed
 			// with releaseConn.
@@ -2950,6 +3083,11 @@ type Rows struct {
 	// returning. It's only used by Next and Err which are
 	// expected not to be called concurrently.
 	hitEOF bool
+
+	// structColumns maps the columns of the current result set to the
+	// fields of the struct last passed to ScanStruct. Like lastcols,
+	// it is only used by methods not called concurrently.
+	structColumns *structColumns
 }
 
 // lasterrOrErrLocked returns either lasterr or the provided err.
@@ -3093,6 +3231,7 @@ func (rs *Rows) NextResultSet() bool {
 	}
 
 	rs.lastcols = nil
+	rs.structColumns = nil
 	nextResultSet, ok := rs.rowsi.(driver.RowsNextResultSet)
 	if !ok {
 		doClose = true
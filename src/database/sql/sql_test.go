@@ -14,6 +14,7 @@ import (
 	"math/rand"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -4803,3 +4804,336 @@ func BenchmarkGrabConn(b *testing.B) {
 		release(nil)
 	}
 }
+
+func TestInterceptor(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	var events []string
+	record := func(name, query string, err error) {
+		ev := name
+		if query != "" {
+			ev += " " + query
+		}
+		if err != nil {
+			ev += ": " + err.Error()
+		}
+		events = append(events, ev)
+	}
+	db.AddInterceptor(&Interceptor{
+		Conn: func(ctx context.Context, next func(context.Context) error) error {
+			err := next(ctx)
+			record("conn", "", err)
+			return err
+		},
+		Prepare: func(ctx context.Context, query string, next func(context.Context, string) error) error {
+			err := next(ctx, query)
+			record("prepare", query, err)
+			return err
+		},
+		Exec: func(ctx context.Context, query string, args []any, next func(context.Context, string, []any) error) error {
+			err := next(ctx, query, args)
+			record("exec", query, err)
+			return err
+		},
+		Query: func(ctx context.Context, query string, args []any, next func(context.Context, string, []any) error) error {
+			err := next(ctx, query, args)
+			record("query", query, err)
+			return err
+		},
+		Begin: func(ctx context.Context, opts *TxOptions, next func(context.Context, *TxOptions) error) error {
+			err := next(ctx, opts)
+			record("begin", "", err)
+			return err
+		},
+		Commit: func(ctx context.Context, next func(context.Context) error) error {
+			if ctx.Err() != nil {
+				t.Errorf("Commit hook context is done: %v", ctx.Err())
+			}
+			err := next(ctx)
+			record("commit", "", err)
+			return err
+		},
+		Rollback: func(ctx context.Context, next func(context.Context) error) error {
+			err := next(ctx)
+			record("rollback", "", err)
+			return err
+		},
+	})
+
+	const sel = "SELECT|people|name|age=?"
+	var name string
+	if err := db.QueryRow(sel, 2).Scan(&name); err != nil || name != "Bob" {
+		t.Fatalf("QueryRow = %q, %v; want Bob", name, err)
+	}
+	if _, err := db.Exec("INSERT|people|name=Dave,age=?", 4); err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := db.Prepare(sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.QueryRow(1).Scan(&name); err != nil || name != "Alice" {
+		t.Fatalf("Stmt.QueryRow = %q, %v; want Alice", name, err)
+	}
+	stmt.Close()
+	if _, err := db.Query("SELECT|nosuchtable|name|"); err == nil {
+		t.Fatal("Query of unknown table succeeded")
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"conn",
+		"query " + sel,
+		"conn",
+		"exec INSERT|people|name=Dave,age=?",
+		"conn",
+		"prepare " + sel,
+		"conn",
+		"query " + sel,
+		"conn",
+		"query SELECT|nosuchtable|name|: fakedb: table \"nosuchtable\" doesn't exist",
+		"conn",
+		"begin",
+		"commit",
+		"conn",
+		"begin",
+		"rollback",
+	}
+	if !slices.Equal(events, want) {
+		t.Errorf("events:\n%s\nwant:\n%s", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestInterceptorModify(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	var order []string
+	errDenied := errors.New("denied")
+	db.AddInterceptor(&Interceptor{
+		Query: func(ctx context.Context, query string, args []any, next func(context.Context, string, []any) error) error {
+			order = append(order, "outer")
+			if query == "DENIED" {
+				return errDenied
+			}
+			return next(ctx, query, args)
+		},
+		Exec: func(ctx context.Context, query string, args []any, next func(context.Context, string, []any) error) error {
+			return nil // skip
+		},
+	})
+	db.AddInterceptor(&Interceptor{
+		Query: func(ctx context.Context, query string, args []any, next func(context.Context, string, []any) error) error {
+			order = append(order, "inner")
+			if query == "SELECT|people|age|age=?" {
+				query, args = "SELECT|people|age|name=?", []any{"Chris"}
+			}
+			return next(ctx, query, args)
+		},
+	})
+
+	var age int
+	if err := db.QueryRow("SELECT|people|age|age=?", 1).Scan(&age); err != nil || age != 3 {
+		t.Errorf("QueryRow = %d, %v; want 3", age, err)
+	}
+	if want := []string{"outer", "inner"}; !slices.Equal(order, want) {
+		t.Errorf("hooks called in order %q, want %q", order, want)
+	}
+	if _, err := db.Query("DENIED"); err != errDenied {
+		t.Errorf("Query: got error %v, want %v", err, errDenied)
+	}
+	if _, err := db.Exec("INSERT|people|name=Dave,age=?", 4); err != ErrIntercepted {
+		t.Errorf("Exec: got error %v, want %v", err, ErrIntercepted)
+	}
+	if err := db.QueryRow("SELECT|people|age|name=?", "Dave").Scan(&age); err != ErrNoRows {
+		t.Errorf("skipped Exec was performed: QueryRow error = %v, want ErrNoRows", err)
+	}
+
+	// No connection is leaked by the skipped or failed operations.
+	if n := db.numOpenConns(); n != 1 {
+		t.Errorf("%d open connections, want 1", n)
+	}
+	if n := db.numFreeConns(); n != 1 {
+		t.Errorf("%d free connections, want 1", n)
+	}
+}
+
+func TestInterceptorSkipCommit(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	db.AddInterceptor(&Interceptor{
+		Commit: func(ctx context.Context, next func(context.Context) error) error {
+			return nil
+		},
+	})
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != ErrIntercepted {
+		t.Errorf("Commit: got error %v, want %v", err, ErrIntercepted)
+	}
+	// The connection with the transaction still open is discarded.
+	if n := db.numOpenConns(); n != 0 {
+		t.Errorf("%d open connections, want 0", n)
+	}
+	if err := tx.Rollback(); err != ErrTxDone {
+		t.Errorf("Rollback: got error %v, want %v", err, ErrTxDone)
+	}
+}
+
+func TestInterceptorIgnoreError(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	db.AddInterceptor(&Interceptor{
+		Prepare: func(ctx context.Context, query string, next func(context.Context, string) error) error {
+			next(ctx, query)
+			return nil
+		},
+		Exec: func(ctx context.Context, query string, args []any, next func(context.Context, string, []any) error) error {
+			next(ctx, query, args)
+			return nil
+		},
+		Query: func(ctx context.Context, query string, args []any, next func(context.Context, string, []any) error) error {
+			next(ctx, query, args)
+			return nil
+		},
+		Begin: func(ctx context.Context, opts *TxOptions, next func(context.Context, *TxOptions) error) error {
+			next(ctx, opts)
+			return nil
+		},
+	})
+
+	// The errors of the operations are returned even though the hooks
+	// ignore them, as there is no result to return.
+	if res, err := db.Exec("INSERT|nosuchtable|name=Dave"); err == nil {
+		t.Errorf("Exec = %v, nil; want error", res)
+	}
+	if rows, err := db.Query("SELECT|nosuchtable|name|"); err == nil {
+		t.Errorf("Query = %v, nil; want error", rows)
+	}
+	if stmt, err := db.Prepare("NOSUCHCMD|people|name|"); err == nil {
+		t.Errorf("Prepare = %v, nil; want error", stmt)
+	}
+	if tx, err := db.BeginTx(context.Background(), &TxOptions{ReadOnly: true}); err == nil {
+		t.Errorf("BeginTx = %v, nil; want error", tx)
+	}
+	stmt, err := db.Prepare("SELECT|people|name|age=?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	if rows, err := stmt.Query(); err == nil {
+		t.Errorf("Stmt.Query = %v, nil; want error", rows)
+	}
+	if res, err := stmt.Exec(); err == nil {
+		t.Errorf("Stmt.Exec = %v, nil; want error", res)
+	}
+	if n := db.numOpenConns(); n != 1 {
+		t.Errorf("%d open connections, want 1", n)
+	}
+}
+
+type personName struct {
+	Name string
+}
+
+type person struct {
+	personName
+	Age     int    `db:"age"`
+	Photo   []byte `db:"photo"`
+	Ignored string `db:"-"`
+	Dead    NullBool
+}
+
+func TestRowsScanStruct(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	rows, err := db.Query("SELECT|people|age,name,photo,dead|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []person
+	for rows.Next() {
+		p := person{Ignored: "x"}
+		if err := rows.ScanStruct(&p); err != nil {
+			t.Fatalf("ScanStruct: %v", err)
+		}
+		got = append(got, p)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []person{
+		{personName{"Alice"}, 1, []byte("APHOTO"), "x", NullBool{}},
+		{personName{"Bob"}, 2, []byte("BPHOTO"), "x", NullBool{}},
+		{personName{"Chris"}, 3, []byte("CPHOTO"), "x", NullBool{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanStruct:\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestRowsScanStructErrors(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	tests := []struct {
+		query string
+		dest  any
+		err   string
+	}{
+		{"SELECT|people|name,age|", person{}, "sql: ScanStruct destination must be a non-nil pointer to a struct"},
+		{"SELECT|people|name,age|", new(int), "sql: ScanStruct destination must be a non-nil pointer to a struct"},
+		{"SELECT|people|name,bdate,age|", &personName{}, `sql: no field of sql.personName matches column "bdate", "age"`},
+		{"SELECT|people|name|", &struct{ Name int }{}, `sql: Scan error on column index 0, name "name": converting driver.Value type []uint8 ("Alice") to a int: invalid syntax`},
+	}
+	for _, tt := range tests {
+		err := db.QueryRow(tt.query).ScanStruct(tt.dest)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: ScanStruct(%T) error = %v, want %s", tt.query, tt.dest, err, tt.err)
+		}
+	}
+	if n := db.numOpenConns(); n != 1 {
+		t.Errorf("%d open connections, want 1", n)
+	}
+}
+
+func TestRowScanStruct(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	var p struct {
+		Name string
+		Age  *int64 `db:"AGE"`
+	}
+	if err := db.QueryRow("SELECT|people|name,age|name=?", "Bob").ScanStruct(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Bob" || p.Age == nil || *p.Age != 2 {
+		t.Errorf("ScanStruct = %+v, want Bob, 2", p)
+	}
+	if err := db.QueryRow("SELECT|people|name|name=?", "Dave").ScanStruct(&p); err != ErrNoRows {
+		t.Errorf("ScanStruct of no rows: got error %v, want ErrNoRows", err)
+	}
+	var raw struct{ Photo RawBytes }
+	if err := db.QueryRow("SELECT|people|photo|name=?", "Bob").ScanStruct(&raw); err == nil {
+		t.Error("ScanStruct into RawBytes succeeded")
+	}
+}
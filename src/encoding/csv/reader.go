@@ -174,6 +174,13 @@ type Reader struct {
 
 	// lastRecord is a record cache and only used when ReuseRecord == true.
 	lastRecord []string
+
+	// header is the header record read by ReadStruct.
+	header []string
+
+	// columns maps the header columns to the fields of
+	// the struct type last passed to ReadStruct.
+	columns *structColumns
 }
 
 // NewReader returns a new Reader that reads from r.
@@ -0,0 +1,290 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"internal/structfield"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A FieldError describes a CSV field that could not be converted
+// to or from the struct field it is mapped to.
+// Line and column numbers are 1-indexed; they are zero for errors
+// reported by [Writer.WriteStruct].
+type FieldError struct {
+	Line   int          // Line where the field starts
+	Column int          // Column (1-based byte index) where the field starts
+	Header string       // Name of the column in the header record
+	Field  string       // Name of the struct field
+	Type   reflect.Type // Type of the struct field
+	Err    error        // The actual error
+}
+
+func (e *FieldError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("csv: column %q: cannot convert field %s of type %v: %v", e.Header, e.Field, e.Type, e.Err)
+	}
+	return fmt.Sprintf("csv: line %d, column %d: cannot convert %q value into field %s of type %v: %v", e.Line, e.Column, e.Header, e.Field, e.Type, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// structFields holds the fields of struct types that map to CSV
+// columns, named by their csv struct tags.
+var structFields = structfield.Cache{Tag: "csv"}
+
+// structColumns maps the columns of a header record to struct fields.
+type structColumns struct {
+	typ    reflect.Type
+	fields []*structfield.Field // indexed by column; nil if the column is not mapped
+}
+
+func newStructColumns(t reflect.Type, header []string) *structColumns {
+	fields := structFields.Fields(t)
+	c := &structColumns{typ: t, fields: make([]*structfield.Field, len(header))}
+	for i, name := range header {
+		for j := range fields {
+			if fields[j].Name == name {
+				c.fields[i] = &fields[j]
+				break
+			}
+		}
+		if c.fields[i] != nil {
+			continue
+		}
+		for j := range fields {
+			if strings.EqualFold(fields[j].Name, name) {
+				c.fields[i] = &fields[j]
+				break
+			}
+		}
+	}
+	return c
+}
+
+var errNotStructPointer = errors.New("csv: argument must be a non-nil pointer to a struct")
+
+// ReadStruct reads one record from r and stores its fields in the
+// struct pointed to by v.
+//
+// On the first call, ReadStruct reads the header record and uses it to
+// name the columns of all following records. The header record is
+// checked against [Reader.FieldsPerRecord] like any other record; if it
+// has the wrong number of fields, ReadStruct returns the [ErrFieldCount]
+// error but still uses it as the header. Each column is stored in
+// the struct field with the same name, given by the field's csv struct
+// tag or, without a tag, by the field's name. Exact matches are
+// preferred, but case-insensitive matches are accepted. Columns with
+// no matching field are ignored, and fields with no matching column
+// are left unchanged.
+//
+// A field of a type implementing [encoding.TextUnmarshaler] is set
+// with its UnmarshalText method. Other fields must be strings, booleans,
+// integers or floating-point numbers, which are parsed with the [strconv]
+// package, or pointers to such types. An empty CSV field sets a pointer
+// to nil.
+//
+// If some fields of the record cannot be converted, ReadStruct stores
+// the others and returns a [FieldError] for each failure, combined with
+// [errors.Join]. If there is no data left to be read, ReadStruct returns
+// [io.EOF].
+func (r *Reader) ReadStruct(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errNotStructPointer
+	}
+	if r.header == nil {
+		header, err := r.readRecord(nil)
+		if err == nil || errors.Is(err, ErrFieldCount) {
+			r.header = header
+		}
+		if err != nil {
+			return err
+		}
+	}
+	rv = rv.Elem()
+	if r.columns == nil || r.columns.typ != rv.Type() {
+		r.columns = newStructColumns(rv.Type(), r.header)
+	}
+
+	record, err := r.Read()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, s := range record {
+		if i >= len(r.columns.fields) || r.columns.fields[i] == nil {
+			continue
+		}
+		f := r.columns.fields[i]
+		fv, err := structfield.ByIndex(rv, f.Index)
+		if err == nil {
+			err = setField(fv, s)
+		}
+		if err != nil {
+			line, col := r.FieldPos(i)
+			errs = append(errs, &FieldError{
+				Line:   line,
+				Column: col,
+				Header: r.header[i],
+				Field:  f.Field,
+				Type:   f.Type,
+				Err:    err,
+			})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// setField stores the CSV field s in v.
+func setField(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if s == "" {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().Implements(textUnmarshalerType) {
+			return v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+// formatField returns the CSV field for v.
+func formatField(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		if v.Type().Implements(textMarshalerType) {
+			b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+			return string(b), err
+		}
+		v = v.Elem()
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		b, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("unsupported type %v", v.Type())
+}
+
+// WriteStruct writes the fields of the struct v, or of the struct
+// pointed to by v, as a single CSV record to w.
+//
+// The struct fields map to columns as described for [Reader.ReadStruct].
+// On the first call, WriteStruct writes a header record with the column
+// names, in struct field order. Fields of types implementing
+// [encoding.TextMarshaler] are formatted with their MarshalText method,
+// other fields with the [strconv] package. A nil pointer is written as
+// an empty field.
+//
+// If some fields cannot be converted, WriteStruct writes nothing, not
+// even the header record, and returns a [FieldError] for each failure, combined with [errors.Join].
+// Writes are buffered, so [Writer.Flush] must eventually be called.
+func (w *Writer) WriteStruct(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return errors.New("csv: argument must be a struct or a non-nil pointer to a struct")
+	}
+	fields := structFields.Fields(rv.Type())
+	w.record = w.record[:0]
+	var errs []error
+	for _, f := range fields {
+		fv, err := rv.FieldByIndexErr(f.Index)
+		if err != nil {
+			// A nil embedded struct pointer: the field is empty.
+			w.record = append(w.record, "")
+			continue
+		}
+		s, err := formatField(fv)
+		if err != nil {
+			errs = append(errs, &FieldError{Header: f.Name, Field: f.Field, Type: f.Type, Err: err})
+		}
+		w.record = append(w.record, s)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if !w.wroteHeader {
+		header := make([]string, len(fields))
+		for i, f := range fields {
+			header[i] = f.Name
+		}
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+	return w.Write(w.record)
+}
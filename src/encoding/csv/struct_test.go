@@ -0,0 +1,240 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"errors"
+	"io"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type Base struct {
+	ID   int `csv:"id"`
+	Note string
+}
+
+type Host struct {
+	Base
+	Name    string     `csv:"name"`
+	Addr    netip.Addr `csv:"addr"`
+	Port    *uint16    `csv:"port"`
+	Weight  float64    `csv:"weight"`
+	Enabled bool       `csv:"enabled"`
+	Skipped string     `csv:"-"`
+	private string
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestReadStruct(t *testing.T) {
+	in := `NAME,id,addr,port,extra,weight,enabled
+a,1,10.0.0.1,80,x,0.5,true
+b,2,::1,,y,1e3,false
+`
+	want := []Host{
+		{Base: Base{ID: 1}, Name: "a", Addr: netip.MustParseAddr("10.0.0.1"), Port: ptr[uint16](80), Weight: 0.5, Enabled: true},
+		{Base: Base{ID: 2}, Name: "b", Addr: netip.MustParseAddr("::1"), Weight: 1000},
+	}
+
+	r := NewReader(strings.NewReader(in))
+	var got []Host
+	for {
+		var h Host
+		err := r.ReadStruct(&h)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadStruct error: %v", err)
+		}
+		got = append(got, h)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadStruct:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadStructErrors(t *testing.T) {
+	in := `id,addr,port,weight
+1,bad,70000,1
+2,::1,80,
+`
+	r := NewReader(strings.NewReader(in))
+
+	var h Host
+	err := r.ReadStruct(&h)
+	want := []FieldError{
+		{Line: 2, Column: 3, Header: "addr", Field: "Addr", Type: reflect.TypeFor[netip.Addr]()},
+		{Line: 2, Column: 7, Header: "port", Field: "Port", Type: reflect.TypeFor[*uint16]()},
+	}
+	checkFieldErrors(t, err, want)
+	if h.ID != 1 || h.Weight != 1 {
+		t.Errorf("ReadStruct did not store valid fields: %+v", h)
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) || numErr.Err != strconv.ErrRange {
+		t.Errorf("ReadStruct error %v does not wrap strconv.ErrRange", err)
+	}
+
+	err = r.ReadStruct(&h)
+	want = []FieldError{
+		{Line: 3, Column: 10, Header: "weight", Field: "Weight", Type: reflect.TypeFor[float64]()},
+	}
+	checkFieldErrors(t, err, want)
+
+	if err := r.ReadStruct(&h); err != io.EOF {
+		t.Errorf("ReadStruct at end: got %v, want io.EOF", err)
+	}
+	if err := r.ReadStruct(h); err != errNotStructPointer {
+		t.Errorf("ReadStruct(non-pointer): got %v, want %v", err, errNotStructPointer)
+	}
+}
+
+func TestReadStructFieldCount(t *testing.T) {
+	in := `id,name,addr
+1,a
+2,b,::1
+`
+	r := NewReader(strings.NewReader(in))
+	r.FieldsPerRecord = 2
+
+	var h Host
+	err := r.ReadStruct(&h)
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Err != ErrFieldCount || pe.Line != 1 {
+		t.Fatalf("ReadStruct with bad header: got %v, want ErrFieldCount on line 1", err)
+	}
+	if err := r.ReadStruct(&h); err != nil {
+		t.Fatalf("ReadStruct error: %v", err)
+	}
+	if h.ID != 1 || h.Name != "a" {
+		t.Errorf("ReadStruct did not use the header: %+v", h)
+	}
+	if err := r.ReadStruct(&h); !errors.As(err, &pe) || pe.Err != ErrFieldCount || pe.Line != 3 {
+		t.Errorf("ReadStruct with bad record: got %v, want ErrFieldCount on line 3", err)
+	}
+
+	// Without FieldsPerRecord, the header sets the expected field count.
+	r = NewReader(strings.NewReader(in))
+	if err := r.ReadStruct(&h); !errors.As(err, &pe) || pe.Err != ErrFieldCount || pe.Line != 2 {
+		t.Errorf("ReadStruct with short record: got %v, want ErrFieldCount on line 2", err)
+	}
+}
+
+func checkFieldErrors(t *testing.T, err error, want []FieldError) {
+	t.Helper()
+	var errs []error
+	if u, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = u.Unwrap()
+	} else if err != nil {
+		errs = []error{err}
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors (%v), want %d", len(errs), err, len(want))
+	}
+	for i, err := range errs {
+		fe, ok := err.(*FieldError)
+		if !ok {
+			t.Errorf("error %d: got %T, want *FieldError", i, err)
+			continue
+		}
+		if fe.Err == nil {
+			t.Errorf("error %d: nil Err", i)
+		}
+		got := *fe
+		got.Err = nil
+		if got != want[i] {
+			t.Errorf("error %d:\ngot  %+v\nwant %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestWriteStruct(t *testing.T) {
+	rows := []Host{
+		{Base: Base{ID: 1, Note: "a, b"}, Name: "a", Addr: netip.MustParseAddr("10.0.0.1"), Port: ptr[uint16](80), Weight: 0.5, Enabled: true},
+		{Base: Base{ID: 2}, Name: "b", Addr: netip.MustParseAddr("::1"), Weight: 1000, Skipped: "x"},
+	}
+	want := `id,Note,name,addr,port,weight,enabled
+1,"a, b",a,10.0.0.1,80,0.5,true
+2,,b,::1,,1000,false
+`
+	var b strings.Builder
+	w := NewWriter(&b)
+	for _, row := range rows {
+		if err := w.WriteStruct(row); err != nil {
+			t.Fatalf("WriteStruct error: %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("WriteStruct:\ngot  %q\nwant %q", got, want)
+	}
+
+	// Round trip.
+	r := NewReader(strings.NewReader(b.String()))
+	for i, want := range rows {
+		want.Skipped = ""
+		var got Host
+		if err := r.ReadStruct(&got); err != nil {
+			t.Fatalf("ReadStruct error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("row %d round trip:\ngot  %+v\nwant %+v", i, got, want)
+		}
+	}
+}
+
+func TestWriteStructErrors(t *testing.T) {
+	type T struct {
+		A int
+		B chan int
+		C []int `csv:"c"`
+	}
+	var b strings.Builder
+	w := NewWriter(&b)
+	err := w.WriteStruct(&T{})
+	checkFieldErrors(t, err, []FieldError{
+		{Header: "B", Field: "B", Type: reflect.TypeFor[chan int]()},
+		{Header: "c", Field: "C", Type: reflect.TypeFor[[]int]()},
+	})
+	w.Flush()
+	if got, want := b.String(), ""; got != want {
+		t.Errorf("WriteStruct output: got %q, want %q", got, want)
+	}
+	if err := w.WriteStruct(1); err == nil {
+		t.Errorf("WriteStruct(1): got nil error")
+	}
+}
+
+func TestStructFieldNames(t *testing.T) {
+	type Inner struct {
+		A, B, C int
+	}
+	type Other struct {
+		B int
+	}
+	type T struct {
+		Inner
+		*Other
+		C int `csv:"A"`
+		D int `csv:"dee,omitempty"`
+	}
+	var names []string
+	for _, f := range structFields.Fields(reflect.TypeFor[T]()) {
+		names = append(names, f.Field+":"+f.Name)
+	}
+	// Inner.A is hidden by the shallower tagged C, B is ambiguous.
+	want := []string{"C:A", "D:dee"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("fields: got %q, want %q", names, want)
+	}
+}
@@ -33,6 +33,9 @@ type Writer struct {
 	Comma   rune // Field delimiter (set to ',' by NewWriter)
 	UseCRLF bool // True to use \r\n as the line terminator
 	w       *bufio.Writer
+
+	wroteHeader bool     // whether WriteStruct has written the header record
+	record      []string // record buffer for WriteStruct
 }
 
 // NewWriter returns a new Writer that writes to w.
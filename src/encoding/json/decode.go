@@ -8,8 +8,10 @@
 package json
 
 import (
+	"bytes"
 	"encoding"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -84,6 +86,7 @@ import (
 // an [UnmarshalTypeError] describing the earliest such error. In any
 // case, it's not guaranteed that all the remaining fields following
 // the problematic one will be unmarshaled into the target object.
+// See [Decoder.ReportAllErrors] to obtain every such error instead.
 //
 // The JSON null value unmarshals into an interface, map, pointer, or slice
 // by setting that Go value to nil. Because null is often used in JSON to mean
@@ -128,6 +131,16 @@ type UnmarshalTypeError struct {
 	Offset int64        // error occurred after reading Offset bytes
 	Struct string       // name of the struct type containing the field
 	Field  string       // the full path from root node to the field
+
+	// Pointer is the RFC 6901 JSON Pointer to the offending value,
+	// such as "/items/3/name", relative to the root of the decoded
+	// JSON value. It uses the object keys as they appear in the input.
+	Pointer string
+
+	// Line and Column give the 1-based line and byte column of the
+	// first byte of the offending value within the decoded JSON value.
+	// Unlike Offset, they do not point past the value.
+	Line, Column int
 }
 
 func (e *UnmarshalTypeError) Error() string {
@@ -180,9 +193,13 @@ func (d *decodeState) unmarshal(v any) error {
 	// test must be applied at the top level of the value.
 	err := d.value(rv)
 	if err != nil {
-		return d.addErrorContext(err)
+		err = d.addErrorContext(err)
+		if d.reportAllErrors {
+			return errors.Join(append(d.savedErrors, err)...)
+		}
+		return err
 	}
-	return d.savedError
+	return d.savedErr()
 }
 
 // A Number represents a JSON number literal.
@@ -207,6 +224,13 @@ type errorContext struct {
 	FieldStack []string
 }
 
+// A pathElem is one reference token of the JSON Pointer to the value
+// being decoded: either an array index or an object key.
+type pathElem struct {
+	index int    // array index; used if key is nil
+	key   []byte // quoted object key, aliasing decodeState.data
+}
+
 // decodeState represents the state while decoding a JSON value.
 type decodeState struct {
 	data                  []byte
@@ -214,9 +238,13 @@ type decodeState struct {
 	opcode                int // last read result
 	scan                  scanner
 	errorContext          *errorContext
+	path                  []pathElem
+	valueStart            int // index of the first byte of the value being decoded
 	savedError            error
+	savedErrors           []error // all saved errors, if reportAllErrors
 	useNumber             bool
 	disallowUnknownFields bool
+	reportAllErrors       bool
 }
 
 // readIndex returns the position of the last byte read.
@@ -232,7 +260,10 @@ const phasePanicMsg = "JSON decoder out of sync - data changing underfoot?"
 func (d *decodeState) init(data []byte) *decodeState {
 	d.data = data
 	d.off = 0
+	d.valueStart = 0
 	d.savedError = nil
+	d.savedErrors = nil
+	d.path = d.path[:0]
 	if d.errorContext != nil {
 		d.errorContext.Struct = nil
 		// Reuse the allocated space for the FieldStack slice.
@@ -243,14 +274,32 @@ func (d *decodeState) init(data []byte) *decodeState {
 
 // saveError saves the first err it is called with,
 // for reporting at the end of the unmarshal.
+// If d.reportAllErrors is set, it saves every err instead.
 func (d *decodeState) saveError(err error) {
+	if d.reportAllErrors {
+		d.savedErrors = append(d.savedErrors, d.addErrorContext(err))
+		return
+	}
 	if d.savedError == nil {
 		d.savedError = d.addErrorContext(err)
 	}
 }
 
+// savedErr returns the errors recorded by saveError, if any.
+func (d *decodeState) savedErr() error {
+	if d.reportAllErrors {
+		return errors.Join(d.savedErrors...)
+	}
+	return d.savedError
+}
+
 // addErrorContext returns a new error enhanced with information from d.errorContext
+// and the position of the value being decoded.
 func (d *decodeState) addErrorContext(err error) error {
+	if err, ok := err.(*UnmarshalTypeError); ok {
+		err.Pointer = d.pointer()
+		err.Line, err.Column = d.position(d.valueStart)
+	}
 	if d.errorContext != nil && (d.errorContext.Struct != nil || len(d.errorContext.FieldStack) > 0) {
 		switch err := err.(type) {
 		case *UnmarshalTypeError:
@@ -261,6 +310,41 @@ func (d *decodeState) addErrorContext(err error) error {
 	return err
 }
 
+// pointer returns the RFC 6901 JSON Pointer to the value being decoded.
+func (d *decodeState) pointer() string {
+	var b []byte
+	for _, e := range d.path {
+		b = append(b, '/')
+		if e.key == nil {
+			b = strconv.AppendInt(b, int64(e.index), 10)
+			continue
+		}
+		key, ok := unquoteBytes(e.key)
+		if !ok {
+			panic(phasePanicMsg)
+		}
+		for _, c := range key {
+			switch c {
+			case '~':
+				b = append(b, "~0"...)
+			case '/':
+				b = append(b, "~1"...)
+			default:
+				b = append(b, c)
+			}
+		}
+	}
+	return string(b)
+}
+
+// position returns the 1-based line and column of the byte at index i of d.data.
+func (d *decodeState) position(i int) (line, column int) {
+	data := d.data[:min(max(i, 0), len(d.data))]
+	line = 1 + bytes.Count(data, []byte{'\n'})
+	column = len(data) - bytes.LastIndexByte(data, '\n')
+	return line, column
+}
+
 // skip scans to the end of what was started.
 func (d *decodeState) skip() {
 	s, data, i := &d.scan, d.data, d.off
@@ -355,6 +439,7 @@ Switch:
 // reads the following byte ahead. If v is invalid, the value is discarded.
 // The first byte of the value has been read already.
 func (d *decodeState) value(v reflect.Value) error {
+	d.valueStart = d.readIndex()
 	switch d.opcode {
 	default:
 		panic(phasePanicMsg)
@@ -400,6 +485,7 @@ type unquotedValue struct{}
 // If it finds anything other than a quoted string literal or null,
 // valueQuoted returns unquotedValue{}.
 func (d *decodeState) valueQuoted() any {
+	d.valueStart = d.readIndex()
 	switch d.opcode {
 	default:
 		panic(phasePanicMsg)
@@ -532,6 +618,7 @@ func (d *decodeState) array(v reflect.Value) error {
 		break
 	}
 
+	d.path = append(d.path, pathElem{})
 	i := 0
 	for {
 		// Look ahead for ] - can only happen on first iteration.
@@ -539,6 +626,7 @@ func (d *decodeState) array(v reflect.Value) error {
 		if d.opcode == scanEndArray {
 			break
 		}
+		d.path[len(d.path)-1].index = i
 
 		// Expand slice length, growing the slice if necessary.
 		if v.Kind() == reflect.Slice {
@@ -574,6 +662,7 @@ func (d *decodeState) array(v reflect.Value) error {
 			panic(phasePanicMsg)
 		}
 	}
+	d.path = d.path[:len(d.path)-1]
 
 	if i < v.Len() {
 		if v.Kind() == reflect.Array {
@@ -657,6 +746,7 @@ func (d *decodeState) object(v reflect.Value) error {
 		origErrorContext = *d.errorContext
 	}
 
+	d.path = append(d.path, pathElem{})
 	for {
 		// Read opening " of string key or closing }.
 		d.scanWhile(scanSkipSpace)
@@ -676,6 +766,7 @@ func (d *decodeState) object(v reflect.Value) error {
 		if !ok {
 			panic(phasePanicMsg)
 		}
+		d.path[len(d.path)-1].key = item
 
 		// Figure out field corresponding to key.
 		var subv reflect.Value
@@ -760,6 +851,7 @@ func (d *decodeState) object(v reflect.Value) error {
 		// Write value back to map;
 		// if using struct, subv points into struct already.
 		if v.Kind() == reflect.Map {
+			d.valueStart = start // report key errors at the key
 			kt := t.Key()
 			var kv reflect.Value
 			if reflect.PointerTo(kt).Implements(textUnmarshalerType) {
@@ -818,6 +910,7 @@ func (d *decodeState) object(v reflect.Value) error {
 			panic(phasePanicMsg)
 		}
 	}
+	d.path = d.path[:len(d.path)-1]
 	return nil
 }
 
@@ -1020,6 +1113,7 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value, fromQuoted bool
 
 // valueInterface is like value but returns interface{}
 func (d *decodeState) valueInterface() (val any) {
+	d.valueStart = d.readIndex()
 	switch d.opcode {
 	default:
 		panic(phasePanicMsg)
@@ -1038,12 +1132,14 @@ func (d *decodeState) valueInterface() (val any) {
 // arrayInterface is like array but returns []interface{}.
 func (d *decodeState) arrayInterface() []any {
 	var v = make([]any, 0)
+	d.path = append(d.path, pathElem{})
 	for {
 		// Look ahead for ] - can only happen on first iteration.
 		d.scanWhile(scanSkipSpace)
 		if d.opcode == scanEndArray {
 			break
 		}
+		d.path[len(d.path)-1].index = len(v)
 
 		v = append(v, d.valueInterface())
 
@@ -1058,12 +1154,14 @@ func (d *decodeState) arrayInterface() []any {
 			panic(phasePanicMsg)
 		}
 	}
+	d.path = d.path[:len(d.path)-1]
 	return v
 }
 
 // objectInterface is like object but returns map[string]interface{}.
 func (d *decodeState) objectInterface() map[string]any {
 	m := make(map[string]any)
+	d.path = append(d.path, pathElem{})
 	for {
 		// Read opening " of string key or closing }.
 		d.scanWhile(scanSkipSpace)
@@ -1083,6 +1181,7 @@ func (d *decodeState) objectInterface() map[string]any {
 		if !ok {
 			panic(phasePanicMsg)
 		}
+		d.path[len(d.path)-1].key = item
 
 		// Read : before value.
 		if d.opcode == scanSkipSpace {
@@ -1107,6 +1206,7 @@ func (d *decodeState) objectInterface() map[string]any {
 			panic(phasePanicMsg)
 		}
 	}
+	d.path = d.path[:len(d.path)-1]
 	return m
 }
 
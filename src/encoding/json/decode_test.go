@@ -420,12 +420,12 @@ var unmarshalTests = []struct {
 	{CaseName: Name(""), in: `"g-clef: \uD834\uDD1E"`, ptr: new(string), out: "g-clef: \U0001D11E"},
 	{CaseName: Name(""), in: `"invalid: \uD834x\uDD1E"`, ptr: new(string), out: "invalid: \uFFFDx\uFFFD"},
 	{CaseName: Name(""), in: "null", ptr: new(any), out: nil},
-	{CaseName: Name(""), in: `{"X": [1,2,3], "Y": 4}`, ptr: new(T), out: T{Y: 4}, err: &UnmarshalTypeError{"array", reflect.TypeFor[string](), 7, "T", "X"}},
-	{CaseName: Name(""), in: `{"X": 23}`, ptr: new(T), out: T{}, err: &UnmarshalTypeError{"number", reflect.TypeFor[string](), 8, "T", "X"}},
+	{CaseName: Name(""), in: `{"X": [1,2,3], "Y": 4}`, ptr: new(T), out: T{Y: 4}, err: &UnmarshalTypeError{Value: "array", Type: reflect.TypeFor[string](), Offset: 7, Struct: "T", Field: "X"}},
+	{CaseName: Name(""), in: `{"X": 23}`, ptr: new(T), out: T{}, err: &UnmarshalTypeError{Value: "number", Type: reflect.TypeFor[string](), Offset: 8, Struct: "T", Field: "X"}},
 	{CaseName: Name(""), in: `{"x": 1}`, ptr: new(tx), out: tx{}},
 	{CaseName: Name(""), in: `{"x": 1}`, ptr: new(tx), out: tx{}},
 	{CaseName: Name(""), in: `{"x": 1}`, ptr: new(tx), err: fmt.Errorf("json: unknown field \"x\""), disallowUnknownFields: true},
-	{CaseName: Name(""), in: `{"S": 23}`, ptr: new(W), out: W{}, err: &UnmarshalTypeError{"number", reflect.TypeFor[SS](), 0, "W", "S"}},
+	{CaseName: Name(""), in: `{"S": 23}`, ptr: new(W), out: W{}, err: &UnmarshalTypeError{Value: "number", Type: reflect.TypeFor[SS](), Offset: 0, Struct: "W", Field: "S"}},
 	{CaseName: Name(""), in: `{"F1":1,"F2":2,"F3":3}`, ptr: new(V), out: V{F1: float64(1), F2: int32(2), F3: Number("3")}},
 	{CaseName: Name(""), in: `{"F1":1,"F2":2,"F3":3}`, ptr: new(V), out: V{F1: Number("1"), F2: int32(2), F3: Number("3")}, useNumber: true},
 	{CaseName: Name(""), in: `{"k1":1,"k2":"s","k3":[1,2.0,3e-3],"k4":{"kk1":"s","kk2":2}}`, ptr: new(any), out: ifaceNumAsFloat64},
@@ -2046,6 +2046,51 @@ func TestUnmarshalTypeError(t *testing.T) {
 	}
 }
 
+func TestUnmarshalTypeErrorPosition(t *testing.T) {
+	type Item struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	type Config struct {
+		Items []Item           `json:"items"`
+		Extra map[string][]int `json:"extra"`
+		Any   any              `json:"any"`
+		Keys  map[int]bool     `json:"keys"`
+	}
+	tests := []struct {
+		CaseName
+		in      string
+		pointer string
+		line    int
+		column  int
+	}{
+		{Name(""), `{"items": [{"name": "a"}, {"name": 1}]}`, "/items/1/name", 1, 36},
+		{Name(""), "{\n  \"items\": [\n    {\"count\": \"x\"}\n  ]\n}", "/items/0/count", 3, 15},
+		{Name(""), `{"extra": {"a/b~c": [1, "two"]}}`, "/extra/a~1b~0c/1", 1, 25},
+		{Name(""), `{"any": [{"big": 1e1000}]}`, "/any/0/big", 1, 18},
+		{Name(""), `{"ITEMS": [{"Name": true}]}`, "/ITEMS/0/Name", 1, 21},
+		{Name(""), `{"items": 1}`, "/items", 1, 11},
+		{Name(""), `{"items": [{"name": {"a": 1}}]}`, "/items/0/name", 1, 21},
+		{Name(""), `{"keys": {"1": true, "x": false}}`, "/keys/x", 1, 22},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var v Config
+			err := Unmarshal([]byte(tt.in), &v)
+			var ute *UnmarshalTypeError
+			if !errors.As(err, &ute) {
+				t.Fatalf("%s: Unmarshal error:\n\tgot:  %v\n\twant: %T", tt.Where, err, ute)
+			}
+			if ute.Pointer != tt.pointer {
+				t.Errorf("%s: Pointer:\n\tgot:  %q\n\twant: %q", tt.Where, ute.Pointer, tt.pointer)
+			}
+			if ute.Line != tt.line || ute.Column != tt.column {
+				t.Errorf("%s: Line:Column:\n\tgot:  %d:%d\n\twant: %d:%d", tt.Where, ute.Line, ute.Column, tt.line, tt.column)
+			}
+		})
+	}
+}
+
 func TestUnmarshalSyntax(t *testing.T) {
 	var x any
 	tests := []struct {
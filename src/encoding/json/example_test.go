@@ -172,6 +172,35 @@ func ExampleDecoder_Decode_stream() {
 	// json.Delim: ]
 }
 
+// This example streams an array of rows, writing the
+// enclosing object with WriteToken and each row with Encode.
+func ExampleEncoder_WriteToken() {
+	type Row struct {
+		ID   int
+		Name string
+	}
+	rows := []Row{{1, "Ed"}, {2, "Sam"}, {3, "Ann"}}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, t := range []json.Token{json.Delim('{'), "rows", json.Delim('[')} {
+		if err := enc.WriteToken(t); err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, t := range []json.Token{json.Delim(']'), json.Delim('}')} {
+		if err := enc.WriteToken(t); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// Output:
+	// {"rows":[{"ID":1,"Name":"Ed"},{"ID":2,"Name":"Sam"},{"ID":3,"Name":"Ann"}]}
+}
+
 // This example uses RawMessage to delay parsing part of a JSON message.
 func ExampleRawMessage_unmarshal() {
 	type Color struct {
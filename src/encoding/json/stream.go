@@ -7,7 +7,9 @@ package json
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
 )
 
 // A Decoder reads and decodes JSON values from an input stream.
@@ -41,6 +43,11 @@ func (dec *Decoder) UseNumber() { dec.d.useNumber = true }
 // non-ignored, exported fields in the destination.
 func (dec *Decoder) DisallowUnknownFields() { dec.d.disallowUnknownFields = true }
 
+// ReportAllErrors causes the Decoder to keep decoding after a JSON value
+// cannot be stored in the destination. Decode then reports every such
+// error, combined with [errors.Join], instead of only the first.
+func (dec *Decoder) ReportAllErrors() { dec.d.reportAllErrors = true }
+
 // Decode reads the next JSON-encoded value from its
 // input and stores it in the value pointed to by v.
 //
@@ -186,6 +193,10 @@ type Encoder struct {
 	indentBuf    []byte
 	indentPrefix string
 	indentValue  string
+
+	tokenState int
+	tokenStack []int
+	tokenBuf   []byte
 }
 
 // NewEncoder returns a new encoder that writes to w.
@@ -196,12 +207,19 @@ func NewEncoder(w io.Writer) *Encoder {
 // Encode writes the JSON encoding of v to the stream,
 // followed by a newline character.
 //
+// If Encode is called after [Encoder.WriteToken] has opened an array or
+// object, v is written as the next element of that array or as the value
+// of the pending object member, and no newline is added.
+//
 // See the documentation for [Marshal] for details about the
 // conversion of Go values to JSON.
 func (enc *Encoder) Encode(v any) error {
 	if enc.err != nil {
 		return enc.err
 	}
+	if enc.tokenState != tokenTopValue {
+		return enc.encodeInToken(v)
+	}
 
 	e := newEncodeState()
 	defer encodeStatePool.Put(e)
@@ -233,6 +251,178 @@ func (enc *Encoder) Encode(v any) error {
 	return err
 }
 
+// encodeInToken is like Encode but writes v inside the array or object
+// opened by WriteToken.
+func (enc *Encoder) encodeInToken(v any) error {
+	b, err := enc.tokenPrepareForValue(enc.tokenBuf[:0])
+	if err != nil {
+		return err
+	}
+
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+
+	if err := e.marshal(v, encOpts{escapeHTML: enc.escapeHTML}); err != nil {
+		return err
+	}
+	if enc.indentPrefix != "" || enc.indentValue != "" {
+		prefix := enc.indentPrefix + strings.Repeat(enc.indentValue, len(enc.tokenStack))
+		b, err = appendIndent(b, e.Bytes(), prefix, enc.indentValue)
+		if err != nil {
+			return err
+		}
+	} else {
+		b = append(b, e.Bytes()...)
+	}
+	return enc.tokenWrite(enc.tokenValueEnd(b))
+}
+
+// WriteToken writes the next JSON token to the stream.
+// Together with [Encoder.Encode], it allows a JSON value to be
+// written incrementally, without holding all of it in memory.
+//
+// The token must be one of the types returned by [Decoder.Token]:
+// a [Delim] to begin or end an array or object, or a bool, float64,
+// [Number], string or nil literal. A string written where an object
+// member is expected is the member's name. WriteToken inserts the
+// commas and colons between tokens and reports an error, without
+// writing anything, if the token is not valid at the current position.
+// Strings are escaped as by [Encoder.Encode], and indentation set by
+// [Encoder.SetIndent] is applied. Like Encode, WriteToken writes a
+// newline after the end of each top-level value.
+//
+// Each call to WriteToken performs a single Write to the underlying
+// writer, so callers writing many small tokens may want to wrap it in
+// a [bufio.Writer].
+func (enc *Encoder) WriteToken(t Token) error {
+	if enc.err != nil {
+		return enc.err
+	}
+
+	b := enc.tokenBuf[:0]
+	var err error
+	switch t := t.(type) {
+	case Delim:
+		switch t {
+		case '[', '{':
+			if b, err = enc.tokenPrepareForValue(b); err != nil {
+				return err
+			}
+			enc.tokenStack = append(enc.tokenStack, enc.tokenState)
+			if t == '[' {
+				enc.tokenState = tokenArrayStart
+			} else {
+				enc.tokenState = tokenObjectStart
+			}
+			b = append(b, byte(t))
+		case ']', '}':
+			switch {
+			case t == ']' && enc.tokenState == tokenArrayComma,
+				t == '}' && enc.tokenState == tokenObjectComma:
+				b = enc.tokenNewline(b, len(enc.tokenStack)-1)
+			case t == ']' && enc.tokenState == tokenArrayStart,
+				t == '}' && enc.tokenState == tokenObjectStart:
+			default:
+				return enc.tokenError(t)
+			}
+			enc.tokenState = enc.tokenStack[len(enc.tokenStack)-1]
+			enc.tokenStack = enc.tokenStack[:len(enc.tokenStack)-1]
+			b = enc.tokenValueEnd(append(b, byte(t)))
+		default:
+			return fmt.Errorf("json: invalid delimiter %q", rune(t))
+		}
+		return enc.tokenWrite(b)
+
+	case string:
+		switch enc.tokenState {
+		case tokenObjectStart, tokenObjectComma:
+			if enc.tokenState == tokenObjectComma {
+				b = append(b, ',')
+			}
+			b = enc.tokenNewline(b, len(enc.tokenStack))
+			b = appendString(b, t, enc.escapeHTML)
+			b = append(b, ':')
+			if enc.indentPrefix != "" || enc.indentValue != "" {
+				b = append(b, ' ')
+			}
+			enc.tokenState = tokenObjectColon
+			return enc.tokenWrite(b)
+		}
+
+	case bool, float64, Number, nil:
+	default:
+		return fmt.Errorf("json: invalid token type %T", t)
+	}
+	return enc.Encode(t)
+}
+
+// tokenPrepareForValue appends to b the separator needed before
+// the next value and reports an error if no value is allowed.
+func (enc *Encoder) tokenPrepareForValue(b []byte) ([]byte, error) {
+	switch enc.tokenState {
+	case tokenArrayComma:
+		b = append(b, ',')
+		fallthrough
+	case tokenArrayStart:
+		b = enc.tokenNewline(b, len(enc.tokenStack))
+	case tokenObjectStart, tokenObjectComma:
+		return b, errors.New("json: expected object member name, found value")
+	}
+	return b, nil
+}
+
+// tokenValueEnd advances the token state after a complete value
+// and appends the newline that terminates a top-level value.
+func (enc *Encoder) tokenValueEnd(b []byte) []byte {
+	switch enc.tokenState {
+	case tokenTopValue:
+		b = append(b, '\n')
+	case tokenArrayStart:
+		enc.tokenState = tokenArrayComma
+	case tokenObjectColon:
+		enc.tokenState = tokenObjectComma
+	}
+	return b
+}
+
+// tokenNewline appends a newline and indentation for the given nesting
+// depth to b, if indentation is enabled.
+func (enc *Encoder) tokenNewline(b []byte, depth int) []byte {
+	if enc.indentPrefix == "" && enc.indentValue == "" {
+		return b
+	}
+	b = append(b, '\n')
+	b = append(b, enc.indentPrefix...)
+	for range depth {
+		b = append(b, enc.indentValue...)
+	}
+	return b
+}
+
+func (enc *Encoder) tokenError(d Delim) error {
+	var context string
+	switch enc.tokenState {
+	case tokenTopValue:
+		context = " outside array or object"
+	case tokenArrayStart, tokenArrayComma:
+		context = " in array"
+	case tokenObjectStart, tokenObjectComma:
+		context = " in object"
+	case tokenObjectColon:
+		context = " after object member name"
+	}
+	return fmt.Errorf("json: unexpected %q%s", rune(d), context)
+}
+
+func (enc *Encoder) tokenWrite(b []byte) error {
+	enc.tokenBuf = b
+	if _, err := enc.w.Write(b); err != nil {
+		enc.err = err
+		return err
+	}
+	return nil
+}
+
 // SetIndent instructs the encoder to format each subsequent encoded
 // value as if indented by the package-level function Indent(dst, src, prefix, indent).
 // Calling SetIndent("", "") disables indentation.
@@ -6,6 +6,7 @@ package json
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -16,6 +17,7 @@ import (
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -235,6 +237,134 @@ func TestEncoderSetEscapeHTML(t *testing.T) {
 	}
 }
 
+// encodeValue is an item of a token stream that is written with Encode.
+type encodeValue struct{ v any }
+
+func writeTokens(enc *Encoder, toks []any) error {
+	for _, tok := range toks {
+		var err error
+		if ev, ok := tok.(encodeValue); ok {
+			err = enc.Encode(ev.v)
+		} else {
+			err = enc.WriteToken(tok)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestEncoderWriteToken(t *testing.T) {
+	tests := []struct {
+		CaseName
+		toks []any
+		want string
+	}{{
+		CaseName: Name(""),
+		toks:     []any{Delim('['), 1.0, "a", Delim('{'), "k", true, Delim('}'), nil, Number("1e3"), Delim(']')},
+		want:     `[1,"a",{"k":true},null,1e3]` + "\n",
+	}, {
+		CaseName: Name(""),
+		toks: []any{
+			Delim('{'), "rows", Delim('['),
+			encodeValue{struct{ A int }{1}}, encodeValue{[]int{2}},
+			Delim(']'), "n", encodeValue{3}, Delim('}'),
+		},
+		want: `{"rows":[{"A":1},[2]],"n":3}` + "\n",
+	}, {
+		CaseName: Name(""),
+		toks:     []any{Delim('{'), "<&>", "<&>", Delim('}')},
+		want:     `{"\u003c\u0026\u003e":"\u003c\u0026\u003e"}` + "\n",
+	}, {
+		CaseName: Name(""),
+		toks:     []any{Delim('['), Delim(']'), Delim('{'), Delim('}'), nil, encodeValue{"x"}, false},
+		want:     "[]\n{}\nnull\n\"x\"\nfalse\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := writeTokens(NewEncoder(&buf), tt.toks); err != nil {
+				t.Fatalf("%s: WriteToken error: %v", tt.Where, err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("%s: WriteToken:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoderWriteTokenIndent(t *testing.T) {
+	toks := []any{
+		Delim('{'),
+		"a", Delim('['), 1.0, 2.0, Delim(']'),
+		"b", Delim('{'), Delim('}'),
+		"c", encodeValue{map[string]any{"x": []int{1}, "y": []int{}}},
+		"d", Delim('['), Delim('{'), "e", nil, Delim('}'), Delim(']'),
+		Delim('}'),
+	}
+	v := map[string]any{
+		"a": []int{1, 2},
+		"b": struct{}{},
+		"c": map[string]any{"x": []int{1}, "y": []int{}},
+		"d": []any{map[string]any{"e": nil}},
+	}
+
+	var want strings.Builder
+	enc := NewEncoder(&want)
+	enc.SetIndent(">", "\t")
+	if err := enc.Encode(v); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+
+	var got strings.Builder
+	enc = NewEncoder(&got)
+	enc.SetIndent(">", "\t")
+	if err := writeTokens(enc, toks); err != nil {
+		t.Fatalf("WriteToken error: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("WriteToken:\n\tgot:  %s\n\twant: %s", got.String(), want.String())
+	}
+}
+
+func TestEncoderWriteTokenError(t *testing.T) {
+	tests := []struct {
+		CaseName
+		toks []any
+		err  string
+	}{
+		{Name(""), []any{Delim(']')}, `json: unexpected ']' outside array or object`},
+		{Name(""), []any{Delim('['), Delim('}')}, `json: unexpected '}' in array`},
+		{Name(""), []any{Delim('{'), Delim(']')}, `json: unexpected ']' in object`},
+		{Name(""), []any{Delim('{'), "k", Delim('}')}, `json: unexpected '}' after object member name`},
+		{Name(""), []any{Delim('{'), 1.0}, `json: expected object member name, found value`},
+		{Name(""), []any{Delim('{'), Delim('[')}, `json: expected object member name, found value`},
+		{Name(""), []any{Delim('{'), encodeValue{"k"}}, `json: expected object member name, found value`},
+		{Name(""), []any{Delim('x')}, `json: invalid delimiter 'x'`},
+		{Name(""), []any{1}, `json: invalid token type int`},
+		{Name(""), []any{Delim('['), encodeValue{make(chan int)}}, `json: unsupported type: chan int`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buf strings.Builder
+			enc := NewEncoder(&buf)
+			err := writeTokens(enc, tt.toks)
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("%s: WriteToken error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.err)
+			}
+			// The failing token must not have been written.
+			var want strings.Builder
+			if err := writeTokens(NewEncoder(&want), tt.toks[:len(tt.toks)-1]); err != nil {
+				t.Fatalf("%s: WriteToken error: %v", tt.Where, err)
+			}
+			if buf.String() != want.String() {
+				t.Errorf("%s: output after error:\n\tgot:  %s\n\twant: %s", tt.Where, buf.String(), want.String())
+			}
+		})
+	}
+}
+
 func TestDecoder(t *testing.T) {
 	for i := 0; i <= len(streamTest); i++ {
 		// Use stream without newlines as input,
@@ -303,6 +433,47 @@ func nlines(s string, n int) string {
 	return s
 }
 
+func TestDecoderReportAllErrors(t *testing.T) {
+	type T struct {
+		A int      `json:"a"`
+		B string   `json:"b"`
+		C []uint8  `json:"c"`
+		D []string `json:"d"`
+	}
+	in := `{"a": "x", "b": 2, "c": "not base64!", "d": ["ok", 3, false]}`
+	want := []string{"/a", "/b", "", "/d/1", "/d/2"}
+
+	dec := NewDecoder(strings.NewReader(in))
+	dec.ReportAllErrors()
+	var v T
+	err := dec.Decode(&v)
+	if err == nil {
+		t.Fatal("Decode error: got nil, want non-nil")
+	}
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	var got []string
+	for _, err := range errs {
+		var ute *UnmarshalTypeError
+		if errors.As(err, &ute) {
+			got = append(got, ute.Pointer)
+		} else {
+			got = append(got, "")
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Decode error pointers:\n\tgot:  %q\n\twant: %q", got, want)
+	}
+	if v.D[0] != "ok" {
+		t.Errorf("Decode did not continue past errors: D = %q", v.D)
+	}
+
+	// Without ReportAllErrors, only the first error is reported.
+	err = NewDecoder(strings.NewReader(in)).Decode(new(T))
+	if _, ok := err.(*UnmarshalTypeError); !ok {
+		t.Errorf("Decode error:\n\tgot:  %T\n\twant: %T", err, new(UnmarshalTypeError))
+	}
+}
+
 func TestRawMessage(t *testing.T) {
 	var data struct {
 		X  float64
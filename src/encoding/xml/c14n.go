@@ -0,0 +1,425 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xml
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// An nsDecl is a name space declaration binding prefix to url.
+// The empty prefix denotes the default name space.
+type nsDecl struct {
+	prefix, url string
+}
+
+// An nsScope records the name space declarations
+// made by a stack of open elements.
+type nsScope struct {
+	decls []nsDecl // in-scope declarations, innermost last
+	marks []int    // start of each open element's declarations in decls
+}
+
+func (s *nsScope) push() {
+	s.marks = append(s.marks, len(s.decls))
+}
+
+func (s *nsScope) pop() {
+	n := s.marks[len(s.marks)-1]
+	s.marks = s.marks[:len(s.marks)-1]
+	s.decls = s.decls[:n]
+}
+
+func (s *nsScope) declare(prefix, url string) {
+	s.decls = append(s.decls, nsDecl{prefix, url})
+}
+
+// lookup returns the name space bound to prefix.
+func (s *nsScope) lookup(prefix string) (url string, ok bool) {
+	for i := len(s.decls) - 1; i >= 0; i-- {
+		if s.decls[i].prefix == prefix {
+			return s.decls[i].url, true
+		}
+	}
+	if prefix == xmlPrefix {
+		return xmlURL, true
+	}
+	return "", false
+}
+
+// prefixFor returns the innermost prefix bound to url.
+// The default name space is considered only if allowDefault is set.
+func (s *nsScope) prefixFor(url string, allowDefault bool) (prefix string, ok bool) {
+	if url == xmlURL {
+		return xmlPrefix, true
+	}
+	for i := len(s.decls) - 1; i >= 0; i-- {
+		d := s.decls[i]
+		if d.url != url || d.prefix == "" && !allowDefault {
+			continue
+		}
+		// Skip prefixes rebound by an inner declaration.
+		if u, _ := s.lookup(d.prefix); u == url {
+			return d.prefix, true
+		}
+	}
+	return "", false
+}
+
+// prefixOf returns the prefix to write for a name of start with
+// name space url: its Name if i is 0, or its Attr[i-1] otherwise.
+// It prefers the prefix with which the name was written in the input,
+// if the Decoder recorded it and it is still bound to url, and
+// otherwise uses prefixFor.
+func (s *nsScope) prefixOf(start *StartElement, i int, url string, allowDefault bool) (prefix string, ok bool) {
+	if len(start.prefixes) == 1+len(start.Attr) {
+		p := start.prefixes[i]
+		if u, _ := s.lookup(p); u == url && (p != "" || allowDefault) {
+			return p, true
+		}
+	}
+	return s.prefixFor(url, allowDefault)
+}
+
+// nsDeclPrefix reports whether an attribute with the given name,
+// as returned by [Decoder.Token], is a name space declaration,
+// and if so, the prefix it declares.
+func nsDeclPrefix(name Name) (prefix string, ok bool) {
+	switch {
+	case name.Space == xmlnsPrefix:
+		return name.Local, true
+	case name.Space == "" && name.Local == xmlnsPrefix:
+		return "", true
+	}
+	return "", false
+}
+
+// nsDeclName returns the attribute name that declares prefix.
+func nsDeclName(prefix string) string {
+	if prefix == "" {
+		return xmlnsPrefix
+	}
+	return xmlnsPrefix + ":" + prefix
+}
+
+func joinPrefix(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+// A Canonicalizer writes a stream of tokens as canonical XML, as
+// defined by Canonical XML Version 1.0
+// (https://www.w3.org/TR/2001/REC-xml-c14n-20010315) or, if Exclusive
+// is set, by Exclusive XML Canonicalization Version 1.0
+// (https://www.w3.org/TR/2002/REC-xml-exc-c14n-20020718/).
+// These are the canonicalization methods used by XML Signature.
+//
+// The tokens, typically read with [Decoder.Token] from a Decoder with
+// [Decoder.NormalizeAttrs] set, must form a well-formed document or a
+// sequence of complete elements: the document subset to canonicalize. Element and attribute names are
+// written with the prefixes they had in the input, as recorded by the
+// Decoder, which must be bound by the xmlns attributes of the tokens or
+// by [Canonicalizer.Namespaces]. A name without a recorded prefix uses
+// the innermost prefix bound to its name space.
+//
+// The Canonicalizer does not process document type declarations, so
+// it does not add default attributes or normalize attribute values
+// according to their declared types, and it does not render the xml:
+// attributes inherited by the first element of a document subset.
+type Canonicalizer struct {
+	// Exclusive selects Exclusive XML Canonicalization, in which an
+	// element only declares the name spaces it uses.
+	Exclusive bool
+
+	// Comments keeps comments in the output, as required by the
+	// "WithComments" variants of the canonicalization methods.
+	Comments bool
+
+	// InclusivePrefixes is the InclusiveNamespaces PrefixList of
+	// Exclusive XML Canonicalization: prefixes whose declarations
+	// are rendered as in inclusive canonicalization.
+	// The default name space is denoted "#default".
+	InclusivePrefixes []string
+
+	// Namespaces holds the name space declarations in scope at the
+	// start of the token stream, mapping prefixes to name space URLs.
+	// The default name space has the empty prefix. It is needed to
+	// canonicalize an element that uses declarations made by its
+	// ancestors; see [Decoder.Namespaces].
+	Namespaces map[string]string
+
+	w        *bufio.Writer
+	started  bool
+	scope    nsScope  // declarations in the input
+	rendered nsScope  // declarations in the output
+	names    []Name   // names of open elements
+	qnames   []string // prefixed names of open elements
+	afterDoc bool     // whether a top-level element has been written
+}
+
+// NewCanonicalizer returns a new Canonicalizer that writes to w.
+func NewCanonicalizer(w io.Writer) *Canonicalizer {
+	return &Canonicalizer{w: bufio.NewWriter(w)}
+}
+
+// EncodeToken writes the canonical form of t.
+// It returns an error if [StartElement] and [EndElement] tokens are
+// not properly matched or if a name uses an undeclared name space.
+//
+// EncodeToken buffers its output; callers must call [Canonicalizer.Flush]
+// when finished.
+func (c *Canonicalizer) EncodeToken(t Token) error {
+	if !c.started {
+		c.started = true
+		c.scope.push()
+		for _, prefix := range sortedKeys(c.Namespaces) {
+			c.scope.declare(prefix, c.Namespaces[prefix])
+		}
+	}
+
+	switch t := t.(type) {
+	case StartElement:
+		return c.writeStart(&t)
+	case EndElement:
+		return c.writeEnd(t.Name)
+	case CharData:
+		if len(c.names) > 0 {
+			c.escape(t, false)
+		}
+	case Comment:
+		if c.Comments {
+			c.writeTopLevel(func() {
+				c.w.WriteString("<!--")
+				c.w.Write(t)
+				c.w.WriteString("-->")
+			})
+		}
+	case ProcInst:
+		if t.Target == "xml" {
+			break
+		}
+		c.writeTopLevel(func() {
+			c.w.WriteString("<?")
+			c.w.WriteString(t.Target)
+			if len(t.Inst) > 0 {
+				c.w.WriteByte(' ')
+				c.w.Write(t.Inst)
+			}
+			c.w.WriteString("?>")
+		})
+	case Directive:
+		// Document type declarations are not part of the canonical form.
+	default:
+		return fmt.Errorf("xml: EncodeToken of invalid token type")
+	}
+	return nil
+}
+
+// Flush flushes any buffered output to the underlying writer.
+func (c *Canonicalizer) Flush() error {
+	return c.w.Flush()
+}
+
+// writeTopLevel calls write to output a comment or processing
+// instruction, separating it by a newline from the document element
+// if it appears outside of it.
+func (c *Canonicalizer) writeTopLevel(write func()) {
+	switch {
+	case len(c.names) > 0:
+		write()
+	case c.afterDoc:
+		c.w.WriteByte('\n')
+		write()
+	default:
+		write()
+		c.w.WriteByte('\n')
+	}
+}
+
+func (c *Canonicalizer) writeStart(start *StartElement) error {
+	if start.Name.Local == "" {
+		return fmt.Errorf("xml: start tag with no name")
+	}
+	c.scope.push()
+	c.rendered.push()
+	var attrs []Attr
+	var index []int // index of each of attrs in start.Attr
+	for i, attr := range start.Attr {
+		if prefix, ok := nsDeclPrefix(attr.Name); ok {
+			c.scope.declare(prefix, attr.Value)
+		} else {
+			attrs = append(attrs, attr)
+			index = append(index, i)
+		}
+	}
+
+	prefix := ""
+	if start.Name.Space != "" {
+		var ok bool
+		if prefix, ok = c.scope.prefixOf(start, 0, start.Name.Space, true); !ok {
+			return fmt.Errorf("xml: no prefix declared for name space %s of element <%s>", start.Name.Space, start.Name.Local)
+		}
+	}
+	qname := joinPrefix(prefix, start.Name.Local)
+	c.names = append(c.names, start.Name)
+	c.qnames = append(c.qnames, qname)
+
+	// Determine the prefixes whose declarations may need rendering.
+	used := []string{prefix}
+	qattrs := make([]string, len(attrs))
+	for i, attr := range attrs {
+		p := ""
+		if attr.Name.Space != "" {
+			var ok bool
+			if p, ok = c.scope.prefixOf(start, 1+index[i], attr.Name.Space, false); !ok {
+				return fmt.Errorf("xml: no prefix declared for name space %s of attribute %s", attr.Name.Space, attr.Name.Local)
+			}
+			used = append(used, p)
+		}
+		qattrs[i] = joinPrefix(p, attr.Name.Local)
+	}
+	var candidates []string
+	if c.Exclusive {
+		candidates = used
+		for _, p := range c.InclusivePrefixes {
+			if p == "#default" {
+				p = ""
+			}
+			candidates = append(candidates, p)
+		}
+	} else {
+		for _, d := range c.scope.decls {
+			candidates = append(candidates, d.prefix)
+		}
+	}
+	slices.Sort(candidates)
+	candidates = slices.Compact(candidates)
+
+	var decls []nsDecl
+	for _, p := range candidates {
+		if p == xmlPrefix {
+			continue
+		}
+		url, ok := c.scope.lookup(p)
+		if !ok && p != "" {
+			continue
+		}
+		prev, _ := c.rendered.lookup(p)
+		if url == prev {
+			// Already rendered by an output ancestor, or
+			// an empty default name space that needs no undeclaring.
+			continue
+		}
+		c.rendered.declare(p, url)
+		decls = append(decls, nsDecl{p, url})
+	}
+
+	// Attributes are sorted by name space URL, then local name.
+	order := make([]int, len(attrs))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(i, j int) int {
+		a, b := attrs[i].Name, attrs[j].Name
+		return cmp.Or(strings.Compare(a.Space, b.Space), strings.Compare(a.Local, b.Local))
+	})
+
+	c.w.WriteByte('<')
+	c.w.WriteString(qname)
+	for _, d := range decls {
+		c.w.WriteByte(' ')
+		c.w.WriteString(nsDeclName(d.prefix))
+		c.w.WriteString(`="`)
+		c.escape([]byte(d.url), true)
+		c.w.WriteByte('"')
+	}
+	for _, i := range order {
+		c.w.WriteByte(' ')
+		c.w.WriteString(qattrs[i])
+		c.w.WriteString(`="`)
+		c.escape([]byte(attrs[i].Value), true)
+		c.w.WriteByte('"')
+	}
+	c.w.WriteByte('>')
+	return nil
+}
+
+func (c *Canonicalizer) writeEnd(name Name) error {
+	if len(c.names) == 0 {
+		return fmt.Errorf("xml: end tag </%s> without start tag", name.Local)
+	}
+	if top := c.names[len(c.names)-1]; top != name {
+		return fmt.Errorf("xml: end tag </%s> does not match start tag <%s>", name.Local, top.Local)
+	}
+	c.w.WriteString("</")
+	c.w.WriteString(c.qnames[len(c.qnames)-1])
+	c.w.WriteByte('>')
+	c.names = c.names[:len(c.names)-1]
+	c.qnames = c.qnames[:len(c.qnames)-1]
+	c.scope.pop()
+	c.rendered.pop()
+	if len(c.names) == 0 {
+		c.afterDoc = true
+	}
+	return nil
+}
+
+// escape writes s escaped as canonical text or, if attr is set,
+// as a canonical attribute value.
+func (c *Canonicalizer) escape(s []byte, attr bool) {
+	last := 0
+	for i, b := range s {
+		var esc string
+		switch b {
+		case '&':
+			esc = "&amp;"
+		case '<':
+			esc = "&lt;"
+		case '>':
+			if attr {
+				continue
+			}
+			esc = "&gt;"
+		case '"':
+			if !attr {
+				continue
+			}
+			esc = "&quot;"
+		case '\t':
+			if !attr {
+				continue
+			}
+			esc = "&#x9;"
+		case '\n':
+			if !attr {
+				continue
+			}
+			esc = "&#xA;"
+		case '\r':
+			esc = "&#xD;"
+		default:
+			continue
+		}
+		c.w.Write(s[last:i])
+		c.w.WriteString(esc)
+		last = i + 1
+	}
+	c.w.Write(s[last:])
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,278 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xml
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func canonicalize(t *testing.T, c *Canonicalizer, w *strings.Builder, in string) string {
+	t.Helper()
+	d := NewDecoder(strings.NewReader(in))
+	d.NormalizeAttrs = true
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Token: %v", err)
+		}
+		if err := c.EncodeToken(tok); err != nil {
+			t.Fatalf("EncodeToken: %v", err)
+		}
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return w.String()
+}
+
+var c14nTests = []struct {
+	name      string
+	in        string
+	exclusive bool
+	comments  bool
+	want      string
+}{{
+	// Canonical XML 1.0, section 3.1.
+	name: "PIs and comments",
+	in: `<?xml version="1.0"?>
+
+<?xml-stylesheet   href="doc.xsl"
+   type="text/xsl"   ?>
+
+<!DOCTYPE doc SYSTEM "doc.dtd">
+
+<doc>Hello, world!<!-- Comment 1 --></doc>
+
+<?pi-without-data     ?>
+
+<!-- Comment 2 -->
+
+<!-- Comment 3 -->`,
+	want: `<?xml-stylesheet href="doc.xsl"
+   type="text/xsl"   ?>
+<doc>Hello, world!</doc>
+<?pi-without-data?>`,
+}, {
+	name: "PIs and comments with comments",
+	in: `<?xml version="1.0"?>
+
+<?xml-stylesheet   href="doc.xsl"
+   type="text/xsl"   ?>
+
+<!DOCTYPE doc SYSTEM "doc.dtd">
+
+<doc>Hello, world!<!-- Comment 1 --></doc>
+
+<?pi-without-data     ?>
+
+<!-- Comment 2 -->
+
+<!-- Comment 3 -->`,
+	comments: true,
+	want: `<?xml-stylesheet href="doc.xsl"
+   type="text/xsl"   ?>
+<doc>Hello, world!<!-- Comment 1 --></doc>
+<?pi-without-data?>
+<!-- Comment 2 -->
+<!-- Comment 3 -->`,
+}, {
+	// Canonical XML 1.0, section 3.3, without the DTD.
+	name: "start and end tags",
+	in: `<doc>
+   <e1   />
+   <e2   ></e2>
+   <e3   name = "elem3"   id="elem3"   />
+   <e5 a:attr="out" b:attr="sorted" attr2="all" attr="I'm"
+      xmlns:b="http://www.ietf.org"
+      xmlns:a="http://www.w3.org"
+      xmlns="http://example.org"/>
+   <e6 xmlns="" xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="" xmlns:a="http://www.w3.org">
+            <e9 xmlns="" xmlns:a="http://www.ietf.org"/>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+	want: `<doc>
+   <e1></e1>
+   <e2></e2>
+   <e3 id="elem3" name="elem3"></e3>
+   <e5 xmlns="http://example.org" xmlns:a="http://www.w3.org" xmlns:b="http://www.ietf.org" attr="I'm" attr2="all" b:attr="sorted" a:attr="out"></e5>
+   <e6 xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="">
+            <e9 xmlns:a="http://www.ietf.org"></e9>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+}, {
+	name: "start and end tags exclusive",
+	in: `<doc>
+   <e5 a:attr="out" b:attr="sorted" attr2="all" attr="I'm"
+      xmlns:b="http://www.ietf.org"
+      xmlns:a="http://www.w3.org"
+      xmlns:c="http://unused.example"
+      xmlns="http://example.org"/>
+   <e6 xmlns="" xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="" xmlns:a="http://www.w3.org">
+            <e9 xmlns="" xmlns:a="http://www.ietf.org" a:x="1"/>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+	exclusive: true,
+	want: `<doc>
+   <e5 xmlns="http://example.org" xmlns:a="http://www.w3.org" xmlns:b="http://www.ietf.org" attr="I'm" attr2="all" b:attr="sorted" a:attr="out"></e5>
+   <e6>
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="">
+            <e9 xmlns:a="http://www.ietf.org" a:x="1"></e9>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+}, {
+	// Canonical XML 1.0, section 3.4, without the DTD.
+	name: "character modifications and character references",
+	in: `<doc>
+   <text>First line&#x0d;&#10;Second line</text>
+   <value>&#x32;</value>
+   <compute><![CDATA[value>"0" && value<"10" ?"valid":"error"]]></compute>
+   <compute expr='value>"0" &amp;&amp; value&lt;"10" ?"valid":"error"'>valid</compute>
+   <norm attr=' &apos;   &#x20;&#13;&#xa;&#9;   &apos; '/>
+</doc>`,
+	want: `<doc>
+   <text>First line&#xD;
+Second line</text>
+   <value>2</value>
+   <compute>value&gt;"0" &amp;&amp; value&lt;"10" ?"valid":"error"</compute>
+   <compute expr="value>&quot;0&quot; &amp;&amp; value&lt;&quot;10&quot; ?&quot;valid&quot;:&quot;error&quot;">valid</compute>
+   <norm attr=" '    &#xD;&#xA;&#x9;   ' "></norm>
+</doc>`,
+}, {
+	name: "attribute value normalization",
+	in:   "<doc a='x\ty\nz\r\nw\rv' b='&#9;\t&#10;\n&#13;\r'>\t\r\n</doc>",
+	want: "<doc a=\"x y z w v\" b=\"&#x9; &#xA; &#xD; \">\t\n</doc>",
+}, {
+	name: "character escaping",
+	in:   "<doc a='&quot;x&#9;y&#10;z&lt;&gt;&amp;'>&amp;&lt;&gt;\"'&#13;<![CDATA[<&>]]></doc>",
+	want: `<doc a="&quot;x&#x9;y&#xA;z&lt;>&amp;">&amp;&lt;&gt;"'&#xD;&lt;&amp;&gt;</doc>`,
+}, {
+	// Two prefixes bound to the same name space:
+	// names keep the prefix they were written with.
+	name: "prefixes of one name space",
+	in:   `<p:a xmlns:p="urn:u" xmlns="urn:u"><b p:x="1"/></p:a>`,
+	want: `<p:a xmlns="urn:u" xmlns:p="urn:u"><b p:x="1"></b></p:a>`,
+}, {
+	name:      "exclusive prefixes of one name space",
+	in:        `<p:a xmlns:p="urn:u" xmlns="urn:u"><b p:x="1"/></p:a>`,
+	exclusive: true,
+	want:      `<p:a xmlns:p="urn:u"><b xmlns="urn:u" p:x="1"></b></p:a>`,
+}}
+
+func TestCanonicalizer(t *testing.T) {
+	for _, tt := range c14nTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w strings.Builder
+			c := NewCanonicalizer(&w)
+			c.Exclusive = tt.exclusive
+			c.Comments = tt.comments
+			if got := canonicalize(t, c, &w, tt.in); got != tt.want {
+				t.Errorf("canonical form:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCanonicalizerSubset canonicalizes an element of a document,
+// using the example of Exclusive XML Canonicalization 1.0, section 2.2.
+func TestCanonicalizerSubset(t *testing.T) {
+	const in = `<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org">
+  <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"/>
+  </n1:elem2>
+</n0:local>`
+	tests := []struct {
+		exclusive bool
+		prefixes  []string
+		want      string
+	}{{
+		want: `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xmlns:n3="ftp://example.org" xml:lang="en">
+    <n3:stuff></n3:stuff>
+  </n1:elem2>`,
+	}, {
+		exclusive: true,
+		want: `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+  </n1:elem2>`,
+	}, {
+		exclusive: true,
+		prefixes:  []string{"n0", "#default"},
+		want: `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+  </n1:elem2>`,
+	}}
+	for _, tt := range tests {
+		d := NewDecoder(strings.NewReader(in))
+		d.NormalizeAttrs = true
+		var w strings.Builder
+		c := NewCanonicalizer(&w)
+		c.Exclusive = tt.exclusive
+		c.InclusivePrefixes = tt.prefixes
+		in, depth := false, 0
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Token: %v", err)
+			}
+			if se, ok := tok.(StartElement); ok && se.Name.Local == "elem2" {
+				c.Namespaces = d.Namespaces()
+				in = true
+			}
+			if !in {
+				continue
+			}
+			if err := c.EncodeToken(tok); err != nil {
+				t.Fatalf("EncodeToken: %v", err)
+			}
+			switch tok.(type) {
+			case StartElement:
+				depth++
+			case EndElement:
+				depth--
+				in = depth > 0
+			}
+		}
+		if err := c.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got := w.String(); got != tt.want {
+			t.Errorf("Exclusive=%v InclusivePrefixes=%q:\ngot:\n%s\nwant:\n%s", tt.exclusive, tt.prefixes, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalizerErrors(t *testing.T) {
+	c := NewCanonicalizer(io.Discard)
+	if err := c.EncodeToken(StartElement{Name: Name{Space: "urn:x", Local: "a"}}); err == nil {
+		t.Errorf("EncodeToken of element in undeclared name space: got nil error")
+	}
+	c = NewCanonicalizer(io.Discard)
+	c.EncodeToken(StartElement{Name: Name{Local: "a"}})
+	if err := c.EncodeToken(EndElement{Name: Name{Local: "b"}}); err == nil {
+		t.Errorf("EncodeToken of mismatched end element: got nil error")
+	}
+}
@@ -160,6 +160,22 @@ func (enc *Encoder) Indent(prefix, indent string) {
 	enc.p.indent = indent
 }
 
+// PreservePrefixes causes the Encoder to write name space prefixes
+// declared by the xmlns attributes of the tokens it encodes, as returned
+// by [Decoder.Token], instead of inventing its own. This allows a token
+// stream to be decoded and re-encoded without changing its prefixes.
+//
+// Each element or attribute name is written with the prefix it had in
+// the input if the token was read by a Decoder with
+// [Decoder.NormalizeAttrs] set and that prefix is still bound to its
+// name space URL. Otherwise it is written with the innermost prefix
+// bound to its name space URL; an element name may also use the default
+// name space. If no suitable prefix is in scope, the Encoder declares
+// one as usual.
+func (enc *Encoder) PreservePrefixes() {
+	enc.p.preserve = true
+}
+
 // Encode writes the XML encoding of v to the stream.
 //
 // See the documentation for [Marshal] for details about the conversion
@@ -330,6 +346,13 @@ type printer struct {
 	tags       []Name
 	closed     bool
 	err        error
+
+	// In PreservePrefixes mode, scope holds the name space
+	// declarations in effect and qnames the prefixed names
+	// of the open elements.
+	preserve bool
+	scope    nsScope
+	qnames   []string
 }
 
 // createAttrPrefix finds the name space prefix attribute to use for the given name space,
@@ -353,22 +376,7 @@ func (p *printer) createAttrPrefix(url string) string {
 		p.attrNS = make(map[string]string)
 	}
 
-	// Pick a name. We try to use the final element of the path
-	// but fall back to _.
-	prefix := strings.TrimRight(url, "/")
-	if i := strings.LastIndex(prefix, "/"); i >= 0 {
-		prefix = prefix[i+1:]
-	}
-	if prefix == "" || !isName([]byte(prefix)) || strings.Contains(prefix, ":") {
-		prefix = "_"
-	}
-	// xmlanything is reserved and any variant of it regardless of
-	// case should be matched, so:
-	//    (('X'|'x') ('M'|'m') ('L'|'l'))
-	// See Section 2.3 of https://www.w3.org/TR/REC-xml/
-	if len(prefix) >= 3 && strings.EqualFold(prefix[:3], "xml") {
-		prefix = "_" + prefix
-	}
+	prefix := prefixName(url)
 	if p.attrNS[prefix] != "" {
 		// Name is taken. Find a better one.
 		for p.seq++; ; p.seq++ {
@@ -393,6 +401,27 @@ func (p *printer) createAttrPrefix(url string) string {
 	return prefix
 }
 
+// prefixName returns the prefix to try first for the given name space.
+func prefixName(url string) string {
+	// Pick a name. We try to use the final element of the path
+	// but fall back to _.
+	prefix := strings.TrimRight(url, "/")
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		prefix = prefix[i+1:]
+	}
+	if prefix == "" || !isName([]byte(prefix)) || strings.Contains(prefix, ":") {
+		prefix = "_"
+	}
+	// xmlanything is reserved and any variant of it regardless of
+	// case should be matched, so:
+	//    (('X'|'x') ('M'|'m') ('L'|'l'))
+	// See Section 2.3 of https://www.w3.org/TR/REC-xml/
+	if len(prefix) >= 3 && strings.EqualFold(prefix[:3], "xml") {
+		prefix = "_" + prefix
+	}
+	return prefix
+}
+
 // deleteAttrPrefix removes an attribute name space prefix.
 func (p *printer) deleteAttrPrefix(prefix string) {
 	delete(p.attrPrefix, p.attrNS[prefix])
@@ -720,6 +749,9 @@ func (p *printer) writeStart(start *StartElement) error {
 	if start.Name.Local == "" {
 		return fmt.Errorf("xml: start tag with no name")
 	}
+	if p.preserve {
+		return p.writeStartPrefixed(start)
+	}
 
 	p.tags = append(p.tags, start.Name)
 	p.markPrefix()
@@ -772,12 +804,105 @@ func (p *printer) writeEnd(name Name) error {
 	p.writeIndent(-1)
 	p.WriteByte('<')
 	p.WriteByte('/')
-	p.WriteString(name.Local)
+	if p.preserve {
+		p.WriteString(p.qnames[len(p.qnames)-1])
+		p.qnames = p.qnames[:len(p.qnames)-1]
+		p.scope.pop()
+	} else {
+		p.WriteString(name.Local)
+	}
 	p.WriteByte('>')
 	p.popPrefix()
 	return nil
 }
 
+// writeStartPrefixed is like writeStart but uses the name space
+// prefixes declared in start and its ancestors.
+func (p *printer) writeStartPrefixed(start *StartElement) error {
+	p.tags = append(p.tags, start.Name)
+	p.markPrefix()
+	p.scope.push()
+	for _, attr := range start.Attr {
+		if prefix, ok := nsDeclPrefix(attr.Name); ok {
+			p.scope.declare(prefix, attr.Value)
+		}
+	}
+
+	// Declarations needed in addition to those in start.Attr.
+	var decls []nsDecl
+	declare := func(prefix, url string) {
+		p.scope.declare(prefix, url)
+		decls = append(decls, nsDecl{prefix, url})
+	}
+
+	var qname string
+	if start.Name.Space == "" {
+		if url, _ := p.scope.lookup(""); url != "" {
+			declare("", "")
+		}
+		qname = start.Name.Local
+	} else {
+		prefix, ok := p.scope.prefixOf(start, 0, start.Name.Space, true)
+		if !ok {
+			prefix = ""
+			declare(prefix, start.Name.Space)
+		}
+		qname = joinPrefix(prefix, start.Name.Local)
+	}
+	p.qnames = append(p.qnames, qname)
+
+	attrs := make([]string, len(start.Attr))
+	for i, attr := range start.Attr {
+		name := attr.Name
+		if name.Local == "" {
+			continue
+		}
+		if prefix, ok := nsDeclPrefix(name); ok {
+			attrs[i] = nsDeclName(prefix)
+			continue
+		}
+		if name.Space == "" {
+			attrs[i] = name.Local
+			continue
+		}
+		prefix, ok := p.scope.prefixOf(start, 1+i, name.Space, false)
+		if !ok {
+			prefix = prefixName(name.Space)
+			for seq := 1; ; seq++ {
+				if _, taken := p.scope.lookup(prefix); !taken {
+					break
+				}
+				prefix = prefixName(name.Space) + "_" + strconv.Itoa(seq)
+			}
+			declare(prefix, name.Space)
+		}
+		attrs[i] = joinPrefix(prefix, name.Local)
+	}
+
+	p.writeIndent(1)
+	p.WriteByte('<')
+	p.WriteString(qname)
+	for _, d := range decls {
+		p.WriteByte(' ')
+		p.WriteString(nsDeclName(d.prefix))
+		p.WriteString(`="`)
+		p.EscapeString(d.url)
+		p.WriteByte('"')
+	}
+	for i, attr := range start.Attr {
+		if attrs[i] == "" {
+			continue
+		}
+		p.WriteByte(' ')
+		p.WriteString(attrs[i])
+		p.WriteString(`="`)
+		p.EscapeString(attr.Value)
+		p.WriteByte('"')
+	}
+	p.WriteByte('>')
+	return nil
+}
+
 func (p *printer) marshalSimple(typ reflect.Type, val reflect.Value) (string, []byte, error) {
 	switch val.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
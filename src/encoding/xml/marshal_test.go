@@ -1968,13 +1968,13 @@ var encodeTokenTests = []struct {
 }{{
 	desc: "start element with name space",
 	toks: []Token{
-		StartElement{Name{"space", "local"}, nil},
+		StartElement{Name: Name{"space", "local"}, Attr: nil},
 	},
 	want: `<local xmlns="space">`,
 }, {
 	desc: "start element with no name",
 	toks: []Token{
-		StartElement{Name{"space", ""}, nil},
+		StartElement{Name: Name{"space", ""}, Attr: nil},
 	},
 	err: "xml: start tag with no name",
 }, {
@@ -2052,7 +2052,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "mismatching end tag local name",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, nil},
+		StartElement{Name: Name{"", "foo"}, Attr: nil},
 		EndElement{Name{"", "bar"}},
 	},
 	err:  "xml: end tag </bar> does not match start tag <foo>",
@@ -2060,7 +2060,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "mismatching end tag namespace",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, nil},
+		StartElement{Name: Name{"space", "foo"}, Attr: nil},
 		EndElement{Name{"another", "foo"}},
 	},
 	err:  "xml: end tag </foo> in namespace another does not match start tag <foo> in namespace space",
@@ -2068,7 +2068,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "start element with explicit namespace",
 	toks: []Token{
-		StartElement{Name{"space", "local"}, []Attr{
+		StartElement{Name: Name{"space", "local"}, Attr: []Attr{
 			{Name{"xmlns", "x"}, "space"},
 			{Name{"space", "foo"}, "value"},
 		}},
@@ -2077,7 +2077,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "start element with explicit namespace and colliding prefix",
 	toks: []Token{
-		StartElement{Name{"space", "local"}, []Attr{
+		StartElement{Name: Name{"space", "local"}, Attr: []Attr{
 			{Name{"xmlns", "x"}, "space"},
 			{Name{"space", "foo"}, "value"},
 			{Name{"x", "bar"}, "other"},
@@ -2087,10 +2087,10 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "start element using previously defined namespace",
 	toks: []Token{
-		StartElement{Name{"", "local"}, []Attr{
+		StartElement{Name: Name{"", "local"}, Attr: []Attr{
 			{Name{"xmlns", "x"}, "space"},
 		}},
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"space", "x"}, "y"},
 		}},
 	},
@@ -2098,19 +2098,19 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "nested name space with same prefix",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"xmlns", "x"}, "space1"},
 		}},
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"xmlns", "x"}, "space2"},
 		}},
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"space1", "a"}, "space1 value"},
 			{Name{"space2", "b"}, "space2 value"},
 		}},
 		EndElement{Name{"", "foo"}},
 		EndElement{Name{"", "foo"}},
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"space1", "a"}, "space1 value"},
 			{Name{"space2", "b"}, "space2 value"},
 		}},
@@ -2119,7 +2119,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "start element defining several prefixes for the same name space",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"xmlns", "a"}, "space"},
 			{Name{"xmlns", "b"}, "space"},
 			{Name{"space", "x"}, "value"},
@@ -2129,10 +2129,10 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "nested element redefines name space",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"xmlns", "x"}, "space"},
 		}},
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"xmlns", "y"}, "space"},
 			{Name{"space", "a"}, "value"},
 		}},
@@ -2141,10 +2141,10 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "nested element creates alias for default name space",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"", "xmlns"}, "space"},
 		}},
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"xmlns", "y"}, "space"},
 			{Name{"space", "a"}, "value"},
 		}},
@@ -2153,10 +2153,10 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "nested element defines default name space with existing prefix",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"xmlns", "x"}, "space"},
 		}},
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"", "xmlns"}, "space"},
 			{Name{"space", "a"}, "value"},
 		}},
@@ -2165,10 +2165,10 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "nested element uses empty attribute name space when default ns defined",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"", "xmlns"}, "space"},
 		}},
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"", "attr"}, "value"},
 		}},
 	},
@@ -2176,7 +2176,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "redefine xmlns",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"foo", "xmlns"}, "space"},
 		}},
 	},
@@ -2184,7 +2184,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "xmlns with explicit name space #1",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"xml", "xmlns"}, "space"},
 		}},
 	},
@@ -2192,7 +2192,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "xmlns with explicit name space #2",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{xmlURL, "xmlns"}, "space"},
 		}},
 	},
@@ -2200,7 +2200,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "empty name space declaration is ignored",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"xmlns", "foo"}, ""},
 		}},
 	},
@@ -2208,7 +2208,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "attribute with no name is ignored",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"", ""}, "value"},
 		}},
 	},
@@ -2216,7 +2216,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "namespace URL with non-valid name",
 	toks: []Token{
-		StartElement{Name{"/34", "foo"}, []Attr{
+		StartElement{Name: Name{"/34", "foo"}, Attr: []Attr{
 			{Name{"/34", "x"}, "value"},
 		}},
 	},
@@ -2224,10 +2224,10 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "nested element resets default namespace to empty",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"", "xmlns"}, "space"},
 		}},
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"", "xmlns"}, ""},
 			{Name{"", "x"}, "value"},
 			{Name{"space", "x"}, "value"},
@@ -2237,16 +2237,16 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "nested element requires empty default name space",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"", "xmlns"}, "space"},
 		}},
-		StartElement{Name{"", "foo"}, nil},
+		StartElement{Name: Name{"", "foo"}, Attr: nil},
 	},
 	want: `<foo xmlns="space" xmlns="space"><foo>`,
 }, {
 	desc: "attribute uses name space from xmlns",
 	toks: []Token{
-		StartElement{Name{"some/space", "foo"}, []Attr{
+		StartElement{Name: Name{"some/space", "foo"}, Attr: []Attr{
 			{Name{"", "attr"}, "value"},
 			{Name{"some/space", "other"}, "other value"},
 		}},
@@ -2255,12 +2255,12 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "default name space should not be used by attributes",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"", "xmlns"}, "space"},
 			{Name{"xmlns", "bar"}, "space"},
 			{Name{"space", "baz"}, "foo"},
 		}},
-		StartElement{Name{"space", "baz"}, nil},
+		StartElement{Name: Name{"space", "baz"}, Attr: nil},
 		EndElement{Name{"space", "baz"}},
 		EndElement{Name{"space", "foo"}},
 	},
@@ -2268,11 +2268,11 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "default name space not used by attributes, not explicitly defined",
 	toks: []Token{
-		StartElement{Name{"space", "foo"}, []Attr{
+		StartElement{Name: Name{"space", "foo"}, Attr: []Attr{
 			{Name{"", "xmlns"}, "space"},
 			{Name{"space", "baz"}, "foo"},
 		}},
-		StartElement{Name{"space", "baz"}, nil},
+		StartElement{Name: Name{"space", "baz"}, Attr: nil},
 		EndElement{Name{"space", "baz"}},
 		EndElement{Name{"space", "foo"}},
 	},
@@ -2280,10 +2280,10 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "impossible xmlns declaration",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"", "xmlns"}, "space"},
 		}},
-		StartElement{Name{"space", "bar"}, []Attr{
+		StartElement{Name: Name{"space", "bar"}, Attr: []Attr{
 			{Name{"space", "attr"}, "value"},
 		}},
 	},
@@ -2291,7 +2291,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "reserved namespace prefix -- all lower case",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"http://www.w3.org/2001/xmlSchema-instance", "nil"}, "true"},
 		}},
 	},
@@ -2299,7 +2299,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "reserved namespace prefix -- all upper case",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"http://www.w3.org/2001/XMLSchema-instance", "nil"}, "true"},
 		}},
 	},
@@ -2307,7 +2307,7 @@ var encodeTokenTests = []struct {
 }, {
 	desc: "reserved namespace prefix -- all mixed case",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, []Attr{
+		StartElement{Name: Name{"", "foo"}, Attr: []Attr{
 			{Name{"http://www.w3.org/2001/XmLSchema-instance", "nil"}, "true"},
 		}},
 	},
@@ -2466,6 +2466,77 @@ func TestSimpleUseOfEncodeToken(t *testing.T) {
 	}
 }
 
+func TestEncoderPreservePrefixes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{{
+		in:   `<saml:Assertion xmlns:saml="urn:assertion" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" ID="a1"><ds:Signature><ds:SignedInfo ds:x="1"></ds:SignedInfo></ds:Signature></saml:Assertion>`,
+		want: `<saml:Assertion xmlns:saml="urn:assertion" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" ID="a1"><ds:Signature><ds:SignedInfo ds:x="1"></ds:SignedInfo></ds:Signature></saml:Assertion>`,
+	}, {
+		// The same name space bound to two prefixes: the innermost wins.
+		in:   `<a:root xmlns:a="urn:x"><b:child xmlns:b="urn:x"><b:leaf></b:leaf></b:child></a:root>`,
+		want: `<a:root xmlns:a="urn:x"><b:child xmlns:b="urn:x"><b:leaf></b:leaf></b:child></a:root>`,
+	}, {
+		in:   `<root xmlns="urn:d" xml:lang="en"><p:x xmlns:p="urn:p" p:a="1" b="2"></p:x><y xmlns=""></y></root>`,
+		want: `<root xmlns="urn:d" xml:lang="en"><p:x xmlns:p="urn:p" p:a="1" b="2"></p:x><y xmlns=""></y></root>`,
+	}, {
+		// The same name space bound to two prefixes in one element:
+		// each name keeps the prefix it was written with.
+		in:   `<p:a xmlns:p="urn:u" xmlns="urn:u"><b p:x="1"></b><p:c></p:c></p:a>`,
+		want: `<p:a xmlns:p="urn:u" xmlns="urn:u"><b p:x="1"></b><p:c></p:c></p:a>`,
+	}}
+	for _, tt := range tests {
+		d := NewDecoder(strings.NewReader(tt.in))
+		d.NormalizeAttrs = true
+		var buf strings.Builder
+		enc := NewEncoder(&buf)
+		enc.PreservePrefixes()
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Token: %v", err)
+			}
+			if err := enc.EncodeToken(tok); err != nil {
+				t.Fatalf("EncodeToken: %v", err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("round trip of %s:\ngot:  %s\nwant: %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEncoderPreservePrefixesDeclare(t *testing.T) {
+	var buf strings.Builder
+	enc := NewEncoder(&buf)
+	enc.PreservePrefixes()
+	toks := []Token{
+		StartElement{Name: Name{Space: "urn:a", Local: "x"}, Attr: []Attr{{Name: Name{Space: "http://example.com/b/", Local: "y"}, Value: "1"}}},
+		StartElement{Name: Name{Local: "z"}},
+		EndElement{Name: Name{Local: "z"}},
+		EndElement{Name: Name{Space: "urn:a", Local: "x"}},
+	}
+	for _, tok := range toks {
+		if err := enc.EncodeToken(tok); err != nil {
+			t.Fatalf("EncodeToken: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	want := `<x xmlns="urn:a" xmlns:b="http://example.com/b/" b:y="1"><z xmlns=""></z></x>`
+	if got := buf.String(); got != want {
+		t.Errorf("EncodeToken:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 // Issue 16158. Decoder.unmarshalAttr ignores the return value of copyValue.
 func TestIssue16158(t *testing.T) {
 	const data = `<foo b="HELLOWORLD"></foo>`
@@ -2540,14 +2611,14 @@ var closeTests = []struct {
 }{{
 	desc: "unclosed start element",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, nil},
+		StartElement{Name: Name{"", "foo"}, Attr: nil},
 	},
 	want: `<foo>`,
 	err:  "unclosed tag <foo>",
 }, {
 	desc: "closed element",
 	toks: []Token{
-		StartElement{Name{"", "foo"}, nil},
+		StartElement{Name: Name{"", "foo"}, Attr: nil},
 		EndElement{Name{"", "foo"}},
 	},
 	want: `<foo></foo>`,
@@ -16,6 +16,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
@@ -55,6 +56,11 @@ type Token any
 type StartElement struct {
 	Name Name
 	Attr []Attr
+
+	// prefixes holds the name space prefixes with which Name and
+	// each attribute of Attr, in order, were written in the input.
+	// It is set by a Decoder with NormalizeAttrs set.
+	prefixes []string
 }
 
 // Copy creates a new copy of StartElement.
@@ -62,6 +68,7 @@ func (e StartElement) Copy() StartElement {
 	attrs := make([]Attr, len(e.Attr))
 	copy(attrs, e.Attr)
 	e.Attr = attrs
+	e.prefixes = slices.Clone(e.prefixes)
 	return e
 }
 
@@ -197,6 +204,17 @@ type Decoder struct {
 	// the attribute xmlns="DefaultSpace".
 	DefaultSpace string
 
+	// NormalizeAttrs causes the parser to normalize attribute values
+	// as the XML specification requires: each tab, newline or carriage
+	// return in an attribute value is replaced by a space, unless it is
+	// written as a character reference. It also makes the returned
+	// StartElement tokens remember the name space prefixes written in
+	// the input, so that a [Canonicalizer] or an [Encoder] in
+	// [Encoder.PreservePrefixes] mode writes the same prefixes.
+	// The tokens passed to a Canonicalizer must be read with
+	// NormalizeAttrs set.
+	NormalizeAttrs bool
+
 	r              io.ByteReader
 	t              TokenReader
 	buf            bytes.Buffer
@@ -317,6 +335,14 @@ func (d *Decoder) Token() (Token, error) {
 			}
 		}
 
+		if d.NormalizeAttrs {
+			t1.prefixes = make([]string, 1+len(t1.Attr))
+			t1.prefixes[0] = t1.Name.Space
+			for i, a := range t1.Attr {
+				t1.prefixes[1+i] = a.Name.Space
+			}
+		}
+
 		d.pushElement(t1.Name)
 		d.translate(&t1.Name, true)
 		for i := range t1.Attr {
@@ -333,6 +359,19 @@ func (d *Decoder) Token() (Token, error) {
 	return t, err
 }
 
+// Namespaces returns the name space declarations in scope after the
+// most recent call to [Decoder.Token], mapping each declared prefix to
+// its name space URL. The default name space has the empty prefix.
+// After a [StartElement], the declarations made by that element are
+// included.
+func (d *Decoder) Namespaces() map[string]string {
+	m := make(map[string]string, len(d.ns))
+	for prefix, url := range d.ns {
+		m[prefix] = url
+	}
+	return m
+}
+
 const (
 	xmlURL      = "http://www.w3.org/XML/1998/namespace"
 	xmlnsPrefix = "xmlns"
@@ -847,7 +886,7 @@ func (d *Decoder) rawToken() (Token, error) {
 		d.needClose = true
 		d.toClose = name
 	}
-	return StartElement{name, attr}, nil
+	return StartElement{Name: name, Attr: attr}, nil
 }
 
 func (d *Decoder) attrval() []byte {
@@ -989,6 +1028,10 @@ var entity = map[string]rune{
 func (d *Decoder) text(quote int, cdata bool) []byte {
 	var b0, b1 byte
 	var trunc int
+	nl := byte('\n')
+	if quote >= 0 && d.NormalizeAttrs {
+		nl = ' '
+	}
 	d.buf.Reset()
 Input:
 	for {
@@ -1118,11 +1161,15 @@ Input:
 			return nil
 		}
 
-		// We must rewrite unescaped \r and \r\n into \n.
+		// We must rewrite unescaped \r and \r\n into \n,
+		// and unescaped white space in attribute values into
+		// spaces if normalizing them.
 		if b == '\r' {
-			d.buf.WriteByte('\n')
+			d.buf.WriteByte(nl)
 		} else if b1 == '\r' && b == '\n' {
 			// Skip \r\n--we already wrote \n.
+		} else if b == '\n' || b == '\t' && nl == ' ' {
+			d.buf.WriteByte(nl)
 		} else {
 			d.buf.WriteByte(b)
 		}
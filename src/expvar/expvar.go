@@ -7,6 +7,10 @@
 // /debug/vars in JSON format. As of Go 1.22, the /debug/vars request must
 // use GET.
 //
+// The numeric variables and the metrics of the runtime can also be
+// exposed in the OpenMetrics text format by [MetricsHandler], which is
+// not registered by default.
+//
 // Operations to set or modify these public variables are atomic.
 //
 // In addition to adding the HTTP handler, this package registers the
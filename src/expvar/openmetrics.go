@@ -0,0 +1,246 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package expvar
+
+import (
+	"math"
+	"net/http"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+)
+
+// MetricsHandler returns an HTTP Handler that exposes the metrics of
+// the runtime and the numeric exported variables in the OpenMetrics
+// text format, which is also understood by Prometheus. Unlike the JSON
+// handler at /debug/vars, it is not registered on
+// [http.DefaultServeMux]; to serve it, register it explicitly:
+//
+//	http.Handle("GET /debug/metrics", expvar.MetricsHandler())
+//
+// Each metric supported by [runtime/metrics] is exposed under a name
+// derived from its name and unit: the path components are joined by
+// underscores after a "go" prefix, followed by the unit, in which "/"
+// is written "per". For example, "/gc/heap/allocs:bytes" becomes
+// go_gc_heap_allocs_bytes and "/gc/heap/tiny/allocs:objects"
+// becomes go_gc_heap_tiny_allocs_objects. Other characters not allowed
+// in metric names are replaced by underscores. Cumulative metrics are
+// counters and other metrics are gauges. The help text of a metric is
+// its description.
+//
+// Metrics whose values are distributions, such as
+// "/sched/latencies:seconds", are omitted: an OpenMetrics histogram
+// has a sum of its samples, which the runtime does not record.
+//
+// Exported variables are exposed under their name, with characters
+// not allowed in metric names replaced by underscores, if they are an
+// [*Int], a [*Float], a [Func] returning a number, or a [*Map] whose
+// values are numbers, in which case each entry is labeled by its key.
+// Their type is unknown. Variables whose name conflicts with a metric
+// exposed earlier are omitted.
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(metricsHandler)
+}
+
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	w.Write(appendOpenMetrics(nil))
+}
+
+// appendOpenMetrics appends the metrics exposed by MetricsHandler to b.
+func appendOpenMetrics(b []byte) []byte {
+	seen := make(map[string]bool)
+
+	descs := metrics.All()
+	samples := make([]metrics.Sample, len(descs))
+	for i := range samples {
+		samples[i].Name = descs[i].Name
+	}
+	metrics.Read(samples)
+	for i, s := range samples {
+		d := &descs[i]
+		name := runtimeMetricName(d.Name)
+		if seen[name] {
+			continue
+		}
+		switch s.Value.Kind() {
+		case metrics.KindUint64:
+			b = appendFamily(b, name, counterOrGauge(d), d.Description)
+			b = appendSample(b, name, d.Cumulative, "", "", float64(s.Value.Uint64()))
+		case metrics.KindFloat64:
+			b = appendFamily(b, name, counterOrGauge(d), d.Description)
+			b = appendSample(b, name, d.Cumulative, "", "", s.Value.Float64())
+		default:
+			continue
+		}
+		seen[name] = true
+	}
+
+	vars.Do(func(kv KeyValue) {
+		name := sanitizeMetricName(kv.Key)
+		if seen[name] {
+			return
+		}
+		if f, ok := numericValue(kv.Value); ok {
+			seen[name] = true
+			b = appendFamily(b, name, "unknown", "")
+			b = appendSample(b, name, false, "", "", f)
+			return
+		}
+		m, ok := kv.Value.(*Map)
+		if !ok {
+			return
+		}
+		m.Do(func(kv KeyValue) {
+			f, ok := numericValue(kv.Value)
+			if !ok {
+				return
+			}
+			if !seen[name] {
+				seen[name] = true
+				b = appendFamily(b, name, "unknown", "")
+			}
+			b = appendSample(b, name, false, "key", kv.Key, f)
+		})
+	})
+
+	return append(b, "# EOF\n"...)
+}
+
+func counterOrGauge(d *metrics.Description) string {
+	if d.Cumulative {
+		return "counter"
+	}
+	return "gauge"
+}
+
+// runtimeMetricName returns the OpenMetrics name of the runtime metric
+// with the given name.
+func runtimeMetricName(name string) string {
+	path, unit, _ := strings.Cut(name, ":")
+	unit = strings.ReplaceAll(unit, "/", "_per_")
+	return sanitizeMetricName("go" + strings.ReplaceAll(path, "/", "_") + "_" + unit)
+}
+
+// sanitizeMetricName replaces the characters of name that are not
+// allowed in metric names by underscores.
+func sanitizeMetricName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == ':' || i > 0 && '0' <= c && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// numericValue returns the value of v as a float64,
+// if v is a number.
+func numericValue(v Var) (float64, bool) {
+	switch v := v.(type) {
+	case *Int:
+		return float64(v.Value()), true
+	case *Float:
+		return v.Value(), true
+	case Func:
+		switch x := v.Value().(type) {
+		case int:
+			return float64(x), true
+		case int8:
+			return float64(x), true
+		case int16:
+			return float64(x), true
+		case int32:
+			return float64(x), true
+		case int64:
+			return float64(x), true
+		case uint:
+			return float64(x), true
+		case uint8:
+			return float64(x), true
+		case uint16:
+			return float64(x), true
+		case uint32:
+			return float64(x), true
+		case uint64:
+			return float64(x), true
+		case uintptr:
+			return float64(x), true
+		case float32:
+			return float64(x), true
+		case float64:
+			return x, true
+		}
+	}
+	return 0, false
+}
+
+// appendFamily appends the metadata of a metric family to b.
+func appendFamily(b []byte, name, typ, help string) []byte {
+	b = append(b, "# TYPE "...)
+	b = append(b, name...)
+	b = append(b, ' ')
+	b = append(b, typ...)
+	b = append(b, '\n')
+	if help != "" {
+		b = append(b, "# HELP "...)
+		b = append(b, name...)
+		b = append(b, ' ')
+		b = appendEscaped(b, help, false)
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// appendSample appends a sample of metric name to b. The sample of a
+// counter has the suffix _total. If label is not empty, the sample has
+// a label with the given value.
+func appendSample(b []byte, name string, counter bool, label, value string, f float64) []byte {
+	b = append(b, name...)
+	if counter {
+		b = append(b, "_total"...)
+	}
+	if label != "" {
+		b = append(b, '{')
+		b = append(b, label...)
+		b = append(b, `="`...)
+		b = appendEscaped(b, value, true)
+		b = append(b, `"}`...)
+	}
+	b = append(b, ' ')
+	b = appendFloat(b, f)
+	return append(b, '\n')
+}
+
+// appendFloat appends f to b in the format of OpenMetrics numbers.
+func appendFloat(b []byte, f float64) []byte {
+	switch {
+	case math.IsInf(f, +1):
+		return append(b, "+Inf"...)
+	case math.IsInf(f, -1):
+		return append(b, "-Inf"...)
+	case math.IsNaN(f):
+		return append(b, "NaN"...)
+	}
+	return strconv.AppendFloat(b, f, 'g', -1, 64)
+}
+
+// appendEscaped appends s to b, escaping backslashes and newlines,
+// and double quotes if quote is set.
+func appendEscaped(b []byte, s string, quote bool) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			b = append(b, `\\`...)
+		case c == '\n':
+			b = append(b, `\n`...)
+		case c == '"' && quote:
+			b = append(b, `\"`...)
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
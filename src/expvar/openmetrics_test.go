@@ -0,0 +1,101 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package expvar
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"regexp"
+	"runtime/metrics"
+	"strings"
+	"testing"
+)
+
+func TestRuntimeMetricName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"/gc/heap/allocs:bytes", "go_gc_heap_allocs_bytes"},
+		{"/gc/heap/tiny/allocs:objects", "go_gc_heap_tiny_allocs_objects"},
+		{"/cpu/classes/gc/mark/assist:cpu-seconds", "go_cpu_classes_gc_mark_assist_cpu_seconds"},
+		{"/gc/gogc:percent", "go_gc_gogc_percent"},
+		{"/a/b:bytes/second", "go_a_b_bytes_per_second"},
+		{"/a/b:byte*cpu-seconds", "go_a_b_byte_cpu_seconds"},
+	}
+	for _, tt := range tests {
+		if got := runtimeMetricName(tt.name); got != tt.want {
+			t.Errorf("runtimeMetricName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	RemoveAll()
+	NewInt("requests").Set(42)
+	NewFloat("load.avg").Set(0.5)
+	NewString("version").Set("v1")
+	m := NewMap("hits")
+	m.Add("a", 1)
+	m.Add(`b"c`, 2)
+	m.Set("s", new(String))
+	Publish("answer", Func(func() any { return uint8(7) }))
+	Publish("list", Func(func() any { return []int{1} }))
+	defer RemoveAll()
+
+	rr := httptest.NewRecorder()
+	rr.Body = new(bytes.Buffer)
+	MetricsHandler().ServeHTTP(rr, nil)
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text;") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	got := rr.Body.String()
+	if !strings.HasSuffix(got, "\n# EOF\n") {
+		t.Errorf("output does not end with # EOF")
+	}
+
+	for _, want := range []string{
+		"# TYPE requests unknown\nrequests 42\n",
+		"# TYPE load_avg unknown\nload_avg 0.5\n",
+		"# TYPE hits unknown\nhits{key=\"a\"} 1\nhits{key=\"b\\\"c\"} 2\n",
+		"# TYPE answer unknown\nanswer 7\n",
+		"# TYPE go_gc_heap_allocs_bytes counter\n# HELP go_gc_heap_allocs_bytes Cumulative sum of memory allocated to the heap by the application.\ngo_gc_heap_allocs_bytes_total ",
+		"# TYPE go_gc_gogc_percent gauge\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"version", "list", "memstats", "hits{key=\"s\"}"} {
+		if strings.Contains(got, "# TYPE "+unwanted+" ") || strings.Contains(got, "\n"+unwanted+" ") {
+			t.Errorf("output contains %q", unwanted)
+		}
+	}
+
+	// Every line is metadata or a valid sample, and every runtime
+	// metric is present, except histograms.
+	sample := regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*(\{[a-z]+="([^"\\]|\\.)*"\})? ([-+]?[0-9.e+-]+|[-+]Inf|NaN)$`)
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		if !strings.HasPrefix(line, "# ") && !sample.MatchString(line) {
+			t.Errorf("invalid line %q", line)
+		}
+	}
+	for _, d := range metrics.All() {
+		name := runtimeMetricName(d.Name)
+		switch d.Kind {
+		case metrics.KindBad:
+		case metrics.KindFloat64Histogram:
+			if strings.Contains(got, "# TYPE "+name+" ") {
+				t.Errorf("output has histogram %s for %s", name, d.Name)
+			}
+		default:
+			if !strings.Contains(got, "# TYPE "+name+" ") {
+				t.Errorf("output lacks %s for %s", name, d.Name)
+			}
+		}
+	}
+	if regexp.MustCompile(`(?m)^# TYPE \S+ (gauge)?histogram$`).MatchString(got) {
+		t.Errorf("output contains a histogram")
+	}
+}
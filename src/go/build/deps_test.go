@@ -43,7 +43,7 @@ var depsRules = `
 	  internal/cfg, internal/coverage, internal/coverage/rtcov,
 	  internal/coverage/uleb128, internal/coverage/calloc,
 	  internal/cpu, internal/goarch, internal/godebugs,
-	  internal/goexperiment, internal/goos,
+	  internal/goexperiment, internal/goos, internal/runtime/cgroup,
 	  internal/goversion, internal/nettrace, internal/platform,
 	  internal/trace/traceviewer/format,
 	  log/internal,
@@ -64,7 +64,8 @@ var depsRules = `
 	internal/goarch,
 	internal/godebugs,
 	internal/goexperiment,
-	internal/goos
+	internal/goos,
+	internal/runtime/cgroup
 	< internal/bytealg
 	< internal/itoa
 	< internal/unsafeheader
@@ -208,6 +209,7 @@ var depsRules = `
 	< html,
 	  internal/dag,
 	  internal/goroot,
+	  internal/structfield,
 	  internal/types/errors,
 	  mime/quotedprintable,
 	  net/internal/socktest,
@@ -232,7 +234,8 @@ var depsRules = `
 
 	fmt !< encoding/base32, encoding/base64;
 
-	FMT, encoding/base32, encoding/base64, internal/saferio
+	FMT, encoding/base32, encoding/base64, internal/saferio,
+	internal/structfield
 	< encoding/ascii85, encoding/csv, encoding/gob, encoding/hex,
 	  encoding/json, encoding/pem, encoding/xml, mime;
 
@@ -321,7 +324,9 @@ var depsRules = `
 	# databases
 	FMT
 	< database/sql/internal
-	< database/sql/driver
+	< database/sql/driver;
+
+	database/sql/driver, internal/structfield, iter
 	< database/sql;
 
 	# images
@@ -405,9 +410,6 @@ var depsRules = `
 
 	log, log/slog !< crypto/tls, database/sql, go/importer, testing;
 
-	FMT, log, net
-	< log/syslog;
-
 	RUNTIME
 	< log/slog/internal, log/slog/internal/buffer;
 
@@ -514,6 +516,9 @@ var depsRules = `
 	crypto/tls
 	< net/smtp;
 
+	crypto/tls, log/slog
+	< log/syslog;
+
 	crypto/rand
 	< hash/maphash; # for purego implementation
 
@@ -548,7 +553,7 @@ var depsRules = `
 
 	# HTTP-aware packages
 
-	encoding/json, net/http
+	encoding/json, net/http, runtime/metrics
 	< expvar;
 
 	net/http, net/http/internal/ascii
@@ -223,6 +223,14 @@ func (cdr *CounterDataReader) Goarch() string {
 	return cdr.goarch
 }
 
+// Test returns the import path of the package and the name of the
+// top-level test whose counter increments are recorded in this
+// counter data file, as written by "go test -coverpertest". Both
+// are empty for counter data files not produced by a single test.
+func (cdr *CounterDataReader) Test() (pkg, name string) {
+	return cdr.args["testpkg"], cdr.args["testname"]
+}
+
 // FuncPayload encapsulates the counter data payload for a single
 // function as read from a counter data file.
 type FuncPayload struct {
@@ -311,12 +311,12 @@ type CounterFileHeader struct {
 // The "args" section of a segment is used to store annotations
 // describing where the counter data came from; this section is
 // basically a series of key-value pairs (can be thought of as an
-// encoded 'map[string]string'). At the moment we only write os.Args()
-// data to this section, using pairs of the form "argc=<integer>",
-// "argv0=<os.Args[0]>", "argv1=<os.Args[1]>", and so on. In the
-// future the args table may also include things like GOOS/GOARCH
-// values, and/or tags indicating which tests were run to generate the
-// counter data.
+// encoded 'map[string]string'). We write os.Args() data to this
+// section, using pairs of the form "argc=<integer>",
+// "argv0=<os.Args[0]>", "argv1=<os.Args[1]>", and so on, along with
+// the GOOS and GOARCH values. Counter data files written for a single
+// test by "go test -coverpertest" also record the test's package and
+// name, as "testpkg=<import path>" and "testname=<test name>".
 type CounterSegmentHeader struct {
 	FcnEntries uint64
 	StrTabLen  uint32
@@ -55,6 +55,7 @@ var rtPkgs = [...]string{
 	"runtime/internal/math",
 	"internal/bytealg",
 	"internal/goexperiment",
+	"internal/runtime/cgroup",
 	"internal/runtime/syscall",
 	"runtime",
 }
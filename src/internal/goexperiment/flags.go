@@ -127,4 +127,7 @@ type Flags struct {
 	// ExecTracer2 controls whether to use the new execution trace
 	// implementation.
 	ExecTracer2 bool
+
+	// SwissMap enables the Swiss table map implementation in the runtime.
+	SwissMap bool
 }
@@ -0,0 +1,327 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cgroup finds the CPU bandwidth limit of the Linux cgroup of
+// the current process, for the runtime.
+//
+// Finding the cgroup allocates, but reading its limit only uses a
+// buffer provided by the caller, so that the runtime can check it
+// where it cannot allocate, such as in sysmon.
+package cgroup
+
+// A ReadFunc reads the file at path, which is NUL-terminated, into buf.
+// It returns the number of bytes read, which is len(buf) if the file
+// is larger, or -1 if the file cannot be read.
+type ReadFunc func(path []byte, buf []byte) int
+
+// A CPU locates the files limiting the CPU bandwidth of a cgroup and
+// of its ancestors.
+type CPU struct {
+	v1   bool
+	read ReadFunc
+
+	// files are the NUL-terminated paths of the cpu.max files of
+	// the cgroup and its ancestors for cgroup v2, or of their
+	// cpu.cfs_quota_us and cpu.cfs_period_us files, in pairs, for
+	// cgroup v1.
+	files [][]byte
+}
+
+// maxFileSize is the maximum size of the /proc files FindCPU reads.
+const maxFileSize = 1 << 20
+
+// FindCPU finds the cgroup controlling the CPU bandwidth of the current
+// process from /proc/self/cgroup and /proc/self/mountinfo. root is
+// prepended to the paths of all files, and is empty except in tests.
+// It reports whether the process has such a cgroup.
+func FindCPU(root string, read ReadFunc) (CPU, bool) {
+	cgroups := readFile(read, root+"/proc/self/cgroup")
+	if cgroups == nil {
+		return CPU{}, false
+	}
+	path, v1, ok := parseCgroupPath(cgroups)
+	if !ok {
+		return CPU{}, false
+	}
+	mountinfo := readFile(read, root+"/proc/self/mountinfo")
+	if mountinfo == nil {
+		return CPU{}, false
+	}
+	mount, dir, ok := parseMountInfo(mountinfo, path, v1)
+	if !ok {
+		return CPU{}, false
+	}
+
+	// The limit of a cgroup is the most restrictive one of it and
+	// its ancestors, up to the root of the mounted hierarchy.
+	c := CPU{v1: v1, read: read}
+	for {
+		d := root + dir
+		if v1 {
+			c.files = append(c.files, cstring(d+"/cpu.cfs_quota_us"), cstring(d+"/cpu.cfs_period_us"))
+		} else {
+			c.files = append(c.files, cstring(d+"/cpu.max"))
+		}
+		if len(dir) <= len(mount) {
+			break
+		}
+		dir = parentDir(dir)
+	}
+	return c, true
+}
+
+// Limit returns the CPU bandwidth limit of the cgroup, in CPUs, and
+// whether it is limited. buf is used to read the limit files, and
+// should be at least 64 bytes long.
+func (c *CPU) Limit(buf []byte) (limit float64, ok bool) {
+	if c.v1 {
+		for i := 0; i+1 < len(c.files); i += 2 {
+			quota, qok := readInt(c.read, c.files[i], buf)
+			period, pok := readInt(c.read, c.files[i+1], buf)
+			if !qok || !pok || quota < 0 || period <= 0 {
+				// -1 means no limit.
+				continue
+			}
+			if l := float64(quota) / float64(period); !ok || l < limit {
+				limit, ok = l, true
+			}
+		}
+		return limit, ok
+	}
+	for _, f := range c.files {
+		n := c.read(f, buf)
+		if n <= 0 {
+			continue
+		}
+		l, lok := parseCPUMax(buf[:n])
+		if lok && (!ok || l < limit) {
+			limit, ok = l, true
+		}
+	}
+	return limit, ok
+}
+
+// parseCPUMax parses the contents of a cgroup v2 cpu.max file,
+// "$MAX $PERIOD", where $MAX is "max" when unlimited.
+func parseCPUMax(b []byte) (float64, bool) {
+	quota, rest := field(b)
+	period, _ := field(rest)
+	if string(quota) == "max" {
+		return 0, false
+	}
+	q, qok := atoi(quota)
+	p, pok := atoi(period)
+	if !qok || !pok || p <= 0 {
+		return 0, false
+	}
+	return float64(q) / float64(p), true
+}
+
+// parseCgroupPath returns the path of the cgroup controlling the CPU
+// of the process, from the contents of /proc/self/cgroup, with lines
+// of the form "hierarchy-ID:controllers:path". The cgroup v1 hierarchy
+// with the cpu controller takes precedence over the unified cgroup v2
+// hierarchy, whose ID is 0 and which has no controllers listed.
+func parseCgroupPath(b []byte) (path string, v1, ok bool) {
+	for len(b) > 0 {
+		var line []byte
+		line, b = nextLine(b)
+		id, rest, found := cut(line, ':')
+		if !found {
+			continue
+		}
+		controllers, p, found := cut(rest, ':')
+		if !found {
+			continue
+		}
+		if hasListElem(controllers, ',', "cpu") {
+			return string(p), true, true
+		}
+		if string(id) == "0" && len(controllers) == 0 {
+			path, ok = string(p), true
+		}
+	}
+	return path, false, ok
+}
+
+// parseMountInfo finds the mount point of the cgroup hierarchy in the
+// contents of /proc/self/mountinfo, and the directory of the cgroup
+// with the given path. Its lines have the form
+//
+//	36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+//
+// where /mnt1 is the root of the mount within the file system, /mnt2
+// its mount point, ext3 the file system type and rw,errors=continue
+// its super options.
+func parseMountInfo(b []byte, path string, v1 bool) (mount, dir string, ok bool) {
+	for len(b) > 0 {
+		var line []byte
+		line, b = nextLine(b)
+		var fields [6][]byte
+		rest := line
+		for i := range fields {
+			fields[i], rest = field(rest)
+		}
+		// Skip the optional fields.
+		var f []byte
+		for {
+			f, rest = field(rest)
+			if len(f) == 0 || string(f) == "-" {
+				break
+			}
+		}
+		fstype, rest := field(rest)
+		_, rest = field(rest) // source
+		superOptions, _ := field(rest)
+		if v1 {
+			if string(fstype) != "cgroup" || !hasListElem(superOptions, ',', "cpu") {
+				continue
+			}
+		} else if string(fstype) != "cgroup2" {
+			continue
+		}
+
+		mountRoot := unescape(fields[3])
+		mount = unescape(fields[4])
+		// path is relative to the root of the hierarchy, and the
+		// mount may only expose a part of it.
+		switch {
+		case mountRoot == "/":
+			dir = mount + path
+		case path == mountRoot:
+			dir = mount
+		case len(path) > len(mountRoot) && path[:len(mountRoot)] == mountRoot && path[len(mountRoot)] == '/':
+			dir = mount + path[len(mountRoot):]
+		default:
+			continue
+		}
+		if len(dir) > 1 && dir[len(dir)-1] == '/' {
+			dir = dir[:len(dir)-1]
+		}
+		return mount, dir, true
+	}
+	return "", "", false
+}
+
+// readFile reads the file at path into a new buffer, or returns nil.
+func readFile(read ReadFunc, path string) []byte {
+	p := cstring(path)
+	for size := 4096; size <= maxFileSize; size *= 2 {
+		buf := make([]byte, size)
+		n := read(p, buf)
+		if n < 0 {
+			return nil
+		}
+		if n < len(buf) {
+			return buf[:n]
+		}
+	}
+	return nil
+}
+
+// readInt reads the decimal integer in the file at path.
+func readInt(read ReadFunc, path, buf []byte) (int64, bool) {
+	n := read(path, buf)
+	if n <= 0 {
+		return 0, false
+	}
+	f, _ := field(buf[:n])
+	return atoi(f)
+}
+
+func cstring(s string) []byte {
+	b := make([]byte, len(s)+1)
+	copy(b, s)
+	return b
+}
+
+// parentDir returns the parent directory of the absolute path dir.
+func parentDir(dir string) string {
+	for i := len(dir) - 1; i > 0; i-- {
+		if dir[i] == '/' {
+			return dir[:i]
+		}
+	}
+	return "/"
+}
+
+// unescape decodes the octal escapes, such as \040 for a space, of a
+// path in /proc/self/mountinfo.
+func unescape(b []byte) string {
+	var out []byte
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c == '\\' && i+3 < len(b) && isOctal(b[i+1]) && isOctal(b[i+2]) && isOctal(b[i+3]) {
+			c = (b[i+1]-'0')<<6 | (b[i+2]-'0')<<3 | (b[i+3] - '0')
+			i += 3
+		}
+		out = append(out, c)
+	}
+	return string(out)
+}
+
+func isOctal(c byte) bool {
+	return '0' <= c && c <= '7'
+}
+
+// nextLine returns the first line of b and the rest of b.
+func nextLine(b []byte) (line, rest []byte) {
+	line, rest, _ = cut(b, '\n')
+	return line, rest
+}
+
+// field returns the first space-separated field of b and the rest of b.
+func field(b []byte) (f, rest []byte) {
+	for len(b) > 0 && (b[0] == ' ' || b[0] == '\t' || b[0] == '\n') {
+		b = b[1:]
+	}
+	i := 0
+	for i < len(b) && b[i] != ' ' && b[i] != '\t' && b[i] != '\n' {
+		i++
+	}
+	return b[:i], b[i:]
+}
+
+func cut(b []byte, sep byte) (before, after []byte, found bool) {
+	for i, c := range b {
+		if c == sep {
+			return b[:i], b[i+1:], true
+		}
+	}
+	return b, nil, false
+}
+
+// hasListElem reports whether the sep-separated list contains elem.
+func hasListElem(list []byte, sep byte, elem string) bool {
+	for len(list) > 0 {
+		var e []byte
+		e, list, _ = cut(list, sep)
+		if string(e) == elem {
+			return true
+		}
+	}
+	return false
+}
+
+func atoi(b []byte) (int64, bool) {
+	neg := false
+	if len(b) > 0 && b[0] == '-' {
+		neg = true
+		b = b[1:]
+	}
+	if len(b) == 0 || len(b) > 18 {
+		return 0, false
+	}
+	var n int64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int64(c-'0')
+	}
+	if neg {
+		n = -n
+	}
+	return n, true
+}
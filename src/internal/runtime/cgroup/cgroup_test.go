@@ -0,0 +1,179 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cgroup_test
+
+import (
+	"internal/runtime/cgroup"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFile implements cgroup.ReadFunc with the os package.
+func readFile(path []byte, buf []byte) int {
+	data, err := os.ReadFile(string(path[:len(path)-1]))
+	if err != nil {
+		return -1
+	}
+	return copy(buf, data)
+}
+
+// writeTree creates the files of a synthetic file system under a
+// temporary directory, and returns it.
+func writeTree(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+const v2MountInfo = `22 1 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw
+30 22 0:26 / /sys/fs/cgroup rw,nosuid,nodev,noexec,relatime shared:4 - cgroup2 cgroup2 rw,nsdelegate,memory_recursiveprot
+`
+
+const v1MountInfo = `22 1 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw
+33 25 0:29 / /sys/fs/cgroup/memory rw,nosuid,nodev,noexec,relatime shared:9 - cgroup cgroup rw,memory
+34 25 0:30 / /sys/fs/cgroup/cpu,cpuacct rw,nosuid,nodev,noexec,relatime shared:10 - cgroup cgroup rw,cpu,cpuacct
+`
+
+func TestCPULimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		found     bool
+		limit     float64
+		wantLimit bool
+	}{
+		{
+			name: "v2",
+			files: map[string]string{
+				"/proc/self/cgroup":                        "0::/kubepods/pod1/ctr\n",
+				"/proc/self/mountinfo":                     v2MountInfo,
+				"/sys/fs/cgroup/kubepods/pod1/ctr/cpu.max": "200000 100000\n",
+				"/sys/fs/cgroup/kubepods/pod1/cpu.max":     "max 100000\n",
+			},
+			found:     true,
+			limit:     2,
+			wantLimit: true,
+		},
+		{
+			name: "v2-ancestor",
+			files: map[string]string{
+				"/proc/self/cgroup":                        "0::/kubepods/pod1/ctr\n",
+				"/proc/self/mountinfo":                     v2MountInfo,
+				"/sys/fs/cgroup/kubepods/pod1/ctr/cpu.max": "max 100000\n",
+				"/sys/fs/cgroup/kubepods/pod1/cpu.max":     "150000 100000\n",
+				"/sys/fs/cgroup/kubepods/cpu.max":          "400000 100000\n",
+			},
+			found:     true,
+			limit:     1.5,
+			wantLimit: true,
+		},
+		{
+			name: "v2-unlimited",
+			files: map[string]string{
+				"/proc/self/cgroup":                 "0::/user.slice\n",
+				"/proc/self/mountinfo":              v2MountInfo,
+				"/sys/fs/cgroup/user.slice/cpu.max": "max 100000\n",
+			},
+			found: true,
+		},
+		{
+			name: "v2-namespace",
+			files: map[string]string{
+				"/proc/self/cgroup":      "0::/\n",
+				"/proc/self/mountinfo":   v2MountInfo,
+				"/sys/fs/cgroup/cpu.max": "50000 100000\n",
+			},
+			found:     true,
+			limit:     0.5,
+			wantLimit: true,
+		},
+		{
+			name: "v1",
+			files: map[string]string{
+				"/proc/self/cgroup": "12:memory:/docker/abc\n" +
+					"4:cpu,cpuacct:/docker/abc\n" +
+					"0::/\n",
+				"/proc/self/mountinfo": v1MountInfo,
+				"/sys/fs/cgroup/cpu,cpuacct/docker/abc/cpu.cfs_quota_us":  "300000\n",
+				"/sys/fs/cgroup/cpu,cpuacct/docker/abc/cpu.cfs_period_us": "100000\n",
+				"/sys/fs/cgroup/cpu,cpuacct/docker/cpu.cfs_quota_us":      "-1\n",
+				"/sys/fs/cgroup/cpu,cpuacct/docker/cpu.cfs_period_us":     "100000\n",
+			},
+			found:     true,
+			limit:     3,
+			wantLimit: true,
+		},
+		{
+			name: "v1-mount-root",
+			files: map[string]string{
+				"/proc/self/cgroup":                         "4:cpu,cpuacct:/docker/abc\n",
+				"/proc/self/mountinfo":                      "34 25 0:30 /docker/abc /sys/fs/cgroup/cpu\\040acct rw - cgroup cgroup rw,cpuacct,cpu\n",
+				"/sys/fs/cgroup/cpu acct/cpu.cfs_quota_us":  "50000\n",
+				"/sys/fs/cgroup/cpu acct/cpu.cfs_period_us": "100000\n",
+			},
+			found:     true,
+			limit:     0.5,
+			wantLimit: true,
+		},
+		{
+			name: "no-cgroup-mount",
+			files: map[string]string{
+				"/proc/self/cgroup":    "0::/\n",
+				"/proc/self/mountinfo": "22 1 0:21 / /proc rw - proc proc rw\n",
+			},
+		},
+		{
+			name:  "no-proc",
+			files: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeTree(t, tt.files)
+			cpu, found := cgroup.FindCPU(root, readFile)
+			if found != tt.found {
+				t.Fatalf("FindCPU found %v, want %v", found, tt.found)
+			}
+			if !found {
+				return
+			}
+			limit, ok := cpu.Limit(make([]byte, 64))
+			if ok != tt.wantLimit || limit != tt.limit {
+				t.Errorf("Limit() = %v, %v, want %v, %v", limit, ok, tt.limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestCPULimitChange(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/proc/self/cgroup":      "0::/\n",
+		"/proc/self/mountinfo":   v2MountInfo,
+		"/sys/fs/cgroup/cpu.max": "max 100000\n",
+	})
+	cpu, found := cgroup.FindCPU(root, readFile)
+	if !found {
+		t.Fatal("FindCPU found no cgroup")
+	}
+	buf := make([]byte, 64)
+	if limit, ok := cpu.Limit(buf); ok {
+		t.Errorf("Limit() = %v, true, want no limit", limit)
+	}
+	if err := os.WriteFile(filepath.Join(root, "/sys/fs/cgroup/cpu.max"), []byte("400000 100000\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if limit, ok := cpu.Limit(buf); !ok || limit != 4 {
+		t.Errorf("Limit() = %v, %v, want 4, true", limit, ok)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package structfield maps named columns to the fields of struct
+// types, for the struct support of encoding/csv and database/sql.
+package structfield
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// A Field describes a struct field that a column maps to.
+type Field struct {
+	Name  string // column name, from the struct tag or the field name
+	Field string // Go field name
+	Index []int
+	Type  reflect.Type
+}
+
+// A Cache computes and caches the fields of struct types.
+type Cache struct {
+	// Tag is the key of the struct tags naming the columns.
+	Tag string
+
+	// Opaque, if not nil, reports whether an embedded struct type is
+	// treated as a single field instead of promoting its fields.
+	Opaque func(reflect.Type) bool
+
+	m sync.Map // map[reflect.Type][]Field
+}
+
+// Fields returns the fields of struct type t that columns map to, in
+// field order.
+//
+// A field maps to the column named by its struct tag, or by its name if
+// it has no tag. Fields tagged "-" and unexported fields are ignored.
+// Fields of embedded structs without a tag are promoted as in Go, unless
+// the struct is opaque; if several fields at the shallowest depth share
+// a name, none of them is used.
+func (c *Cache) Fields(t reflect.Type) []Field {
+	if f, ok := c.m.Load(t); ok {
+		return f.([]Field)
+	}
+
+	var fields []Field
+	var opaque [][]int // indexes of opaque embedded structs
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || slices.ContainsFunc(opaque, func(index []int) bool {
+			return len(sf.Index) > len(index) && slices.Equal(sf.Index[:len(index)], index)
+		}) {
+			continue
+		}
+		tag := sf.Tag.Get(c.Tag)
+		if tag == "-" {
+			continue
+		}
+		if sf.Anonymous && tag == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if c.Opaque == nil || !c.Opaque(ft) {
+					// Its fields are visited separately.
+					continue
+				}
+				opaque = append(opaque, sf.Index)
+			}
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, Field{
+			Name:  name,
+			Field: sf.Name,
+			Index: sf.Index,
+			Type:  sf.Type,
+		})
+	}
+
+	// Keep only the shallowest field for each name,
+	// and drop names that are ambiguous at that depth.
+	depth := make(map[string]int)
+	count := make(map[string]int)
+	for _, f := range fields {
+		d, ok := depth[f.Name]
+		switch {
+		case !ok || len(f.Index) < d:
+			depth[f.Name] = len(f.Index)
+			count[f.Name] = 1
+		case len(f.Index) == d:
+			count[f.Name]++
+		}
+	}
+	fields = slices.DeleteFunc(fields, func(f Field) bool {
+		return len(f.Index) != depth[f.Name] || count[f.Name] > 1
+	})
+
+	f, _ := c.m.LoadOrStore(t, fields)
+	return f.([]Field)
+}
+
+// ByIndex is like [reflect.Value.FieldByIndex] but allocates nil
+// embedded struct pointers.
+func ByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct: %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package structfield
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type inner struct {
+	A, B, C int
+}
+
+type Opaque struct {
+	X, Y int
+}
+
+type other struct {
+	B int
+}
+
+type T struct {
+	inner
+	*other
+	Opaque
+	C int `col:"A"`
+	D int `col:"dee,omitempty"`
+	E int `col:"-"`
+	f int
+}
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		opaque func(reflect.Type) bool
+		want   []string
+	}{
+		// inner.A is hidden by the shallower tagged C, B is ambiguous.
+		{nil, []string{"X:X", "Y:Y", "C:A", "D:dee"}},
+		{
+			func(t reflect.Type) bool { return t == reflect.TypeFor[Opaque]() },
+			[]string{"Opaque:Opaque", "C:A", "D:dee"},
+		},
+	}
+	for _, tt := range tests {
+		c := &Cache{Tag: "col", Opaque: tt.opaque}
+		var names []string
+		for _, f := range c.Fields(reflect.TypeFor[T]()) {
+			names = append(names, f.Field+":"+f.Name)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("Fields: got %q, want %q", names, tt.want)
+		}
+	}
+}
+
+func TestByIndex(t *testing.T) {
+	type Inner struct{ A int }
+	type Outer struct{ *Inner }
+	var v Outer
+	f, err := ByIndex(reflect.ValueOf(&v).Elem(), []int{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetInt(1)
+	if v.Inner == nil || v.A != 1 {
+		t.Errorf("ByIndex did not set the field through the embedded pointer: %+v", v)
+	}
+
+	var w T
+	if _, err := ByIndex(reflect.ValueOf(&w).Elem(), []int{1, 0}); err == nil {
+		t.Errorf("ByIndex through a nil pointer to an unexported struct succeeded")
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package trace reads the execution traces produced by [runtime/trace],
+by the -trace flag of go test and by the /debug/pprof/trace endpoint of
+[net/http/pprof].
+
+A [Reader] validates a trace and returns its events in order, one at a
+time, so that programs can analyze traces too large to fit in memory.
+Each [Event] has a kind, a timestamp, and the goroutine, proc and thread
+it happened on; according to its kind, it describes a state transition
+of a goroutine or a proc, such as a goroutine blocking in a syscall, a
+metric sample, such as the heap size, a range of time, such as a GC
+cycle, or a user annotation: a task, a region or a log message. Events
+carry the stack in which they happened, whose frames are resolved to
+functions, files and lines.
+
+Traces produced by Go 1.22 and later are read as they are written.
+Older traces, starting with Go 1.11, are converted to the same events,
+but they are read in full first.
+
+The trace format changes between Go releases; the package reads
+the traces of the Go releases up to the one it is part of, and the
+events it reports are the same for all of them.
+
+The package is internal to the standard library and the go command
+until its API is agreed on; golang.org/x/exp/trace is a copy of it
+for use by other programs.
+*/
+package trace
@@ -0,0 +1,273 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slog
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// AsyncOptions are options for an [AsyncHandler].
+type AsyncOptions struct {
+	// QueueSize is the maximum number of records waiting to be handled.
+	// If it is zero, 1024 is used.
+	QueueSize int
+
+	// Drop selects what happens to a record when the queue is full.
+	Drop DropPolicy
+
+	// OnError, if non-nil, is called with the errors returned by the
+	// underlying handler. It is called from the goroutine handling
+	// the records, so it should not block.
+	OnError func(error)
+}
+
+// A DropPolicy selects the record an [AsyncHandler] drops
+// when its queue is full.
+type DropPolicy int
+
+const (
+	// DropNone drops no record: Handle waits for room in the queue.
+	// See [AsyncHandler] for the calls that do not wait.
+	DropNone DropPolicy = iota
+	// DropNewest drops the record passed to Handle.
+	DropNewest
+	// DropOldest drops the oldest record in the queue
+	// to make room for the record passed to Handle.
+	DropOldest
+)
+
+// AsyncHandler is a [Handler] that passes records to another handler
+// from a separate goroutine, so that formatting and writing them does
+// not slow down the code that logs them. Records wait in a bounded
+// queue, which drops records when it is full according to the
+// [DropPolicy] of the handler.
+//
+// Before a record is queued, its attributes are resolved, so that
+// [LogValuer] values are computed at the time of the log call.
+//
+// The handlers returned by WithAttrs and WithGroup share the queue;
+// [AsyncHandler.Close] must be called when the handler is no longer
+// needed, to handle the remaining records. If Close is never called,
+// the goroutine handling the records, and the queue, are never freed.
+//
+// With [DropNone], Handle waits while the queue is full, until the
+// goroutine handling the records makes room. That goroutine must
+// therefore not wait itself for a call to Handle on a full queue.
+// The most common way to do so, an underlying handler that logs
+// through the same [Logger], is detected: a call to Handle made from
+// the goroutine handling the records of any AsyncHandler does not wait,
+// and instead handles the record synchronously if the queue is full.
+// An underlying handler that waits for other goroutines logging
+// to the same AsyncHandler can still deadlock.
+type AsyncHandler struct {
+	handler Handler
+	q       *asyncQueue
+}
+
+// An asyncQueue is the queue of records shared by related AsyncHandlers.
+type asyncQueue struct {
+	opts AsyncOptions
+
+	mu       sync.Mutex
+	notEmpty sync.Cond    // signaled when an entry is added or the queue is closed
+	notFull  sync.Cond    // signaled when an entry is removed
+	entries  []asyncEntry // ring buffer of len QueueSize
+	head, n  int          // entries[head] is the oldest of the n queued entries
+	closed   bool
+	dropped  uint64
+
+	done chan struct{} // closed when the goroutine handling the records exits
+}
+
+type asyncEntry struct {
+	ctx context.Context
+	h   Handler
+	r   Record
+}
+
+// NewAsyncHandler creates an [AsyncHandler] that passes records to h,
+// using the given options, and starts the goroutine handling them.
+// If opts is nil, the default options are used.
+func NewAsyncHandler(h Handler, opts *AsyncOptions) *AsyncHandler {
+	if opts == nil {
+		opts = &AsyncOptions{}
+	}
+	q := &asyncQueue{opts: *opts, done: make(chan struct{})}
+	if q.opts.QueueSize <= 0 {
+		q.opts.QueueSize = 1024
+	}
+	q.entries = make([]asyncEntry, q.opts.QueueSize)
+	q.notEmpty.L = &q.mu
+	q.notFull.L = &q.mu
+	go q.run()
+	return &AsyncHandler{handler: h, q: q}
+}
+
+// Enabled reports whether the handler passed to [NewAsyncHandler]
+// is enabled for the level.
+func (h *AsyncHandler) Enabled(ctx context.Context, level Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle queues r to be handled by the underlying handler. It returns
+// nil, except if the handler is closed or if it is called from the
+// goroutine handling the records when the queue is full: then it
+// handles r itself and returns the result.
+func (h *AsyncHandler) Handle(ctx context.Context, r Record) error {
+	r = resolveRecord(r)
+	e := asyncEntry{context.WithoutCancel(ctx), h.handler, r}
+	if !h.q.put(e) {
+		return h.handler.Handle(ctx, r)
+	}
+	return nil
+}
+
+// WithAttrs returns a new [AsyncHandler] that passes records to the
+// result of calling WithAttrs on the underlying handler.
+func (h *AsyncHandler) WithAttrs(attrs []Attr) Handler {
+	return &AsyncHandler{handler: h.handler.WithAttrs(attrs), q: h.q}
+}
+
+// WithGroup returns a new [AsyncHandler] that passes records to the
+// result of calling WithGroup on the underlying handler.
+func (h *AsyncHandler) WithGroup(name string) Handler {
+	if name == "" {
+		return h
+	}
+	return &AsyncHandler{handler: h.handler.WithGroup(name), q: h.q}
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *AsyncHandler) Dropped() uint64 {
+	h.q.mu.Lock()
+	defer h.q.mu.Unlock()
+	return h.q.dropped
+}
+
+// Close handles the queued records and stops the goroutine handling
+// them. It applies to all the handlers sharing the queue of h; they
+// handle the records passed to them afterwards synchronously.
+// Close always returns nil.
+func (h *AsyncHandler) Close() error {
+	q := h.q
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mu.Unlock()
+	<-q.done
+	return nil
+}
+
+// put adds e to the queue, or drops a record. It reports false,
+// without doing either, if the queue is closed or if it would have
+// to wait for room when called from the goroutine handling records;
+// the caller must then handle e itself.
+func (q *asyncQueue) put(e asyncEntry) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.n == len(q.entries) && !q.closed {
+		switch q.opts.Drop {
+		case DropNewest:
+			q.dropped++
+			return true
+		case DropOldest:
+			q.entries[q.head] = asyncEntry{}
+			q.head = (q.head + 1) % len(q.entries)
+			q.n--
+			q.dropped++
+		default:
+			if onAsyncGoroutine() {
+				// Waiting could deadlock: only this goroutine
+				// may be left to make room.
+				return false
+			}
+			q.notFull.Wait()
+		}
+	}
+	if q.closed {
+		return false
+	}
+	q.entries[(q.head+q.n)%len(q.entries)] = e
+	q.n++
+	q.notEmpty.Signal()
+	return true
+}
+
+// run handles the queued records until the queue is closed and empty.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	for {
+		q.mu.Lock()
+		for q.n == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if q.n == 0 {
+			q.mu.Unlock()
+			return
+		}
+		e := q.entries[q.head]
+		q.entries[q.head] = asyncEntry{}
+		q.head = (q.head + 1) % len(q.entries)
+		q.n--
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		if err := e.h.Handle(e.ctx, e.r); err != nil && q.opts.OnError != nil {
+			q.opts.OnError(err)
+		}
+	}
+}
+
+// onAsyncGoroutine reports whether it is called from a goroutine
+// handling the records of an asyncQueue. It is only called when Handle
+// would wait, so the cost of walking the stack does not matter.
+func onAsyncGoroutine() bool {
+	// asyncQueue.run is the function at the bottom of the stack of
+	// such a goroutine.
+	var pcs [64]uintptr
+	for skip := 2; ; skip += len(pcs) {
+		n := runtime.Callers(skip, pcs[:])
+		frames := runtime.CallersFrames(pcs[:n])
+		for {
+			f, more := frames.Next()
+			if f.Function == "log/slog.(*asyncQueue).run" {
+				return true
+			}
+			if !more {
+				break
+			}
+		}
+		if n < len(pcs) {
+			return false
+		}
+	}
+}
+
+// resolveRecord returns a copy of r whose attribute values are resolved,
+// including those of groups.
+func resolveRecord(r Record) Record {
+	r2 := NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a Attr) bool {
+		r2.AddAttrs(resolveAttr(a))
+		return true
+	})
+	return r2
+}
+
+func resolveAttr(a Attr) Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == KindGroup {
+		as := a.Value.Group()
+		resolved := make([]Attr, len(as))
+		for i, ga := range as {
+			resolved[i] = resolveAttr(ga)
+		}
+		a.Value = GroupValue(resolved...)
+	}
+	return a
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// blockingHandler records the messages of the records it handles,
+// after the first one which waits until unblock is closed.
+type blockingHandler struct {
+	mu      sync.Mutex
+	msgs    []string
+	started chan struct{} // closed when the first record is being handled
+	unblock chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{started: make(chan struct{}), unblock: make(chan struct{})}
+}
+
+func (*blockingHandler) Enabled(context.Context, Level) bool { return true }
+
+func (h *blockingHandler) Handle(_ context.Context, r Record) error {
+	h.mu.Lock()
+	first := h.msgs == nil
+	h.msgs = append(h.msgs, r.Message)
+	h.mu.Unlock()
+	if first {
+		close(h.started)
+		<-h.unblock
+	}
+	return nil
+}
+
+func (h *blockingHandler) WithAttrs([]Attr) Handler { return h }
+func (h *blockingHandler) WithGroup(string) Handler { return h }
+
+func TestAsyncHandlerDrop(t *testing.T) {
+	for _, test := range []struct {
+		drop DropPolicy
+		want []string
+	}{
+		{DropNewest, []string{"0", "1", "2"}},
+		{DropOldest, []string{"0", "4", "5"}},
+	} {
+		bh := newBlockingHandler()
+		h := NewAsyncHandler(bh, &AsyncOptions{QueueSize: 2, Drop: test.drop})
+		ctx := context.Background()
+		h.Handle(ctx, NewRecord(testTime, LevelInfo, "0", 0))
+		<-bh.started
+		for _, msg := range []string{"1", "2", "3", "4", "5"} {
+			h.Handle(ctx, NewRecord(testTime, LevelInfo, msg, 0))
+		}
+		if got := h.Dropped(); got != 3 {
+			t.Errorf("%v: Dropped() = %d, want 3", test.drop, got)
+		}
+		close(bh.unblock)
+		h.Close()
+		if !slices.Equal(bh.msgs, test.want) {
+			t.Errorf("%v: handled %q, want %q", test.drop, bh.msgs, test.want)
+		}
+	}
+}
+
+type counter struct{ n int }
+
+func (c *counter) LogValue() Value { return IntValue(c.n) }
+
+func TestAsyncHandler(t *testing.T) {
+	var buf bytes.Buffer
+	var errs []error
+	errHandle := errors.New("handle failed")
+	text := NewTextHandler(&buf, &HandlerOptions{ReplaceAttr: func(_ []string, a Attr) Attr {
+		if a.Key == TimeKey {
+			return Attr{}
+		}
+		return a
+	}})
+	h := NewAsyncHandler(errorHandler{text, errHandle}, &AsyncOptions{
+		OnError: func(err error) { errs = append(errs, err) },
+	})
+	l := New(h).With("a", 1)
+
+	// LogValuers are resolved when the record is logged.
+	c := &counter{1}
+	l.Info("m", "c", c, Group("g", "c", c))
+	c.n = 2
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "level=INFO msg=m a=1 c=1 g.c=1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(errs) != 1 || errs[0] != errHandle {
+		t.Errorf("OnError called with %v, want [%v]", errs, errHandle)
+	}
+
+	// After Close, records are handled synchronously.
+	buf.Reset()
+	if err := l.Handler().Handle(context.Background(), NewRecord(testTime, LevelInfo, "late", 0)); err != errHandle {
+		t.Errorf("Handle after Close: got error %v, want %v", err, errHandle)
+	}
+	if got, want := buf.String(), "level=INFO msg=late a=1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// reentrantHandler records the messages of the records it handles and,
+// when handling one with the message "outer", logs three more records
+// to logger and closes logged.
+type reentrantHandler struct {
+	logger *Logger
+	logged chan struct{}
+	msgs   []string // only accessed by the goroutine handling records
+}
+
+func (*reentrantHandler) Enabled(context.Context, Level) bool { return true }
+
+func (h *reentrantHandler) Handle(_ context.Context, r Record) error {
+	h.msgs = append(h.msgs, r.Message)
+	if r.Message == "outer" {
+		for i := range 3 {
+			h.logger.Info("inner", "i", i)
+		}
+		close(h.logged)
+	}
+	return nil
+}
+
+func (h *reentrantHandler) WithAttrs([]Attr) Handler { return h }
+func (h *reentrantHandler) WithGroup(string) Handler { return h }
+
+func TestAsyncHandlerReentrant(t *testing.T) {
+	// The inner records fill the queue while the outer one is being
+	// handled. Waiting for room would deadlock, since only the goroutine
+	// handling the outer record could make it.
+	rh := &reentrantHandler{logged: make(chan struct{})}
+	h := NewAsyncHandler(rh, &AsyncOptions{QueueSize: 1, Drop: DropNone})
+	rh.logger = New(h)
+	rh.logger.Info("outer")
+	<-rh.logged
+	h.Close()
+	want := []string{"outer", "inner", "inner", "inner"}
+	if !slices.Equal(rh.msgs, want) {
+		t.Errorf("handled %q, want %q", rh.msgs, want)
+	}
+	if got := h.Dropped(); got != 0 {
+		t.Errorf("Dropped() = %d, want 0", got)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slog
+
+import (
+	"context"
+	"errors"
+)
+
+// MultiHandler is a [Handler] that passes each Record to several handlers.
+type MultiHandler struct {
+	handlers []Handler
+}
+
+// NewMultiHandler creates a [MultiHandler] that passes records to each
+// of handlers. Each handler only receives the records it is enabled
+// for, so handlers with different levels can share a [Logger]: for
+// example, a [TextHandler] writing warnings to the terminal and a
+// [JSONHandler] writing debug messages to a file.
+func NewMultiHandler(handlers ...Handler) *MultiHandler {
+	return &MultiHandler{handlers: handlers}
+}
+
+// Enabled reports whether any of the handlers is enabled for the level.
+func (h *MultiHandler) Enabled(ctx context.Context, level Level) bool {
+	for _, hh := range h.handlers {
+		if hh.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes a clone of r to each handler that is enabled for its
+// level. It returns the errors of the handlers, combined with
+// [errors.Join].
+func (h *MultiHandler) Handle(ctx context.Context, r Record) error {
+	var errs []error
+	for _, hh := range h.handlers {
+		if hh.Enabled(ctx, r.Level) {
+			if err := hh.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new [MultiHandler] whose handlers are the results
+// of calling WithAttrs on h's handlers.
+func (h *MultiHandler) WithAttrs(attrs []Attr) Handler {
+	handlers := make([]Handler, len(h.handlers))
+	for i, hh := range h.handlers {
+		// Each handler owns its slice.
+		handlers[i] = hh.WithAttrs(append([]Attr(nil), attrs...))
+	}
+	return &MultiHandler{handlers: handlers}
+}
+
+// WithGroup returns a new [MultiHandler] whose handlers are the results
+// of calling WithGroup on h's handlers.
+func (h *MultiHandler) WithGroup(name string) Handler {
+	if name == "" {
+		return h
+	}
+	handlers := make([]Handler, len(h.handlers))
+	for i, hh := range h.handlers {
+		handlers[i] = hh.WithGroup(name)
+	}
+	return &MultiHandler{handlers: handlers}
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+type errorHandler struct {
+	Handler
+	err error
+}
+
+func (h errorHandler) Handle(ctx context.Context, r Record) error {
+	h.Handler.Handle(ctx, r)
+	return h.err
+}
+
+func (h errorHandler) WithAttrs(as []Attr) Handler {
+	return errorHandler{h.Handler.WithAttrs(as), h.err}
+}
+
+func (h errorHandler) WithGroup(name string) Handler {
+	return errorHandler{h.Handler.WithGroup(name), h.err}
+}
+
+func TestMultiHandler(t *testing.T) {
+	var debug, warn bytes.Buffer
+	removeTime := func(_ []string, a Attr) Attr {
+		if a.Key == TimeKey {
+			return Attr{}
+		}
+		return a
+	}
+	h := NewMultiHandler(
+		NewTextHandler(&debug, &HandlerOptions{Level: LevelDebug, ReplaceAttr: removeTime}),
+		NewTextHandler(&warn, &HandlerOptions{Level: LevelWarn, ReplaceAttr: removeTime}),
+	)
+	ctx := context.Background()
+	if h.Enabled(ctx, LevelDebug-1) {
+		t.Error("enabled below debug level")
+	}
+	if !h.Enabled(ctx, LevelDebug) {
+		t.Error("not enabled at debug level")
+	}
+
+	l := New(h).With("a", 1).WithGroup("g")
+	l.Debug("d", "b", 2)
+	l.Warn("w", "b", 3)
+	if got, want := debug.String(), "level=DEBUG msg=d a=1 g.b=2\nlevel=WARN msg=w a=1 g.b=3\n"; got != want {
+		t.Errorf("debug handler output:\ngot  %q\nwant %q", got, want)
+	}
+	if got, want := warn.String(), "level=WARN msg=w a=1 g.b=3\n"; got != want {
+		t.Errorf("warn handler output:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestMultiHandlerErrors(t *testing.T) {
+	err1, err2 := errors.New("one"), errors.New("two")
+	var buf bytes.Buffer
+	h := NewMultiHandler(
+		errorHandler{NewTextHandler(io.Discard, nil), err1},
+		NewTextHandler(&buf, nil),
+		errorHandler{NewTextHandler(io.Discard, nil), err2},
+	)
+	err := h.Handle(context.Background(), NewRecord(testTime, LevelInfo, "m", 0))
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Errorf("Handle: got error %v, want both %v and %v", err, err1, err2)
+	}
+	if buf.Len() == 0 {
+		t.Error("record not passed to all handlers after an error")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SamplingOptions are options for a [SamplingHandler].
+type SamplingOptions struct {
+	// Interval is the period over which records are counted.
+	// If it is zero, one second is used.
+	Interval time.Duration
+
+	// First is the number of records with the same level and message
+	// that are handled in each interval before sampling starts.
+	// If First and Thereafter are both zero, First is 1.
+	First int
+
+	// Thereafter selects the records handled once First records with
+	// the same level and message have been handled in the interval:
+	// one in every Thereafter records is handled. If it is zero, none is.
+	Thereafter int
+
+	// Level is the maximum level of the sampled records: records above
+	// it are always handled. If it is nil, all records are sampled.
+	Level Leveler
+}
+
+// SamplingHandler is a [Handler] that limits the rate of the records it
+// passes to another handler, to keep frequent log messages from
+// overwhelming the output. Records are counted by level and message:
+// in each interval, the first records of each kind are handled, and
+// then only a sample of them.
+//
+// The counts are shared by the handlers returned by WithAttrs and
+// WithGroup.
+type SamplingHandler struct {
+	handler Handler
+	opts    SamplingOptions
+	s       *sampler
+}
+
+type sampler struct {
+	mu     sync.Mutex
+	start  time.Time           // start of the current interval
+	counts map[samplingKey]int // records seen in the current interval
+}
+
+type samplingKey struct {
+	level Level
+	msg   string
+}
+
+// NewSamplingHandler creates a [SamplingHandler] that passes a sample
+// of records to h, using the given options.
+// If opts is nil, the default options are used, which drop all records
+// but the first of each kind in every second.
+func NewSamplingHandler(h Handler, opts *SamplingOptions) *SamplingHandler {
+	if opts == nil {
+		opts = &SamplingOptions{}
+	}
+	sh := &SamplingHandler{
+		handler: h,
+		opts:    *opts,
+		s:       &sampler{counts: make(map[samplingKey]int)},
+	}
+	if sh.opts.Interval <= 0 {
+		sh.opts.Interval = time.Second
+	}
+	if sh.opts.First <= 0 && sh.opts.Thereafter <= 0 {
+		sh.opts.First = 1
+	}
+	return sh
+}
+
+// Enabled reports whether the handler passed to [NewSamplingHandler]
+// is enabled for the level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle passes r to the underlying handler if it is sampled,
+// and drops it otherwise.
+func (h *SamplingHandler) Handle(ctx context.Context, r Record) error {
+	if h.opts.Level != nil && r.Level > h.opts.Level.Level() {
+		return h.handler.Handle(ctx, r)
+	}
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if !h.s.sample(now, h.opts, samplingKey{r.Level, r.Message}) {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+// sample counts a record of the given kind at time now,
+// and reports whether it should be handled.
+func (s *sampler) sample(now time.Time, opts SamplingOptions, key samplingKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.start) >= opts.Interval || now.Before(s.start) {
+		s.start = now
+		clear(s.counts)
+	}
+	n := s.counts[key]
+	s.counts[key] = n + 1
+	if n < opts.First {
+		return true
+	}
+	return opts.Thereafter > 0 && (n-opts.First)%opts.Thereafter == 0
+}
+
+// WithAttrs returns a new [SamplingHandler] that passes records to
+// the result of calling WithAttrs on the underlying handler.
+func (h *SamplingHandler) WithAttrs(attrs []Attr) Handler {
+	return &SamplingHandler{handler: h.handler.WithAttrs(attrs), opts: h.opts, s: h.s}
+}
+
+// WithGroup returns a new [SamplingHandler] that passes records to
+// the result of calling WithGroup on the underlying handler.
+func (h *SamplingHandler) WithGroup(name string) Handler {
+	if name == "" {
+		return h
+	}
+	return &SamplingHandler{handler: h.handler.WithGroup(name), opts: h.opts, s: h.s}
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slog
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// recordingHandler records the messages of the records it handles.
+type recordingHandler struct {
+	msgs *[]string
+}
+
+func (recordingHandler) Enabled(context.Context, Level) bool { return true }
+
+func (h recordingHandler) Handle(_ context.Context, r Record) error {
+	*h.msgs = append(*h.msgs, r.Message)
+	return nil
+}
+
+func (h recordingHandler) WithAttrs([]Attr) Handler { return h }
+func (h recordingHandler) WithGroup(string) Handler { return h }
+
+func TestSamplingHandler(t *testing.T) {
+	var msgs []string
+	h := NewSamplingHandler(recordingHandler{&msgs}, &SamplingOptions{
+		Interval:   time.Minute,
+		First:      2,
+		Thereafter: 3,
+		Level:      LevelInfo,
+	})
+	ctx := context.Background()
+	handle := func(h Handler, t time.Time, level Level, msg string) {
+		h.Handle(ctx, NewRecord(t, level, msg, 0))
+	}
+
+	t0 := testTime
+	derived := h.WithAttrs([]Attr{Int("a", 1)}).WithGroup("g")
+	for i := range 10 {
+		hh := Handler(h)
+		if i%2 == 1 {
+			hh = derived // shares the counts
+		}
+		handle(hh, t0, LevelInfo, "a")
+		handle(hh, t0, LevelDebug, "a")
+		handle(hh, t0, LevelWarn, "w")
+	}
+	handle(h, t0.Add(time.Minute), LevelInfo, "a")
+
+	count := func(msg string) int {
+		n := 0
+		for _, m := range msgs {
+			if m == msg {
+				n++
+			}
+		}
+		return n
+	}
+	// For each level, records 0, 1, 2, 5 and 8 of the first interval,
+	// then the first one of the second interval.
+	if got, want := count("a"), 2*5+1; got != want {
+		t.Errorf("handled %d sampled records, want %d", got, want)
+	}
+	if got, want := count("w"), 10; got != want {
+		t.Errorf("handled %d records above Level, want %d", got, want)
+	}
+}
+
+func TestSamplingHandlerDefaults(t *testing.T) {
+	var msgs []string
+	h := NewSamplingHandler(recordingHandler{&msgs}, nil)
+	for _, msg := range []string{"a", "a", "b", "a", "b"} {
+		h.Handle(context.Background(), NewRecord(testTime, LevelInfo, msg, 0))
+	}
+	if want := []string{"a", "b"}; !slices.Equal(msgs, want) {
+		t.Errorf("handled %q, want %q", msgs, want)
+	}
+}
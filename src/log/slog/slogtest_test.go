@@ -41,6 +41,41 @@ func TestSlogtest(t *testing.T) {
 	}
 }
 
+func TestSlogtestWrappers(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		new  func(io.Writer) slog.Handler
+	}{
+		{"Multi", func(w io.Writer) slog.Handler {
+			return slog.NewMultiHandler(slog.NewJSONHandler(w, nil), slog.NewTextHandler(io.Discard, nil))
+		}},
+		{"Sampling", func(w io.Writer) slog.Handler {
+			return slog.NewSamplingHandler(slog.NewJSONHandler(w, nil), &slog.SamplingOptions{First: 1000})
+		}},
+		{"Async", func(w io.Writer) slog.Handler {
+			return slog.NewAsyncHandler(slog.NewJSONHandler(w, nil), nil)
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := test.new(&buf)
+			results := func() []map[string]any {
+				if c, ok := h.(io.Closer); ok {
+					c.Close()
+				}
+				ms, err := parseLines(buf.Bytes(), parseJSON)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return ms
+			}
+			if err := slogtest.TestHandler(h, results); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
 func parseLines(src []byte, parse func([]byte) (map[string]any, error)) ([]map[string]any, error) {
 	var records []map[string]any
 	for _, line := range bytes.Split(src, []byte{'\n'}) {
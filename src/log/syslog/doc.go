@@ -4,21 +4,23 @@
 
 // Package syslog provides a simple interface to the system log
 // service. It can send messages to the syslog daemon using UNIX
-// domain sockets, UDP or TCP.
+// domain sockets, UDP, TCP or TLS.
 //
 // Only one call to Dial is necessary. On write failures,
 // the syslog client will attempt to reconnect to the server
 // and write again.
 //
-// The syslog package is frozen and is not accepting new features.
-// Some external packages provide more functionality. See:
-//
-//	https://godoc.org/?q=syslog
+// Messages are written in the traditional format of RFC 3164, unless a
+// [Dialer] selects the format of RFC 5424, which adds message IDs and
+// structured data to messages; see [Message]. A [Dialer] can also
+// connect to servers using TLS, as described by RFC 5425, and buffer
+// messages while the server cannot be reached. A [Handler] sends
+// the records of a [log/slog.Logger] to a [Writer].
 package syslog
 
-// BUG(brainman): This package is not implemented on Windows. As the
-// syslog package is frozen, Windows users are encouraged to
-// use a package outside of the standard library. For background,
+// BUG(brainman): This package is not implemented on Windows.
+// Windows users are encouraged to use a package outside of the
+// standard library. For background,
 // see https://golang.org/issue/1108.
 
 // BUG(akumar): This package is not implemented on Plan 9.
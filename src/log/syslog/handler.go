@@ -0,0 +1,148 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !plan9
+
+package syslog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// HandlerOptions are options for a [Handler].
+type HandlerOptions struct {
+	// Level reports the minimum level to log.
+	// If it is nil, the handler uses slog.LevelInfo.
+	Level slog.Leveler
+
+	// MsgID is the MsgID of the messages.
+	MsgID string
+}
+
+// Handler is a [slog.Handler] that sends records to a [Writer].
+//
+// The level of a record is mapped to a severity: levels below
+// slog.LevelInfo are sent as [LOG_DEBUG], levels below slog.LevelWarn
+// as [LOG_INFO], levels below slog.LevelError as [LOG_WARNING], and
+// others as [LOG_ERR]. The message of the record is the text of the
+// syslog message, and its attributes are parameters of a single
+// structured data element. The names of attributes in groups are
+// qualified by the group names, separated by dots.
+type Handler struct {
+	w      *Writer
+	sdID   string
+	opts   HandlerOptions
+	params []SDParam // parameters from WithAttrs
+	prefix string    // group prefix from WithGroup
+}
+
+// NewHandler creates a [Handler] that writes to w, using the given
+// options. If opts is nil, the default options are used.
+//
+// The attributes of the records are sent in a structured data element
+// with the ID sdID. RFC 5424 reserves IDs without an '@' for those
+// registered with IANA, so sdID should have the form
+// name@<private enterprise number>, using the private enterprise
+// number of the organization defining the element. NewHandler panics
+// if sdID is empty.
+func NewHandler(w *Writer, sdID string, opts *HandlerOptions) *Handler {
+	if sdID == "" {
+		panic("syslog: NewHandler with empty SD-ID")
+	}
+	h := &Handler{w: w, sdID: sdID}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle sends r to the Writer of h.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	m := &Message{
+		Severity: levelSeverity(r.Level),
+		Time:     r.Time,
+		MsgID:    h.opts.MsgID,
+		Text:     r.Message,
+	}
+	params := slices.Clip(h.params)
+	r.Attrs(func(a slog.Attr) bool {
+		params = appendParams(params, h.prefix, a)
+		return true
+	})
+	if len(params) > 0 {
+		m.StructuredData = []SDElement{{ID: h.sdID, Params: params}}
+	}
+	return h.w.WriteMessage(m)
+}
+
+// WithAttrs returns a new [Handler] whose attributes consist of
+// both the receiver's attributes and the arguments.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.params = slices.Clip(h.params)
+	for _, a := range attrs {
+		h2.params = appendParams(h2.params, h.prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a new [Handler] that qualifies the names of
+// later attributes by name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// levelSeverity returns the severity of messages at the given level.
+func levelSeverity(l slog.Level) Priority {
+	switch {
+	case l < slog.LevelInfo:
+		return LOG_DEBUG
+	case l < slog.LevelWarn:
+		return LOG_INFO
+	case l < slog.LevelError:
+		return LOG_WARNING
+	default:
+		return LOG_ERR
+	}
+}
+
+// appendParams appends the parameters for a to params,
+// following the rules of slog.Handler.
+func appendParams(params []SDParam, prefix string, a slog.Attr) []SDParam {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return params
+	}
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			params = appendParams(params, prefix, ga)
+		}
+		return params
+	case slog.KindTime:
+		return append(params, SDParam{prefix + a.Key, a.Value.Time().Format(time.RFC3339Nano)})
+	default:
+		return append(params, SDParam{prefix + a.Key, a.Value.String()})
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !plan9 && !js && !wasip1
+
+package syslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	if !testableNetwork("tcp") {
+		t.Skip("tcp not testable")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	done := make(chan string, 10)
+	go runFrameServer(l, done)
+
+	d := &Dialer{Format: RFC5424, Hostname: "host"}
+	w, err := d.Dial("tcp", l.Addr().String(), LOG_DAEMON, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	h := NewHandler(w, "example@32473", &HandlerOptions{Level: slog.LevelDebug, MsgID: "SLOG"})
+	logger := slog.New(h).With("a", 1).WithGroup("g").With("b", `x"y`)
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	logger.Debug("debug")
+	logger.Info("info", "c", true, slog.Group("h", "d", tm))
+	logger.Warn("warn", slog.Group("empty"))
+	slog.New(h).Error("error")
+
+	pid := os.Getpid()
+	for _, tt := range []struct {
+		pri  Priority
+		rest string
+	}{
+		{LOG_DAEMON | LOG_DEBUG, `[example@32473 a="1" g.b="x\"y"] debug`},
+		{LOG_DAEMON | LOG_INFO, `[example@32473 a="1" g.b="x\"y" g.c="true" g.h.d="2024-01-02T03:04:05Z"] info`},
+		{LOG_DAEMON | LOG_WARNING, `[example@32473 a="1" g.b="x\"y"] warn`},
+		{LOG_DAEMON | LOG_ERR, `- error`},
+	} {
+		got := <-done
+		wantPrefix := fmt.Sprintf("<%d>1 ", tt.pri)
+		wantSuffix := fmt.Sprintf(" host app %d SLOG %s", pid, tt.rest)
+		if !strings.HasPrefix(got, wantPrefix) || !strings.HasSuffix(got, wantSuffix) {
+			t.Errorf("got %q, want %q...%q", got, wantPrefix, wantSuffix)
+		}
+	}
+}
+
+func TestHandlerEnabled(t *testing.T) {
+	ctx := context.Background()
+	h := NewHandler(nil, "example@32473", nil)
+	if h.Enabled(ctx, slog.LevelDebug) || !h.Enabled(ctx, slog.LevelInfo) {
+		t.Error("default level is not slog.LevelInfo")
+	}
+	h = NewHandler(nil, "example@32473", &HandlerOptions{Level: slog.LevelError})
+	if h.Enabled(ctx, slog.LevelWarn) || !h.Enabled(ctx, slog.LevelError) {
+		t.Error("level option is ignored")
+	}
+}
+
+func TestHandlerEmptySDID(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewHandler with empty SD-ID did not panic")
+		}
+	}()
+	NewHandler(nil, "", nil)
+}
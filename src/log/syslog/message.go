@@ -0,0 +1,277 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !plan9
+
+package syslog
+
+import (
+	"crypto/tls"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Format is the format of the messages sent by a [Writer].
+type Format int
+
+const (
+	// RFC3164 is the traditional BSD syslog format described by
+	// RFC 3164. It is the format used by Writers returned by [Dial].
+	RFC3164 Format = iota
+
+	// RFC5424 is the syslog format described by RFC 5424, which
+	// supports precise timestamps, message IDs and structured data.
+	RFC5424
+)
+
+// A Dialer contains options for connecting to a log daemon.
+// The zero value is valid and produces the same Writers as [Dial].
+type Dialer struct {
+	// Format is the format of the messages.
+	Format Format
+
+	// TLSConfig, if non-nil, is used to connect to the log daemon
+	// with TLS, as described by RFC 5425. The network must then be a
+	// stream network, such as "tcp".
+	TLSConfig *tls.Config
+
+	// Hostname is the name of the host sent in messages.
+	// If it is empty, the name reported by the kernel is used.
+	Hostname string
+
+	// BufferSize is the maximum number of messages kept while the log
+	// daemon cannot be reached. They are sent, in order, when the
+	// connection is reestablished by a later write or by Close.
+	// When the buffer is full, the oldest message is dropped.
+	// If BufferSize is zero, messages are not buffered, and writes
+	// fail when the log daemon cannot be reached.
+	BufferSize int
+
+	// Timeout is the maximum amount of time a dial waits for a
+	// connection, including the TLS handshake. If it is zero,
+	// there is no timeout beyond the operating system's.
+	Timeout time.Duration
+}
+
+// A Message is a log message with the optional fields of RFC 5424.
+// When it is sent in the RFC 3164 format, its structured data is
+// written at the beginning of its text, and its MsgID is omitted.
+type Message struct {
+	// Severity is the severity of the message. Its facility bits,
+	// if any, are ignored.
+	Severity Priority
+
+	// Time is the time of the message.
+	// If it is zero, the time the message is written is used.
+	Time time.Time
+
+	// MsgID identifies the type of the message, such as "TCPIN".
+	MsgID string
+
+	// StructuredData is the structured data of the message.
+	StructuredData []SDElement
+
+	// Text is the free-form text of the message.
+	Text string
+}
+
+// An SDElement is an element of the structured data of a [Message].
+type SDElement struct {
+	// ID is the SD-ID of the element. IDs not registered with IANA
+	// have the form name@<private enterprise number>.
+	ID     string
+	Params []SDParam
+}
+
+// An SDParam is a parameter of an [SDElement].
+//
+// RFC 5424 limits the IDs of elements and the names of parameters to
+// 32 printable ASCII characters other than '=', ']', '"' and space.
+// An ID or name that does not follow these rules is sent as a prefix
+// of it, with the invalid characters replaced by '_', followed by '~'
+// and 8 hexadecimal digits of a hash of it, so that different names
+// are unlikely to be sent as the same name.
+type SDParam struct {
+	Name  string
+	Value string
+}
+
+// Field length limits from RFC 5424, section 6.
+const (
+	maxHostname = 255
+	maxAppName  = 48
+	maxProcID   = 128
+	maxMsgID    = 32
+	maxSDName   = 32
+)
+
+// append5424 appends the RFC 5424 form of m to b:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+func (m *Message) append5424(b []byte, p Priority, hostname, tag string) []byte {
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(p), 10)
+	b = append(b, ">1 "...)
+	t := m.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	b = t.AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+	b = append(b, ' ')
+	b = appendHeaderField(b, hostname, maxHostname)
+	b = append(b, ' ')
+	b = appendHeaderField(b, tag, maxAppName)
+	b = append(b, ' ')
+	b = appendHeaderField(b, strconv.Itoa(os.Getpid()), maxProcID)
+	b = append(b, ' ')
+	b = appendHeaderField(b, m.MsgID, maxMsgID)
+	b = append(b, ' ')
+	if len(m.StructuredData) == 0 {
+		b = append(b, '-')
+	} else {
+		b = appendSD(b, m.StructuredData)
+	}
+	if m.Text != "" {
+		b = append(b, ' ')
+		b = append(b, strings.TrimSuffix(m.Text, "\n")...)
+	}
+	return b
+}
+
+// append3164 appends the RFC 3164 form of m to b. Messages to the
+// local daemon omit the hostname and use a shorter timestamp. If nl is
+// set, the message is terminated by a newline.
+func (m *Message) append3164(b []byte, p Priority, hostname, tag string, local, nl bool) []byte {
+	t := m.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(p), 10)
+	b = append(b, '>')
+	if local {
+		b = t.AppendFormat(b, time.Stamp)
+	} else {
+		b = t.AppendFormat(b, time.RFC3339)
+		b = append(b, ' ')
+		b = append(b, hostname...)
+	}
+	b = append(b, ' ')
+	b = append(b, tag...)
+	b = append(b, '[')
+	b = strconv.AppendInt(b, int64(os.Getpid()), 10)
+	b = append(b, "]: "...)
+	msg := m.text3164()
+	if nl {
+		b = append(b, msg...)
+		if !strings.HasSuffix(msg, "\n") {
+			b = append(b, '\n')
+		}
+	} else {
+		b = append(b, strings.TrimSuffix(msg, "\n")...)
+	}
+	return b
+}
+
+// text3164 returns the text of m preceded by its structured data.
+func (m *Message) text3164() string {
+	if len(m.StructuredData) == 0 {
+		return m.Text
+	}
+	b := appendSD(nil, m.StructuredData)
+	if m.Text != "" {
+		b = append(b, ' ')
+		b = append(b, m.Text...)
+	}
+	return string(b)
+}
+
+// appendHeaderField appends s to b as an RFC 5424 header field of at
+// most max characters. Characters outside of PRINTUSASCII are replaced
+// by '_', and an empty field is replaced by the NILVALUE "-".
+func appendHeaderField(b []byte, s string, max int) []byte {
+	if s == "" {
+		return append(b, '-')
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// appendSDName appends s to b as an SD-NAME, which is a header field
+// that cannot contain '=', ']', '"' or spaces. If s is not a valid
+// SD-NAME, appendSDName appends a valid prefix of it followed by a
+// hash of s, as described at [SDParam].
+func appendSDName(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, '_')
+	}
+	valid := len(s) <= maxSDName
+	for i := 0; i < len(s) && valid; i++ {
+		valid = isSDNameChar(s[i])
+	}
+	if valid {
+		return append(b, s...)
+	}
+
+	const hashLen = len("~01234567")
+	p := s[:min(len(s), maxSDName-hashLen)]
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if !isSDNameChar(c) {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	// FNV-1a.
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	b = append(b, '~')
+	for shift := 28; shift >= 0; shift -= 4 {
+		b = append(b, "0123456789abcdef"[h>>shift&0xf])
+	}
+	return b
+}
+
+// isSDNameChar reports whether c may appear in an SD-NAME.
+func isSDNameChar(c byte) bool {
+	return c >= 33 && c <= 126 && c != '=' && c != ']' && c != '"'
+}
+
+// appendSD appends the structured data elements to b.
+func appendSD(b []byte, sd []SDElement) []byte {
+	for _, e := range sd {
+		b = append(b, '[')
+		b = appendSDName(b, e.ID)
+		for _, p := range e.Params {
+			b = append(b, ' ')
+			b = appendSDName(b, p.Name)
+			b = append(b, `="`...)
+			for i := 0; i < len(p.Value); i++ {
+				switch c := p.Value[i]; c {
+				case '"', '\\', ']':
+					b = append(b, '\\', c)
+				default:
+					b = append(b, c)
+				}
+			}
+			b = append(b, '"')
+		}
+		b = append(b, ']')
+	}
+	return b
+}
@@ -0,0 +1,353 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !plan9 && !js && !wasip1
+
+package syslog
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppend5424(t *testing.T) {
+	pid := os.Getpid()
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	tests := []struct {
+		m        Message
+		hostname string
+		tag      string
+		want     string
+	}{
+		{
+			Message{Time: tm, Text: "hello"},
+			"host", "app",
+			fmt.Sprintf("<14>1 2024-05-06T07:08:09.123456Z host app %d - - hello", pid),
+		},
+		{
+			Message{Time: tm},
+			"", "",
+			fmt.Sprintf("<14>1 2024-05-06T07:08:09.123456Z - - %d - -", pid),
+		},
+		{
+			Message{Time: tm, MsgID: "ID 47", Text: "two\nlines\n"},
+			"my host", strings.Repeat("a", 60),
+			fmt.Sprintf("<14>1 2024-05-06T07:08:09.123456Z my_host %s %d ID_47 - two\nlines", strings.Repeat("a", 48), pid),
+		},
+		{
+			Message{
+				Time: tm,
+				StructuredData: []SDElement{
+					{ID: "exampleSDID@32473", Params: []SDParam{{"iut", "3"}, {"event Source", `a"b\c]d`}}},
+					{ID: "examplePriority@32473"},
+				},
+				Text: "msg",
+			},
+			"host", "app",
+			fmt.Sprintf(`<14>1 2024-05-06T07:08:09.123456Z host app %d - [exampleSDID@32473 iut="3" event_Source~4ab12390="a\"b\\c\]d"][examplePriority@32473] msg`, pid),
+		},
+		{
+			// Names longer than 32 bytes with a common prefix stay distinct.
+			Message{
+				Time: tm,
+				StructuredData: []SDElement{
+					{ID: "exampleSDID@32473", Params: []SDParam{
+						{"a_very_long_attribute_name_number_1", "1"},
+						{"a_very_long_attribute_name_number_2", "2"},
+					}},
+				},
+			},
+			"host", "app",
+			fmt.Sprintf(`<14>1 2024-05-06T07:08:09.123456Z host app %d - [exampleSDID@32473 a_very_long_attribute_n~3ac5768d="1" a_very_long_attribute_n~37c571d4="2"]`, pid),
+		},
+	}
+	for _, tt := range tests {
+		got := string(tt.m.append5424(nil, LOG_USER|LOG_INFO, tt.hostname, tt.tag))
+		if got != tt.want {
+			t.Errorf("append5424(%+v):\ngot  %q\nwant %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestText3164(t *testing.T) {
+	m := Message{
+		StructuredData: []SDElement{{ID: "a@1", Params: []SDParam{{"k", "v"}}}},
+		Text:           "text",
+	}
+	if got, want := m.text3164(), `[a@1 k="v"] text`; got != want {
+		t.Errorf("text3164() = %q, want %q", got, want)
+	}
+}
+
+// readFrame reads an octet-counted message from r.
+func readFrame(r *bufio.Reader) (string, error) {
+	s, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(s, " "))
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// runFrameServer accepts a connection on l and sends the frames read
+// from it to done.
+func runFrameServer(l net.Listener, done chan<- string) {
+	c, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer c.Close()
+	c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(c)
+	for {
+		s, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		done <- s
+	}
+}
+
+func TestRFC5424OverTCP(t *testing.T) {
+	if !testableNetwork("tcp") {
+		t.Skip("tcp not testable")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	done := make(chan string, 2)
+	go runFrameServer(l, done)
+
+	d := &Dialer{Format: RFC5424, Hostname: "host"}
+	w, err := d.Dial("tcp", l.Addr().String(), LOG_LOCAL0|LOG_INFO, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("first\nwith a newline\n")); err != nil {
+		t.Fatal(err)
+	}
+	err = w.WriteMessage(&Message{
+		Severity:       LOG_ERR,
+		MsgID:          "AUDIT",
+		StructuredData: []SDElement{{ID: "audit@32473", Params: []SDParam{{"user", "gopher"}}}},
+		Text:           "second",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid := os.Getpid()
+	for _, want := range []string{
+		fmt.Sprintf(" host app %d - - first\nwith a newline", pid),
+		fmt.Sprintf(" host app %d AUDIT [audit@32473 user=\"gopher\"] second", pid),
+	} {
+		got := <-done
+		if !strings.HasSuffix(got, want) {
+			t.Errorf("got %q, want suffix %q", got, want)
+		}
+	}
+}
+
+func TestRFC5424OverLocalStream(t *testing.T) {
+	if !testableNetwork("unix") {
+		t.Skip("unix not testable")
+	}
+	path := filepath.Join(t.TempDir(), "log")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	done := make(chan string, 2)
+	go runFrameServer(l, done)
+
+	defer func(old []string) { logPaths = old }(logPaths)
+	logPaths = []string{path}
+
+	d := &Dialer{Format: RFC5424, Hostname: "host"}
+	w, err := d.Dial("", "", LOG_LOCAL0|LOG_INFO, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, msg := range []string{"first\nwith a newline", "second"} {
+		if _, err := w.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pid := os.Getpid()
+	for _, msg := range []string{"first\nwith a newline", "second"} {
+		want := fmt.Sprintf(" host app %d - - %s", pid, msg)
+		select {
+		case got := <-done:
+			if !strings.HasSuffix(got, want) {
+				t.Errorf("got %q, want suffix %q", got, want)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for frame with suffix %q", want)
+		}
+	}
+}
+
+// testTLSConfigs returns the configurations of a TLS server
+// and of a client trusting it.
+func testTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "syslog test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client = &tls.Config{RootCAs: pool}
+	return server, client
+}
+
+func TestTLS(t *testing.T) {
+	if !testableNetwork("tcp") {
+		t.Skip("tcp not testable")
+	}
+	serverConfig, clientConfig := testTLSConfigs(t)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for _, format := range []Format{RFC3164, RFC5424} {
+		done := make(chan string, 1)
+		go runFrameServer(l, done)
+
+		d := &Dialer{Format: format, TLSConfig: clientConfig, Hostname: "host", Timeout: 5 * time.Second}
+		w, err := d.Dial("tcp", l.Addr().String(), LOG_USER|LOG_NOTICE, "app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Notice("over TLS\n"); err != nil {
+			t.Fatal(err)
+		}
+		got := <-done
+		w.Close()
+
+		want := fmt.Sprintf("host app[%d]: over TLS", os.Getpid())
+		if format == RFC5424 {
+			want = fmt.Sprintf("host app %d - - over TLS", os.Getpid())
+		}
+		if !strings.HasPrefix(got, "<13>") || !strings.HasSuffix(got, want) {
+			t.Errorf("format %d: got %q, want <13>...%q", format, got, want)
+		}
+	}
+}
+
+func TestTLSRequiresStream(t *testing.T) {
+	d := &Dialer{TLSConfig: &tls.Config{}}
+	if _, err := d.Dial("udp", "127.0.0.1:514", LOG_INFO, "app"); err == nil {
+		t.Error("Dial with TLS over udp succeeded")
+	}
+}
+
+func TestBuffering(t *testing.T) {
+	if !testableNetwork("tcp") {
+		t.Skip("tcp not testable")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan string, 10)
+	go runFrameServer(l, done)
+
+	d := &Dialer{Format: RFC5424, Hostname: "host", BufferSize: 2}
+	w, err := d.Dial("tcp", l.Addr().String(), LOG_USER|LOG_INFO, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.Info("m0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-done; !strings.HasSuffix(got, " m0") {
+		t.Fatalf("got %q, want m0", got)
+	}
+
+	// Make the server unreachable.
+	l.Close()
+	w.mu.Lock()
+	w.conn.close()
+	w.conn = nil
+	w.mu.Unlock()
+	for i := 1; i <= 3; i++ {
+		if err := w.Info(fmt.Sprintf("m%d", i)); err != nil {
+			t.Fatalf("writing m%d: %v", i, err)
+		}
+	}
+	if n := len(w.pending); n != 2 {
+		t.Fatalf("%d buffered messages, want 2", n)
+	}
+
+	// Bring the server back at another address.
+	l, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go runFrameServer(l, done)
+	w.mu.Lock()
+	w.raddr = l.Addr().String()
+	w.mu.Unlock()
+
+	if err := w.Info("m4"); err != nil {
+		t.Fatal(err)
+	}
+	// m1 was dropped to make room for m3.
+	for _, want := range []string{"m2", "m3", "m4"} {
+		if got := <-done; !strings.HasSuffix(got, " "+want) {
+			t.Errorf("got %q, want %s", got, want)
+		}
+	}
+}
@@ -7,11 +7,13 @@
 package syslog
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -80,8 +82,22 @@ type Writer struct {
 	network  string
 	raddr    string
 
-	mu   sync.Mutex // guards conn
-	conn serverConn
+	// Set by Dialer.Dial.
+	format    Format
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	bufSize   int
+
+	mu      sync.Mutex // guards conn, framed and pending
+	conn    serverConn
+	framed  bool             // messages on conn are octet-counted
+	pending []pendingMessage // messages waiting for a connection
+}
+
+// A pendingMessage is a message buffered while the server is unreachable.
+type pendingMessage struct {
+	p Priority
+	m Message
 }
 
 // This interface and the separate syslog_unix.go file exist for
@@ -96,8 +112,9 @@ type serverConn interface {
 }
 
 type netConn struct {
-	local bool
-	conn  net.Conn
+	local  bool
+	stream bool // conn is stream-oriented
+	conn   net.Conn
 }
 
 // New establishes a new connection to the system log daemon. Each
@@ -116,6 +133,11 @@ func New(priority Priority, tag string) (*Writer, error) {
 // Otherwise, see the documentation for net.Dial for valid values
 // of network and raddr.
 func Dial(network, raddr string, priority Priority, tag string) (*Writer, error) {
+	return new(Dialer).Dial(network, raddr, priority, tag)
+}
+
+// Dial is like the package-level [Dial] function, using the options of d.
+func (d *Dialer) Dial(network, raddr string, priority Priority, tag string) (*Writer, error) {
 	if priority < 0 || priority > LOG_LOCAL7|LOG_DEBUG {
 		return nil, errors.New("log/syslog: invalid priority")
 	}
@@ -123,14 +145,24 @@ func Dial(network, raddr string, priority Priority, tag string) (*Writer, error)
 	if tag == "" {
 		tag = os.Args[0]
 	}
-	hostname, _ := os.Hostname()
+	hostname := d.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	if d.TLSConfig != nil && !isStream(network) {
+		return nil, errors.New("log/syslog: TLS requires a stream network")
+	}
 
 	w := &Writer{
-		priority: priority,
-		tag:      tag,
-		hostname: hostname,
-		network:  network,
-		raddr:    raddr,
+		priority:  priority,
+		tag:       tag,
+		hostname:  hostname,
+		network:   network,
+		raddr:     raddr,
+		format:    d.Format,
+		tlsConfig: d.TLSConfig,
+		timeout:   d.Timeout,
+		bufSize:   d.BufferSize,
 	}
 
 	w.mu.Lock()
@@ -159,145 +191,232 @@ func (w *Writer) connect() (err error) {
 		}
 	} else {
 		var c net.Conn
-		c, err = net.Dial(w.network, w.raddr)
+		d := &net.Dialer{Timeout: w.timeout}
+		if w.tlsConfig != nil {
+			c, err = tls.DialWithDialer(d, w.network, w.raddr, w.tlsConfig)
+		} else {
+			c, err = d.Dial(w.network, w.raddr)
+		}
 		if err == nil {
 			w.conn = &netConn{
-				conn:  c,
-				local: w.network == "unixgram" || w.network == "unix",
+				conn:   c,
+				local:  w.network == "unixgram" || w.network == "unix",
+				stream: isStream(w.network),
 			}
 			if w.hostname == "" {
 				w.hostname = c.LocalAddr().String()
 			}
 		}
 	}
+	// RFC 5425 requires octet counting over TLS; RFC 6587 describes
+	// it for other stream transports, including the local socket,
+	// as RFC 5424 messages may contain newlines.
+	nc, ok := w.conn.(*netConn)
+	w.framed = err == nil && ok && (w.tlsConfig != nil || w.format == RFC5424 && nc.stream)
 	return
 }
 
+// isStream reports whether network is a stream-oriented network.
+func isStream(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
+
 // Write sends a log message to the syslog daemon.
 func (w *Writer) Write(b []byte) (int, error) {
-	return w.writeAndRetry(w.priority, string(b))
+	return w.writeAndRetry(w.priority, &Message{Text: string(b)})
 }
 
-// Close closes a connection to the syslog daemon.
+// WriteMessage sends m to the syslog daemon. The facility of the
+// message is the one passed to [Dial], and its severity is m.Severity.
+func (w *Writer) WriteMessage(m *Message) error {
+	_, err := w.writeAndRetry(m.Severity, m)
+	return err
+}
+
+// Close closes a connection to the syslog daemon. If messages are
+// buffered, Close first tries to send them, and reports an error
+// if it cannot.
 func (w *Writer) Close() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	var err error
+	if len(w.pending) > 0 {
+		if w.conn == nil {
+			err = w.connect()
+		}
+		if err == nil {
+			err = w.flushPending()
+		}
+		if err != nil {
+			err = fmt.Errorf("log/syslog: %d buffered messages not sent: %w", len(w.pending), err)
+		}
+		w.pending = nil
+	}
 	if w.conn != nil {
-		err := w.conn.close()
+		if cerr := w.conn.close(); err == nil {
+			err = cerr
+		}
 		w.conn = nil
-		return err
 	}
-	return nil
+	return err
 }
 
 // Emerg logs a message with severity [LOG_EMERG], ignoring the severity
 // passed to New.
 func (w *Writer) Emerg(m string) error {
-	_, err := w.writeAndRetry(LOG_EMERG, m)
+	_, err := w.writeAndRetry(LOG_EMERG, &Message{Text: m})
 	return err
 }
 
 // Alert logs a message with severity [LOG_ALERT], ignoring the severity
 // passed to New.
 func (w *Writer) Alert(m string) error {
-	_, err := w.writeAndRetry(LOG_ALERT, m)
+	_, err := w.writeAndRetry(LOG_ALERT, &Message{Text: m})
 	return err
 }
 
 // Crit logs a message with severity [LOG_CRIT], ignoring the severity
 // passed to New.
 func (w *Writer) Crit(m string) error {
-	_, err := w.writeAndRetry(LOG_CRIT, m)
+	_, err := w.writeAndRetry(LOG_CRIT, &Message{Text: m})
 	return err
 }
 
 // Err logs a message with severity [LOG_ERR], ignoring the severity
 // passed to New.
 func (w *Writer) Err(m string) error {
-	_, err := w.writeAndRetry(LOG_ERR, m)
+	_, err := w.writeAndRetry(LOG_ERR, &Message{Text: m})
 	return err
 }
 
 // Warning logs a message with severity [LOG_WARNING], ignoring the
 // severity passed to New.
 func (w *Writer) Warning(m string) error {
-	_, err := w.writeAndRetry(LOG_WARNING, m)
+	_, err := w.writeAndRetry(LOG_WARNING, &Message{Text: m})
 	return err
 }
 
 // Notice logs a message with severity [LOG_NOTICE], ignoring the
 // severity passed to New.
 func (w *Writer) Notice(m string) error {
-	_, err := w.writeAndRetry(LOG_NOTICE, m)
+	_, err := w.writeAndRetry(LOG_NOTICE, &Message{Text: m})
 	return err
 }
 
 // Info logs a message with severity [LOG_INFO], ignoring the severity
 // passed to New.
 func (w *Writer) Info(m string) error {
-	_, err := w.writeAndRetry(LOG_INFO, m)
+	_, err := w.writeAndRetry(LOG_INFO, &Message{Text: m})
 	return err
 }
 
 // Debug logs a message with severity [LOG_DEBUG], ignoring the severity
 // passed to New.
 func (w *Writer) Debug(m string) error {
-	_, err := w.writeAndRetry(LOG_DEBUG, m)
+	_, err := w.writeAndRetry(LOG_DEBUG, &Message{Text: m})
 	return err
 }
 
-func (w *Writer) writeAndRetry(p Priority, s string) (int, error) {
+func (w *Writer) writeAndRetry(p Priority, m *Message) (int, error) {
 	pr := (w.priority & facilityMask) | (p & severityMask)
 
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if w.conn != nil {
-		if n, err := w.write(pr, s); err == nil {
+	if w.conn != nil && len(w.pending) == 0 {
+		if n, err := w.write(pr, m); err == nil {
 			return n, nil
 		}
 	}
-	if err := w.connect(); err != nil {
+	err := w.connect()
+	if err == nil {
+		err = w.flushPending()
+	}
+	if err == nil {
+		var n int
+		if n, err = w.write(pr, m); err == nil {
+			return n, nil
+		}
+	}
+	if w.bufSize <= 0 {
 		return 0, err
 	}
-	return w.write(pr, s)
+
+	// Keep the message until the server can be reached again.
+	pm := pendingMessage{pr, *m}
+	if pm.m.Time.IsZero() {
+		pm.m.Time = time.Now()
+	}
+	if len(w.pending) == w.bufSize {
+		w.pending = append(w.pending[:0], w.pending[1:]...)
+	}
+	w.pending = append(w.pending, pm)
+	return len(m.Text), nil
+}
+
+// flushPending writes the buffered messages.
+// It must be called with w.mu held.
+func (w *Writer) flushPending() error {
+	for len(w.pending) > 0 {
+		if _, err := w.write(w.pending[0].p, &w.pending[0].m); err != nil {
+			return err
+		}
+		w.pending = w.pending[1:]
+	}
+	w.pending = nil
+	return nil
 }
 
-// write generates and writes a syslog formatted string. The
-// format is as follows: <PRI>TIMESTAMP HOSTNAME TAG[PID]: MSG
-func (w *Writer) write(p Priority, msg string) (int, error) {
-	// ensure it ends in a \n
-	nl := ""
-	if !strings.HasSuffix(msg, "\n") {
-		nl = "\n"
+// write generates and writes a syslog formatted string. In RFC 3164
+// format, it is as follows: <PRI>TIMESTAMP HOSTNAME TAG[PID]: MSG
+func (w *Writer) write(p Priority, m *Message) (int, error) {
+	nc, ok := w.conn.(*netConn)
+	if !ok {
+		// ensure it ends in a \n
+		msg := m.text3164()
+		nl := ""
+		if !strings.HasSuffix(msg, "\n") {
+			nl = "\n"
+		}
+		if err := w.conn.writeString(p, w.hostname, w.tag, msg, nl); err != nil {
+			return 0, err
+		}
+		return len(m.Text), nil
 	}
 
-	err := w.conn.writeString(p, w.hostname, w.tag, msg, nl)
-	if err != nil {
+	var b []byte
+	if w.framed {
+		// Reserve room for the length prefix.
+		b = make([]byte, 10, 128)
+	}
+	start := len(b)
+	if w.format == RFC5424 {
+		b = m.append5424(b, p, w.hostname, w.tag)
+	} else {
+		b = m.append3164(b, p, w.hostname, w.tag, nc.local, !w.framed)
+	}
+	if w.framed {
+		prefix := strconv.AppendInt(nil, int64(len(b)-start), 10)
+		prefix = append(prefix, ' ')
+		start -= len(prefix)
+		copy(b[start:], prefix)
+	}
+	if _, err := nc.conn.Write(b[start:]); err != nil {
 		return 0, err
 	}
 	// Note: return the length of the input, not the number of
-	// bytes printed by Fprintf, because this must behave like
-	// an io.Writer.
-	return len(msg), nil
+	// bytes written, because this must behave like an io.Writer.
+	return len(m.Text), nil
 }
 
 func (n *netConn) writeString(p Priority, hostname, tag, msg, nl string) error {
-	if n.local {
-		// Compared to the network form below, the changes are:
-		//	1. Use time.Stamp instead of time.RFC3339.
-		//	2. Drop the hostname field from the Fprintf.
-		timestamp := time.Now().Format(time.Stamp)
-		_, err := fmt.Fprintf(n.conn, "<%d>%s %s[%d]: %s%s",
-			p, timestamp,
-			tag, os.Getpid(), msg, nl)
-		return err
-	}
-	timestamp := time.Now().Format(time.RFC3339)
-	_, err := fmt.Fprintf(n.conn, "<%d>%s %s %s[%d]: %s%s",
-		p, timestamp, hostname,
-		tag, os.Getpid(), msg, nl)
+	m := &Message{Text: msg + nl}
+	_, err := n.conn.Write(m.append3164(nil, p, hostname, tag, n.local, false))
 	return err
 }
 
@@ -11,17 +11,20 @@ import (
 	"net"
 )
 
+// logPaths are the paths of the local syslog daemon's socket.
+// It is a variable for testing.
+var logPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
 // unixSyslog opens a connection to the syslog daemon running on the
 // local machine using a Unix domain socket.
 
 func unixSyslog() (conn serverConn, err error) {
 	logTypes := []string{"unixgram", "unix"}
-	logPaths := []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
 	for _, network := range logTypes {
 		for _, path := range logPaths {
 			conn, err := net.Dial(network, path)
 			if err == nil {
-				return &netConn{conn: conn, local: true}, nil
+				return &netConn{conn: conn, local: true, stream: network == "unix"}, nil
 			}
 		}
 	}
@@ -445,3 +445,22 @@ func ExampleRegexp_FindAllIndex() {
 	// [[1 3]]
 	// [[1 3] [4 6]]
 }
+
+func ExampleSet() {
+	set := regexp.MustCompileSet([]string{
+		`^GET `,
+		`\b5\d\d\b`,
+		`timeout`,
+	})
+	for _, line := range []string{
+		"GET /index.html 200",
+		"GET /api 503 upstream timeout",
+		"POST /login 401",
+	} {
+		fmt.Println(set.MatchString(line))
+	}
+	// Output:
+	// [0]
+	// [0 1 2]
+	// []
+}
@@ -0,0 +1,376 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package regexp
+
+import (
+	"io"
+	"regexp/syntax"
+	"slices"
+	"sync"
+	"unicode/utf8"
+)
+
+// A Set is a collection of regular expressions that are matched
+// against a text together, in a single pass over the input.
+// Matching reports which of the expressions match somewhere in
+// the text, in time linear in the size of the input and in the
+// total size of the expressions, however many expressions there are.
+//
+// A Set is safe for concurrent use by multiple goroutines.
+type Set struct {
+	exprs    []string
+	prog     *syntax.Prog // program for all the expressions, see CompileSet
+	anchored bool         // all expressions match only at the beginning of the text
+	start    setStart     // where execution paths start
+	pool     sync.Pool    // of *setMachine
+}
+
+// A setStart indexes the instructions at which the execution paths of
+// a Set start by the first rune they accept, so that starting a new
+// path at each position of the input does not cost time proportional
+// to the number of expressions.
+//
+// This applies to an expression whose first instructions do not depend
+// on empty-width conditions. Such an expression contributes the rune
+// instructions reachable from its start to ascii and nonASCII; it is
+// in always if it matches the empty string. The start instructions of
+// the other expressions are in dynamic, and paths are started from
+// them with machine-style add at every position.
+type setStart struct {
+	ascii    [utf8.RuneSelf][]uint32 // rune instructions that can match each ASCII rune
+	nonASCII []uint32                // rune instructions that can match other runes
+	always   []int                   // expressions matching the empty string
+	dynamic  []uint32                // start instructions of the other expressions
+}
+
+// CompileSet parses the regular expressions in exprs and returns,
+// if successful, a [Set] that matches them all. The expressions use
+// the same syntax as [Compile]; the first invalid one is reported.
+func CompileSet(exprs []string) (*Set, error) {
+	set := &Set{
+		exprs:    exprs,
+		prog:     &syntax.Prog{Inst: []syntax.Inst{{Op: syntax.InstFail}}},
+		anchored: len(exprs) > 0,
+	}
+	for i, expr := range exprs {
+		re, err := syntax.Parse(expr, syntax.Perl)
+		if err != nil {
+			return nil, err
+		}
+		prog, err := syntax.Compile(re.Simplify())
+		if err != nil {
+			return nil, err
+		}
+		if prog.StartCond()&syntax.EmptyBeginText == 0 {
+			set.anchored = false
+		}
+		set.indexStart(set.appendProg(prog, i), i)
+	}
+	return set, nil
+}
+
+// MustCompileSet is like [CompileSet] but panics if an expression
+// cannot be parsed.
+func MustCompileSet(exprs []string) *Set {
+	set, err := CompileSet(exprs)
+	if err != nil {
+		panic(`regexp: CompileSet: ` + err.Error())
+	}
+	return set
+}
+
+// appendProg appends the instructions of prog, the program for the
+// expression with the given index, to the program of the set and
+// returns the new location of its start instruction.
+// Match instructions record the index in their Arg field,
+// which is otherwise unused.
+func (set *Set) appendProg(prog *syntax.Prog, index int) uint32 {
+	base := uint32(len(set.prog.Inst))
+	reloc := func(pc uint32) uint32 {
+		if pc == 0 {
+			// Instruction 0 always fails.
+			return 0
+		}
+		return base + pc
+	}
+	for _, inst := range prog.Inst {
+		inst.Out = reloc(inst.Out)
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			inst.Arg = reloc(inst.Arg)
+		case syntax.InstMatch:
+			inst.Arg = uint32(index)
+		}
+		set.prog.Inst = append(set.prog.Inst, inst)
+	}
+	return reloc(uint32(prog.Start))
+}
+
+// indexStart adds the expression with the given index,
+// whose program starts at start, to set.start.
+func (set *Set) indexStart(start uint32, index int) {
+	// Collect the instructions reachable from start without consuming input.
+	var insts []uint32
+	seen := make(map[uint32]bool)
+	stack := []uint32{start}
+	for len(stack) > 0 {
+		pc := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if pc == 0 || seen[pc] {
+			continue
+		}
+		seen[pc] = true
+		i := &set.prog.Inst[pc]
+		switch i.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			stack = append(stack, i.Arg, i.Out)
+		case syntax.InstNop, syntax.InstCapture:
+			stack = append(stack, i.Out)
+		case syntax.InstEmptyWidth:
+			// The paths of the whole expression must be started
+			// with add, which evaluates the condition.
+			set.start.dynamic = append(set.start.dynamic, start)
+			return
+		case syntax.InstMatch, syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			insts = append(insts, pc)
+		}
+	}
+
+	for _, pc := range insts {
+		i := &set.prog.Inst[pc]
+		switch i.Op {
+		case syntax.InstMatch:
+			if !slices.Contains(set.start.always, index) {
+				set.start.always = append(set.start.always, index)
+			}
+			continue
+		case syntax.InstRune1:
+			if r := i.Rune[0]; r < utf8.RuneSelf {
+				set.start.ascii[r] = append(set.start.ascii[r], pc)
+				continue
+			}
+		default:
+			for r := range rune(utf8.RuneSelf) {
+				if i.Op == syntax.InstRuneAny || i.Op == syntax.InstRuneAnyNotNL && r != '\n' || i.Op == syntax.InstRune && i.MatchRune(r) {
+					set.start.ascii[r] = append(set.start.ascii[r], pc)
+				}
+			}
+		}
+		// Be conservative about runes outside ASCII, including
+		// utf8.RuneError for invalid input.
+		set.start.nonASCII = append(set.start.nonASCII, pc)
+	}
+}
+
+// Len returns the number of expressions in the set.
+func (set *Set) Len() int {
+	return len(set.exprs)
+}
+
+// Expr returns the source text of the i'th expression in the set.
+func (set *Set) Expr(i int) string {
+	return set.exprs[i]
+}
+
+// Match reports which expressions of the set match somewhere in b.
+// It returns the indexes of the matching expressions, in increasing
+// order, or nil if none of them match.
+func (set *Set) Match(b []byte) []int {
+	return set.doMatch(nil, b, "")
+}
+
+// MatchString reports which expressions of the set match somewhere in s.
+// It returns the indexes of the matching expressions, in increasing
+// order, or nil if none of them match.
+func (set *Set) MatchString(s string) []int {
+	return set.doMatch(nil, nil, s)
+}
+
+// MatchReader reports which expressions of the set match somewhere
+// in the text read from the [io.RuneReader]. It returns the indexes of
+// the matching expressions, in increasing order, or nil if none of
+// them match. Unless all the expressions match, MatchReader reads
+// the whole text.
+func (set *Set) MatchReader(r io.RuneReader) []int {
+	return set.doMatch(r, nil, "")
+}
+
+// A setMachine holds the state during an NFA simulation of a Set.
+// It uses the queues of [machine], without threads: as no submatch
+// positions are tracked, the pc is all the state of an execution path.
+type setMachine struct {
+	q0, q1   queue
+	matched  []bool // matched[i] reports whether expression i matched
+	nmatched int
+
+	inputs inputs
+}
+
+func (set *Set) get() *setMachine {
+	m, ok := set.pool.Get().(*setMachine)
+	if !ok {
+		n := len(set.prog.Inst)
+		m = &setMachine{
+			q0:      queue{make([]uint32, n), make([]entry, 0, n)},
+			q1:      queue{make([]uint32, n), make([]entry, 0, n)},
+			matched: make([]bool, len(set.exprs)),
+		}
+	}
+	return m
+}
+
+func (set *Set) put(m *setMachine) {
+	clear(m.matched)
+	m.nmatched = 0
+	m.q0.dense = m.q0.dense[:0]
+	m.q1.dense = m.q1.dense[:0]
+	m.inputs.clear()
+	set.pool.Put(m)
+}
+
+// doMatch runs the set over either r, b or s.
+// Unlike machine.match, it does not stop at the first match: it adds
+// a new execution path at every position until all the expressions
+// have matched or, if they are anchored, until the first position
+// has been explored.
+func (set *Set) doMatch(r io.RuneReader, b []byte, s string) []int {
+	if len(set.exprs) == 0 {
+		return nil
+	}
+	m := set.get()
+	i, _ := m.inputs.init(r, b, s)
+	for _, index := range set.start.always {
+		m.matched[index] = true
+		m.nmatched++
+	}
+
+	runq, nextq := &m.q0, &m.q1
+	pos := 0
+	r0, width := i.step(pos)
+	r1, width1 := endOfText, 0
+	if r0 != endOfText {
+		r1, width1 = i.step(pos + width)
+	}
+	flag := newLazyFlag(-1, r0)
+	for {
+		if len(runq.dense) == 0 && set.anchored && pos != 0 {
+			break
+		}
+		if !set.anchored || pos == 0 {
+			set.addStart(runq, r0, &flag)
+		}
+		flag = newLazyFlag(r0, r1)
+		set.step(m, runq, nextq, r0, &flag)
+		if width == 0 || m.nmatched == len(m.matched) {
+			break
+		}
+		pos += width
+		r0, width = r1, width1
+		if r0 != endOfText {
+			r1, width1 = i.step(pos + width)
+		}
+		runq, nextq = nextq, runq
+	}
+
+	var matches []int
+	for i, ok := range m.matched {
+		if ok {
+			matches = append(matches, i)
+		}
+	}
+	set.put(m)
+	return matches
+}
+
+// addStart adds to q the entries starting new execution paths
+// at a position where the next rune is c.
+func (set *Set) addStart(q *queue, c rune, cond *lazyFlag) {
+	var pcs []uint32
+	switch {
+	case c == endOfText:
+		// No rune instruction can match.
+	case 0 <= c && c < utf8.RuneSelf:
+		pcs = set.start.ascii[c]
+	default:
+		pcs = set.start.nonASCII
+	}
+	for _, pc := range pcs {
+		set.add(q, pc, cond)
+	}
+	for _, pc := range set.start.dynamic {
+		set.add(q, pc, cond)
+	}
+}
+
+// step executes one step of the machine, running each of the execution
+// paths on runq and appending the paths that survive the rune c (which
+// may be endOfText) to nextq. Reaching a match instruction records a
+// match of the corresponding expression.
+// nextCond gives the setting for the empty-width flags after c.
+func (set *Set) step(m *setMachine, runq, nextq *queue, c rune, nextCond *lazyFlag) {
+	for _, d := range runq.dense {
+		i := &set.prog.Inst[d.pc]
+		add := false
+		switch i.Op {
+		case syntax.InstMatch:
+			if !m.matched[i.Arg] {
+				m.matched[i.Arg] = true
+				m.nmatched++
+			}
+		case syntax.InstRune:
+			add = i.MatchRune(c)
+		case syntax.InstRune1:
+			add = c == i.Rune[0]
+		case syntax.InstRuneAny:
+			add = true
+		case syntax.InstRuneAnyNotNL:
+			add = c != '\n'
+		}
+		if add {
+			set.add(nextq, i.Out, nextCond)
+		}
+	}
+	runq.dense = runq.dense[:0]
+}
+
+// add adds an entry to q for pc, unless the q already has such an entry.
+// It also recursively adds an entry for all instructions reachable from pc
+// by following empty-width conditions satisfied by cond.
+// Only the entries of match and rune instructions are run by step.
+func (set *Set) add(q *queue, pc uint32, cond *lazyFlag) {
+Again:
+	if pc == 0 {
+		return
+	}
+	if j := q.sparse[pc]; j < uint32(len(q.dense)) && q.dense[j].pc == pc {
+		return
+	}
+	j := len(q.dense)
+	q.dense = q.dense[:j+1]
+	q.dense[j] = entry{pc: pc}
+	q.sparse[pc] = uint32(j)
+
+	i := &set.prog.Inst[pc]
+	switch i.Op {
+	default:
+		panic("unhandled")
+	case syntax.InstFail:
+		// nothing
+	case syntax.InstAlt, syntax.InstAltMatch:
+		set.add(q, i.Out, cond)
+		pc = i.Arg
+		goto Again
+	case syntax.InstEmptyWidth:
+		if cond.match(syntax.EmptyOp(i.Arg)) {
+			pc = i.Out
+			goto Again
+		}
+	case syntax.InstNop, syntax.InstCapture:
+		pc = i.Out
+		goto Again
+	case syntax.InstMatch, syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+		// run by step
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package regexp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var setTests = []struct {
+	exprs []string
+	text  string
+	want  []int
+}{
+	{nil, "abc", nil},
+	{[]string{"a", "b", "c"}, "", nil},
+	{[]string{"a", "b", "c"}, "b", []int{1}},
+	{[]string{"a", "b", "c"}, "xcxa", []int{0, 2}},
+	{[]string{"", "x"}, "", []int{0}},
+	{[]string{"^abc", "abc$", `\babc\b`}, "xabc", []int{1}},
+	{[]string{"^abc", "abc$", `\babc\b`}, "abc abcd", []int{0, 2}},
+	{[]string{"^a", "^b"}, "ba", []int{1}},
+	{[]string{`(?m)^b`, `\Ab`}, "a\nb", []int{0}},
+	{[]string{"a+b+", "(a|b)*c", "x*"}, "aaab", []int{0, 2}},
+	{[]string{"[α-ω]+", `\p{Han}`}, "αβγ 中", []int{0, 1}},
+	{[]string{"(?i)hello", "hello"}, "HeLLo", []int{0}},
+	{[]string{"a.c", "(?s)a.c"}, "a\nc", []int{1}},
+	{[]string{`\x{FFFD}`, ".", "a"}, "\xff", []int{0, 1}},
+	{[]string{`a|\bb`}, "xa", []int{0}},
+	{[]string{`\bb|a`}, "xa", []int{0}},
+	{[]string{`(?:^|x)a`, `(?:x|^)a`}, "xa", []int{0, 1}},
+	{[]string{`(?:x|\b)y`, `(?:\b|x)y`}, "xy", []int{0, 1}},
+}
+
+func TestSet(t *testing.T) {
+	for _, tt := range setTests {
+		set := MustCompileSet(tt.exprs)
+		if got := set.MatchString(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("%q.MatchString(%q) = %v, want %v", tt.exprs, tt.text, got, tt.want)
+		}
+		if got := set.Match([]byte(tt.text)); !slices.Equal(got, tt.want) {
+			t.Errorf("%q.Match(%q) = %v, want %v", tt.exprs, tt.text, got, tt.want)
+		}
+		if got := set.MatchReader(strings.NewReader(tt.text)); !slices.Equal(got, tt.want) {
+			t.Errorf("%q.MatchReader(%q) = %v, want %v", tt.exprs, tt.text, got, tt.want)
+		}
+	}
+}
+
+// TestSetFind checks that a set of all the expressions of findTests
+// agrees with matching them one by one.
+func TestSetFind(t *testing.T) {
+	var exprs []string
+	for _, test := range findTests {
+		if !slices.Contains(exprs, test.pat) {
+			exprs = append(exprs, test.pat)
+		}
+	}
+	set := MustCompileSet(exprs)
+	if set.Len() != len(exprs) {
+		t.Fatalf("Len() = %d, want %d", set.Len(), len(exprs))
+	}
+	for _, test := range findTests {
+		var want []int
+		for i, expr := range exprs {
+			if set.Expr(i) != expr {
+				t.Fatalf("Expr(%d) = %q, want %q", i, set.Expr(i), expr)
+			}
+			if MustCompile(expr).MatchString(test.text) {
+				want = append(want, i)
+			}
+		}
+		if got := set.MatchString(test.text); !slices.Equal(got, want) {
+			t.Errorf("MatchString(%q) = %v, want %v", test.text, got, want)
+		}
+	}
+}
+
+// setDiffExprs are expressions mixing empty-width assertions
+// with other alternatives, for TestSetMatchString.
+var setDiffExprs = []string{
+	`a|\bb`, `\bb|a`, `(?:^|x)a`, `(?:x|^)a`, `(?:x|\b)y`, `(?:\b|x)y`,
+	`^`, `$`, `^$`, `\b`, `\B`, `x$|^y`, `(?m)^a|b$`, `(?:\B|^)a`,
+	`(?:a|\Bb)c`, `a*\b`, `(?:$|a)`, `\Ba|^b`, `(?:a|)\b`,
+}
+
+var setDiffTexts = []string{
+	"", "a", "b", "xa", "xy", "ab", "ba", "bc", "abc", "yx", "a b",
+	"x\na", "xb\nb", "aab", " a", "\xffa", "ya", "xxy",
+}
+
+// TestSetMatchString checks that a set agrees with matching its
+// expressions one by one with [Regexp.MatchString], for each
+// expression alone and for all of them together.
+func TestSetMatchString(t *testing.T) {
+	exprs := slices.Clone(setDiffExprs)
+	for _, test := range findTests {
+		if !slices.Contains(exprs, test.pat) {
+			exprs = append(exprs, test.pat)
+		}
+	}
+	texts := slices.Clone(setDiffTexts)
+	for _, test := range findTests {
+		texts = append(texts, test.text)
+	}
+	res := make([]*Regexp, len(exprs))
+	for i, expr := range exprs {
+		res[i] = MustCompile(expr)
+	}
+	all := MustCompileSet(exprs)
+	for _, text := range texts {
+		var want []int
+		for i, re := range res {
+			matched := re.MatchString(text)
+			if matched {
+				want = append(want, i)
+			}
+			got := MustCompileSet([]string{exprs[i]}).MatchString(text)
+			if (len(got) > 0) != matched {
+				t.Errorf("[%q].MatchString(%q) = %v, want match %v", exprs[i], text, got, matched)
+			}
+		}
+		if got := all.MatchString(text); !slices.Equal(got, want) {
+			t.Errorf("MatchString(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestCompileSetError(t *testing.T) {
+	_, err := CompileSet([]string{"a", "b(", "c"})
+	if err == nil || !strings.Contains(err.Error(), "missing closing )") {
+		t.Errorf("CompileSet: got error %v, want missing closing )", err)
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	var exprs []string
+	for i := range 200 {
+		exprs = append(exprs, fmt.Sprintf(`error code=%d\b`, i))
+	}
+	set := MustCompileSet(exprs)
+	text := strings.Repeat("x", 100) + " error code=123 " + strings.Repeat("y", 100)
+	b.SetBytes(int64(len(text)))
+	for range b.N {
+		if m := set.MatchString(text); len(m) != 1 {
+			b.Fatalf("MatchString = %v", m)
+		}
+	}
+}
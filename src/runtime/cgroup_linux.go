@@ -0,0 +1,66 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"internal/runtime/cgroup"
+	"unsafe"
+)
+
+// cgroupCPU is the cgroup limiting the CPU bandwidth of the process,
+// found at startup.
+var cgroupCPU struct {
+	cpu cgroup.CPU
+	ok  bool
+
+	// lock protects buf, which is used to read the limit files
+	// without allocating, as sysmon does.
+	lock mutex
+	buf  [64]byte
+}
+
+// cgroupInit finds the cgroup limiting the CPU bandwidth of the process.
+// Called from schedinit, after parsedebugvars.
+func cgroupInit() {
+	lockInit(&cgroupCPU.lock, lockRankLeafRank)
+	if debug.containermaxprocs == 0 {
+		return
+	}
+	cgroupCPU.cpu, cgroupCPU.ok = cgroup.FindCPU("", readCgroupFile)
+}
+
+// cgroupCPULimit returns the CPU bandwidth limit of the cgroup of the
+// process, in CPUs, and whether it is limited.
+func cgroupCPULimit() (float64, bool) {
+	if !cgroupCPU.ok {
+		return 0, false
+	}
+	lock(&cgroupCPU.lock)
+	limit, ok := cgroupCPU.cpu.Limit(cgroupCPU.buf[:])
+	unlock(&cgroupCPU.lock)
+	return limit, ok
+}
+
+// readCgroupFile implements cgroup.ReadFunc.
+func readCgroupFile(path []byte, buf []byte) int {
+	fd := open(&path[0], _O_RDONLY|_O_CLOEXEC, 0)
+	if fd < 0 {
+		return -1
+	}
+	n := 0
+	for n < len(buf) {
+		r := read(fd, unsafe.Pointer(&buf[n]), int32(len(buf)-n))
+		if r < 0 {
+			closefd(fd)
+			return -1
+		}
+		if r == 0 {
+			break
+		}
+		n += int(r)
+	}
+	closefd(fd)
+	return n
+}
@@ -276,6 +276,7 @@ func chansend(c *hchan, ep unsafe.Pointer, block bool, callerpc uintptr) bool {
 		blockevent(mysg.releasetime-t0, 2)
 	}
 	mysg.c = nil
+	mysg.hidden = 0
 	releaseSudog(mysg)
 	if closed {
 		if c.closed == 0 {
@@ -643,6 +644,7 @@ func chanrecv(c *hchan, ep unsafe.Pointer, block bool) (selected, received bool)
 	success := mysg.success
 	gp.param = nil
 	mysg.c = nil
+	mysg.hidden = 0
 	releaseSudog(mysg)
 	return true, success
 }
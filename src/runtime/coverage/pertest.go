@@ -0,0 +1,140 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package coverage
+
+import (
+	"fmt"
+	"internal/coverage"
+	"internal/coverage/encodecounter"
+	"os"
+	"sync"
+)
+
+// This file contains support for "go test -coverpertest", which
+// writes a separate counter data file for each top-level test,
+// holding only the counter increments made while that test ran.
+
+var perTest struct {
+	sync.Mutex
+	// Directory to which the meta-data file has been written.
+	metaDir string
+	// Counter values at the end of the previous test, indexed by
+	// pkgid/funcid tuple.
+	prev map[pkfunc][]uint32
+}
+
+// writeTestCounters is called (via a linknamed reference) from
+// testmain code when "go test -coverpertest" is in effect, at the end
+// of each top-level test. It writes to dir a counter data file
+// holding the counter increments since the previous call, with the
+// package and test name recorded in the file's args section, along
+// with the meta-data file if not already present. If test is empty,
+// writeTestCounters only records the current counter values as the
+// baseline for the next test, so that code run before the first test
+// (package initialization, TestMain) is not attributed to it.
+func writeTestCounters(dir string, pkg string, test string) error {
+	perTest.Lock()
+	defer perTest.Unlock()
+
+	cl := getCovCounterList()
+	if len(cl) == 0 {
+		// no work to do here.
+		return nil
+	}
+	if cmode == coverage.CtrModeSet {
+		return fmt.Errorf("per-test coverage requires -covermode=count or atomic")
+	}
+
+	s := &emitState{
+		counterlist: cl,
+		pkgmap:      getCovPkgMap(),
+		outdir:      dir,
+		debug:       os.Getenv("GOCOVERDEBUG") != "",
+	}
+	v := &testCounterVisitor{s: s, prev: perTest.prev}
+	if test == "" {
+		// Record the baseline only.
+		if perTest.prev == nil {
+			v.prev = make(map[pkfunc][]uint32)
+		}
+		err := v.VisitFuncs(func(pkid, funcid uint32, counters []uint32) error {
+			return nil
+		})
+		perTest.prev = v.prev
+		return err
+	}
+
+	if perTest.metaDir != dir {
+		if err := emitMetaDataToDirectory(dir, getCovMetaList()); err != nil {
+			return err
+		}
+		perTest.metaDir = dir
+	}
+	if v.prev == nil {
+		v.prev = make(map[pkfunc][]uint32)
+	}
+
+	if err := s.openOutputFiles(finalHash, finalMetaLen, counterDataFile); err != nil {
+		return err
+	}
+	args := make(map[string]string, len(capturedOsArgs)+2)
+	for k, v := range capturedOsArgs {
+		args[k] = v
+	}
+	args["testpkg"] = pkg
+	args["testname"] = test
+	cfw := encodecounter.NewCoverageDataWriter(s.cf, coverage.CtrULeb128)
+	err := cfw.Write(finalHash, args, v)
+	perTest.prev = v.prev
+	if err != nil {
+		s.cf.Close()
+		return err
+	}
+	if err := s.cf.Close(); err != nil {
+		return fmt.Errorf("closing counter data file: %v", err)
+	}
+	if err := os.Rename(s.cftmp, s.cfname); err != nil {
+		return fmt.Errorf("writing %s: rename from %s failed: %v\n", s.cfname, s.cftmp, err)
+	}
+	return nil
+}
+
+// testCounterVisitor visits the functions whose counters have changed
+// since the values recorded in prev, passing the differences to the
+// callback and updating prev.
+type testCounterVisitor struct {
+	s    *emitState
+	prev map[pkfunc][]uint32
+}
+
+func (v *testCounterVisitor) VisitFuncs(f encodecounter.CounterVisitorFn) error {
+	var delta []uint32
+	return v.s.VisitFuncs(func(pkid, funcid uint32, counters []uint32) error {
+		key := pkfunc{pk: pkid, fcn: funcid}
+		prev := v.prev[key]
+		delta = delta[:0]
+		live := false
+		for i, c := range counters {
+			// A counter smaller than before has been reset
+			// by ClearCounters.
+			if i < len(prev) && c >= prev[i] {
+				c -= prev[i]
+			}
+			delta = append(delta, c)
+			if c != 0 {
+				live = true
+			}
+		}
+		if len(prev) != len(counters) {
+			prev = make([]uint32, len(counters))
+			v.prev[key] = prev
+		}
+		copy(prev, counters)
+		if !live {
+			return nil
+		}
+		return f(pkid, funcid, delta)
+	})
+}
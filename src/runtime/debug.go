@@ -10,8 +10,21 @@ import (
 )
 
 // GOMAXPROCS sets the maximum number of CPUs that can be executing
-// simultaneously and returns the previous setting. It defaults to
-// the value of [runtime.NumCPU]. If n < 1, it does not change the current setting.
+// simultaneously and returns the previous setting. If n < 1, it does not change
+// the current setting.
+//
+// The default GOMAXPROCS is the value of [runtime.NumCPU]. On Linux, if the
+// process belongs to a cgroup limiting its CPU bandwidth (through cpu.max with
+// cgroup v2, or cpu.cfs_quota_us and cpu.cfs_period_us with cgroup v1), of it
+// or of one of its ancestors, the default is the limit rounded up, if it is
+// lower, but not lower than 2. The runtime periodically checks the limit, and
+// updates GOMAXPROCS if its default changes, unless GOMAXPROCS was set
+// explicitly, with the GOMAXPROCS environment variable or by a call to
+// GOMAXPROCS. [SetDefaultGOMAXPROCS] restores the default.
+//
+// The limit is ignored with GODEBUG=containermaxprocs=0, and the periodic
+// updates are disabled with GODEBUG=updatemaxprocs=0.
+//
 // This call will go away when the scheduler improves.
 func GOMAXPROCS(n int) int {
 	if GOARCH == "wasm" && n > 1 {
@@ -20,6 +33,9 @@ func GOMAXPROCS(n int) int {
 
 	lock(&sched.lock)
 	ret := int(gomaxprocs)
+	if n > 0 {
+		sched.customGOMAXPROCS = true
+	}
 	unlock(&sched.lock)
 	if n <= 0 || n == ret {
 		return ret
@@ -34,6 +50,25 @@ func GOMAXPROCS(n int) int {
 	return ret
 }
 
+// SetDefaultGOMAXPROCS sets GOMAXPROCS to its default value, as
+// documented for [GOMAXPROCS], and lets the runtime update it again
+// when the default changes, as if GOMAXPROCS had never been set
+// explicitly.
+func SetDefaultGOMAXPROCS() {
+	// Reading the CPU limit does I/O, so do it before stopping the world.
+	procs := defaultGOMAXPROCS(ncpu)
+
+	stw := stopTheWorldGC(stwGOMAXPROCS)
+
+	lock(&sched.lock)
+	sched.customGOMAXPROCS = false
+	unlock(&sched.lock)
+	// newprocs will be processed by startTheWorld
+	newprocs = procs
+
+	startTheWorldGC(stw)
+}
+
 // NumCPU returns the number of logical CPUs usable by the current process.
 //
 // The set of available CPUs is checked by querying the operating system
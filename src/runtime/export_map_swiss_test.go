@@ -0,0 +1,65 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.swissmap
+
+// Export guts for testing.
+
+package runtime
+
+import "unsafe"
+
+func MapTombstones(m map[int]int) int {
+	h := *(**hmap)(unsafe.Pointer(&m))
+	return int(h.tombstones)
+}
+
+func MapGrowing(m map[int]int) bool {
+	h := *(**hmap)(unsafe.Pointer(&m))
+	return h.growing()
+}
+
+func MapTableCheck(m map[int]int) {
+	// Make sure the control bytes, the tombstone count and the
+	// probe sequences of the current table are consistent.
+	h := *(**hmap)(unsafe.Pointer(&m))
+	i := any(m)
+	t := *(**maptype)(unsafe.Pointer(&i))
+
+	if h.buckets == nil {
+		return
+	}
+	full, deleted := 0, 0
+	for x := uintptr(0); x < bucketShift(h.B); x++ {
+		g := (*bmap)(add(h.buckets, x*uintptr(t.BucketSize)))
+		for i := uintptr(0); i < groupSlots; i++ {
+			switch c := g.ctrl[i]; {
+			case c == ctrlEmpty:
+			case c == ctrlDeleted:
+				deleted++
+			case c&ctrlFull != 0:
+				full++
+				k := g.key(t, i)
+				hash := t.Hasher(k, uintptr(h.hash0))
+				if c != ctrlTop(hash) {
+					panic("control byte does not match the hash of the key")
+				}
+				if fg, fi := find(t, h.buckets, h.B, hash, k); fg != g || fi != i {
+					panic("key not found along its probe sequence")
+				}
+			default:
+				panic("bad control byte")
+			}
+		}
+	}
+	if deleted != int(h.tombstones) {
+		panic("wrong number of tombstones")
+	}
+	if !h.growing() && full != h.count {
+		panic("wrong number of entries")
+	}
+	if uintptr(full+deleted) > maxLoad(h.B) {
+		panic("table over its maximum load")
+	}
+}
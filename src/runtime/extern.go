@@ -55,6 +55,9 @@ It is a comma-separated list of name=val pairs setting these named variables:
 	cgocheck mode can be enabled using GOEXPERIMENT (which
 	requires a rebuild), see https://pkg.go.dev/internal/goexperiment for details.
 
+	containermaxprocs: setting containermaxprocs=0 makes the default GOMAXPROCS
+	ignore the CPU bandwidth limit of the Linux cgroup of the process.
+
 	disablethp: setting disablethp=1 on Linux disables transparent huge pages for the heap.
 	It has no effect on other platforms. disablethp is meant for compatibility with versions
 	of Go before 1.21, which stopped working around a Linux kernel default that can result
@@ -218,6 +221,10 @@ It is a comma-separated list of name=val pairs setting these named variables:
 	because it also disables the conservative stack scanning used
 	for asynchronously preempted goroutines.
 
+	updatemaxprocs: setting updatemaxprocs=0 disables the periodic update of
+	GOMAXPROCS when its default value changes, for example when the CPU
+	bandwidth limit of the cgroup of the process changes.
+
 The [net] and [net/http] packages also refer to debugging variables in GODEBUG.
 See the documentation for those packages for details.
 
@@ -225,7 +232,7 @@ The GOMAXPROCS variable limits the number of operating system threads that
 can execute user-level Go code simultaneously. There is no limit to the number of threads
 that can be blocked in system calls on behalf of Go code; those do not count against
 the GOMAXPROCS limit. This package's [GOMAXPROCS] function queries and changes
-the limit.
+the limit, and documents its default value.
 
 The GORACE variable configures the race detector, for programs built using -race.
 See the [Race Detector article] for details.
@@ -0,0 +1,1129 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.swissmap
+
+package runtime
+
+// This file contains the implementation of Go's map type as a Swiss
+// table. It replaces the one in map.go when GOEXPERIMENT=swissmap.
+//
+// A map is an open-addressing hash table. Its slots are arranged in
+// groups of 8, and each group has 8 control bytes, one per slot. A
+// control byte tells whether its slot is empty, deleted (a tombstone),
+// or full, in which case it holds 7 bits of the hash of the key (H2).
+// The low-order bits of the hash (H1) select the group where the probe
+// sequence of the key starts. A lookup compares H2 with all the
+// control bytes of a group at once, with integer arithmetic on a
+// single 64-bit word (SWAR, "SIMD within a register"); no vector
+// instructions are used on any architecture. It then only compares
+// the keys of the matching slots. If the key is not there and
+// the group has no empty slot, the lookup continues with the next
+// group in the probe sequence, which visits all groups in triangular
+// steps, until it has visited them all.
+//
+// Groups have the layout of the buckets of map.go, which the compiler
+// generates for each map type: the tophash bytes serve as control
+// bytes and the overflow pointer is unused. The hmap and hiter structs
+// also keep the layout the compiler knows about.
+//
+// The table grows when 7/8 of its slots are full or deleted, or when
+// its only group is. If most
+// of those are tombstones, it is rehashed to a table of the same size
+// instead. Growth is incremental: each write to the map copies a couple
+// of groups of the old table to the new one, and until all are copied,
+// lookups look at the new table first, then at the old one. Entries
+// are copied rather than moved, and the old table is only modified to
+// update or delete entries, so that iterators can keep walking it.
+//
+// Map iterators walk the groups of the table the map had when they
+// started, from a random group and slot. Once the map has grown, the
+// entries of that table may be stale, so the iterator looks up each key
+// in the map to return its current value, or skip it if it was deleted.
+// Iterators started during growth walk the old table first, then the
+// new one, skipping the keys they found in the old one.
+
+import (
+	"internal/abi"
+	"internal/goarch"
+	"runtime/internal/math"
+	"runtime/internal/sys"
+	"unsafe"
+)
+
+const (
+	// Number of slots in a group.
+	groupSlots = abi.MapBucketCount
+
+	// data offset should be the size of the bmap struct, but needs to be
+	// aligned correctly. For amd64p32 this means 64-bit alignment
+	// even though pointers are 32 bit.
+	dataOffset = unsafe.Offsetof(struct {
+		b bmap
+		v int64
+	}{}.v)
+
+	// Control bytes. Zeroed memory is a group of empty slots.
+	ctrlEmpty   = 0x00 // this slot is empty
+	ctrlDeleted = 0x01 // this slot is empty, but was full when its group was full
+	ctrlFull    = 0x80 // this slot is full; the low 7 bits hold H2
+
+	// The low bit of H2 of keys that are not equal to themselves (NaNs),
+	// set when they are copied to a new table by growth, as their hash is
+	// different each time. Iterators use it to skip the copies of the NaNs
+	// they returned from the old table.
+	ctrlCopied = 0x01
+
+	// flags
+	hashWriting = 4 // a goroutine is writing to the map
+)
+
+// exported value for testing
+const hashLoad = float32(groupSlots) * 7 / 8
+
+// A header for a Go map.
+type hmap struct {
+	// Note: the format of the hmap is also encoded in cmd/compile/internal/reflectdata/reflect.go.
+	// Make sure this stays in sync with the compiler's definition.
+	count int // # live cells == size of map.  Must be first (used by len() builtin)
+	flags uint8
+	B     uint8 // log_2 of # of groups (can hold up to 7/8 * 8 * 2^B items, or 8 if B == 0)
+	oldB  uint8 // log_2 of # of groups of oldbuckets
+	_     uint8
+	hash0 uint32 // hash seed
+
+	buckets    unsafe.Pointer // array of 2^B groups. may be nil if count==0.
+	oldbuckets unsafe.Pointer // previous array of groups, non-nil only when growing
+	tombstones uintptr        // # deleted slots in buckets
+
+	extra *mapextra // optional fields
+}
+
+// mapextra holds fields that are only needed by maps that have grown.
+type mapextra struct {
+	// nevacuate is the progress counter for growth: groups of oldbuckets
+	// less than this have been copied to buckets.
+	nevacuate uintptr
+}
+
+// A group of slots of a Go map.
+type bmap struct {
+	// ctrl holds the control byte of each slot of the group.
+	ctrl [groupSlots]uint8
+	// Followed by groupSlots keys and then groupSlots elems,
+	// and an unused overflow pointer.
+}
+
+// A hash iteration structure.
+// If you modify hiter, also change cmd/compile/internal/reflectdata/reflect.go
+// and reflect/value.go to match the layout of this structure.
+type hiter struct {
+	key        unsafe.Pointer // Must be in first position.  Write nil to indicate iteration end (see cmd/compile/internal/walk/range.go).
+	elem       unsafe.Pointer // Must be in second position (see cmd/compile/internal/walk/range.go).
+	t          *maptype
+	h          *hmap
+	buckets    unsafe.Pointer // groups of the map at hash_iter initialization time
+	oldbuckets unsafe.Pointer // old groups of the map at hash_iter initialization time, if it was growing
+	_          unsafe.Pointer
+	_          unsafe.Pointer
+	startGroup uintptr // group iteration started at
+	offset     uint8   // intra-group offset to start from during iteration (should be big enough to hold groupSlots-1)
+	B          uint8
+	oldB       uint8
+	i          uint8   // # slots of the current group already walked
+	n          uintptr // # groups already walked, old ones first
+	seed       uintptr // hash seed of the map at hash_iter initialization time
+}
+
+// bucketShift returns 1<<b, optimized for code generation.
+func bucketShift(b uint8) uintptr {
+	// Masking the shift amount allows overflow checks to be elided.
+	return uintptr(1) << (b & (goarch.PtrSize*8 - 1))
+}
+
+// bucketMask returns 1<<b - 1, optimized for code generation.
+func bucketMask(b uint8) uintptr {
+	return bucketShift(b) - 1
+}
+
+// maxLoad returns the number of slots that may be full or deleted in
+// a table of 1<<b groups. Larger tables always have an empty slot left,
+// so that probe sequences end before visiting all the groups.
+func maxLoad(b uint8) uintptr {
+	if b == 0 {
+		// A single group may be full.
+		return groupSlots
+	}
+	return bucketShift(b) * groupSlots * 7 / 8
+}
+
+// overLoadFactor reports whether count items placed in 1<<B groups is over the maximum load.
+func overLoadFactor(count int, B uint8) bool {
+	return count > groupSlots && uintptr(count) > maxLoad(B)
+}
+
+// ctrlTop returns the control byte of a full slot with a key of the given hash.
+func ctrlTop(hash uintptr) uint8 {
+	return ctrlFull | uint8(hash>>(goarch.PtrSize*8-7))
+}
+
+// A ctrlGroup holds the control bytes of a group, slot i in byte i,
+// counting from the least significant.
+type ctrlGroup uint64
+
+// A bitset has the high bit of the bytes of the matching slots set.
+type bitset uint64
+
+const (
+	bitsetLSB = 0x0101010101010101
+	bitsetMSB = 0x8080808080808080
+)
+
+// first returns the index of the first slot in b.
+func (b bitset) first() uintptr {
+	return uintptr(sys.TrailingZeros64(uint64(b))) >> 3
+}
+
+// removeFirst returns b without its first slot.
+func (b bitset) removeFirst() bitset {
+	return b & (b - 1)
+}
+
+// matchTop returns the slots with control byte top.
+// It may also return full slots with a different control byte,
+// which callers eliminate by comparing keys.
+func (c ctrlGroup) matchTop(top uint8) bitset {
+	// Bytes equal to top are zero in v. Subtracting one from them
+	// sets their high bit; it may also propagate a borrow to the next
+	// byte, which then is top^1, a full slot.
+	v := uint64(c) ^ (bitsetLSB * uint64(top))
+	return bitset((v - bitsetLSB) &^ v & bitsetMSB)
+}
+
+// matchEmpty returns the empty slots.
+func (c ctrlGroup) matchEmpty() bitset {
+	// Empty is the only control byte with both its high and low bits clear.
+	v := ^uint64(c)
+	return bitset(v & (v << 7) & bitsetMSB)
+}
+
+// matchEmptyOrDeleted returns the empty and deleted slots.
+func (c ctrlGroup) matchEmptyOrDeleted() bitset {
+	return bitset(^uint64(c) & bitsetMSB)
+}
+
+// matchFull returns the full slots.
+func (c ctrlGroup) matchFull() bitset {
+	return bitset(uint64(c) & bitsetMSB)
+}
+
+func (g *bmap) ctrls() ctrlGroup {
+	c := *(*uint64)(unsafe.Pointer(&g.ctrl))
+	if goarch.BigEndian {
+		c = sys.Bswap64(c)
+	}
+	return ctrlGroup(c)
+}
+
+// keySlot returns the address where the key of slot i is stored.
+func (g *bmap) keySlot(t *maptype, i uintptr) unsafe.Pointer {
+	return add(unsafe.Pointer(g), dataOffset+i*uintptr(t.KeySize))
+}
+
+// elemSlot returns the address where the elem of slot i is stored.
+func (g *bmap) elemSlot(t *maptype, i uintptr) unsafe.Pointer {
+	return add(unsafe.Pointer(g), dataOffset+groupSlots*uintptr(t.KeySize)+i*uintptr(t.ValueSize))
+}
+
+// key returns the address of the key of slot i.
+func (g *bmap) key(t *maptype, i uintptr) unsafe.Pointer {
+	k := g.keySlot(t, i)
+	if t.IndirectKey() {
+		k = *((*unsafe.Pointer)(k))
+	}
+	return k
+}
+
+// elem returns the address of the elem of slot i.
+func (g *bmap) elem(t *maptype, i uintptr) unsafe.Pointer {
+	e := g.elemSlot(t, i)
+	if t.IndirectElem() {
+		e = *((*unsafe.Pointer)(e))
+	}
+	return e
+}
+
+// clear deletes the entry of slot i, and reports whether it left a tombstone.
+func (g *bmap) clear(t *maptype, i uintptr) bool {
+	// Only clear key if there are pointers in it.
+	k := g.keySlot(t, i)
+	if t.IndirectKey() {
+		*(*unsafe.Pointer)(k) = nil
+	} else if t.Key.Pointers() {
+		memclrHasPointers(k, t.Key.Size_)
+	}
+	e := g.elemSlot(t, i)
+	if t.IndirectElem() {
+		*(*unsafe.Pointer)(e) = nil
+	} else if t.Elem.Pointers() {
+		memclrHasPointers(e, t.Elem.Size_)
+	} else {
+		memclrNoHeapPointers(e, t.Elem.Size_)
+	}
+	// A group with an empty slot has never been full, so no probe
+	// sequence goes past it, and the slot can be marked empty.
+	if g.ctrls().matchEmpty() != 0 {
+		g.ctrl[i] = ctrlEmpty
+		return false
+	}
+	g.ctrl[i] = ctrlDeleted
+	return true
+}
+
+// A probeSeq iterates over the groups of the probe sequence of a hash.
+type probeSeq struct {
+	mask   uintptr
+	offset uintptr // current group
+	index  uintptr
+}
+
+func makeProbeSeq(hash, mask uintptr) probeSeq {
+	return probeSeq{mask: mask, offset: hash & mask}
+}
+
+func (s probeSeq) next() probeSeq {
+	s.index++
+	s.offset = (s.offset + s.index) & s.mask
+	return s
+}
+
+// find returns the group and slot of key in the table of 1<<B groups
+// at groups, or nil if it is not there.
+func find(t *maptype, groups unsafe.Pointer, B uint8, hash uintptr, key unsafe.Pointer) (*bmap, uintptr) {
+	top := ctrlTop(hash)
+	for s := makeProbeSeq(hash, bucketMask(B)); ; s = s.next() {
+		g := (*bmap)(add(groups, s.offset*uintptr(t.BucketSize)))
+		c := g.ctrls()
+		for m := c.matchTop(top); m != 0; m = m.removeFirst() {
+			i := m.first()
+			if t.Key.Equal(key, g.key(t, i)) {
+				return g, i
+			}
+		}
+		if c.matchEmpty() != 0 || s.index == s.mask {
+			return nil, 0
+		}
+	}
+}
+
+// insertSlot returns the first empty or deleted slot in the probe
+// sequence of hash in the table of 1<<B groups at groups, and whether
+// it is deleted.
+func insertSlot(t *maptype, groups unsafe.Pointer, B uint8, hash uintptr) (*bmap, uintptr, bool) {
+	for s := makeProbeSeq(hash, bucketMask(B)); ; s = s.next() {
+		g := (*bmap)(add(groups, s.offset*uintptr(t.BucketSize)))
+		if m := g.ctrls().matchEmptyOrDeleted(); m != 0 {
+			i := m.first()
+			return g, i, g.ctrl[i] == ctrlDeleted
+		}
+	}
+}
+
+// newSlot returns a slot for a new key with the given hash, set to
+// control byte top. It grows the map first if its table is full.
+func (h *hmap) newSlot(t *maptype, hash uintptr, top uint8) (*bmap, uintptr) {
+	if uintptr(h.count)+h.tombstones >= maxLoad(h.B) {
+		hashGrow(t, h)
+		growWork(t, h)
+	}
+	g, i, deleted := insertSlot(t, h.buckets, h.B, hash)
+	if deleted {
+		h.tombstones--
+	}
+	g.ctrl[i] = top
+	h.count++
+	return g, i
+}
+
+// deleted records the deletion of an entry from the map.
+func (h *hmap) deleted() {
+	h.count--
+	if h.count == 0 {
+		// Reset the hash seed to make it more difficult for attackers to
+		// repeatedly trigger hash collisions. See issue 25237.
+		h.hash0 = uint32(rand())
+		// The tables hold no entries any more, no need to copy them.
+		h.oldbuckets = nil
+	}
+}
+
+func makemap64(t *maptype, hint int64, h *hmap) *hmap {
+	if int64(int(hint)) != hint {
+		hint = 0
+	}
+	return makemap(t, int(hint), h)
+}
+
+// makemap_small implements Go map creation for make(map[k]v) and
+// make(map[k]v, hint) when hint is known to be at most groupSlots
+// at compile time and the map needs to be allocated on the heap.
+func makemap_small() *hmap {
+	h := new(hmap)
+	h.hash0 = uint32(rand())
+	return h
+}
+
+// makemap implements Go map creation for make(map[k]v, hint).
+// If the compiler has determined that the map or the first group
+// can be created on the stack, h and/or group may be non-nil.
+// If h != nil, the map can be created directly in h.
+// If h.buckets != nil, group pointed to can be used as the first group.
+func makemap(t *maptype, hint int, h *hmap) *hmap {
+	mem, overflow := math.MulUintptr(uintptr(hint), t.Bucket.Size_)
+	if overflow || mem > maxAlloc {
+		hint = 0
+	}
+
+	// initialize Hmap
+	if h == nil {
+		h = new(hmap)
+	}
+	h.hash0 = uint32(rand())
+
+	// Find the size parameter B which will hold the requested # of elements.
+	// For hint < 0 overLoadFactor returns false since hint < groupSlots.
+	B := uint8(0)
+	for overLoadFactor(hint, B) {
+		B++
+	}
+	h.B = B
+
+	// allocate initial hash table
+	// if B == 0, the buckets field is allocated lazily later (in mapassign)
+	// If hint is large zeroing this memory could take a while.
+	if h.B != 0 {
+		h.buckets = newarray(t.Bucket, int(bucketShift(h.B)))
+	}
+
+	return h
+}
+
+// mapaccess1 returns a pointer to h[key].  Never returns nil, instead
+// it will return a reference to the zero object for the elem type if
+// the key is not in the map.
+// NOTE: The returned pointer may keep the whole map live, so don't
+// hold onto it for very long.
+func mapaccess1(t *maptype, h *hmap, key unsafe.Pointer) unsafe.Pointer {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		pc := abi.FuncPCABIInternal(mapaccess1)
+		racereadpc(unsafe.Pointer(h), callerpc, pc)
+		raceReadObjectPC(t.Key, key, callerpc, pc)
+	}
+	if msanenabled && h != nil {
+		msanread(key, t.Key.Size_)
+	}
+	if asanenabled && h != nil {
+		asanread(key, t.Key.Size_)
+	}
+	if h == nil || h.count == 0 {
+		if err := mapKeyError(t, key); err != nil {
+			panic(err) // see issue 23734
+		}
+		return unsafe.Pointer(&zeroVal[0])
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map read and map write")
+	}
+	hash := t.Hasher(key, uintptr(h.hash0))
+	g, i := find(t, h.buckets, h.B, hash, key)
+	if g == nil {
+		if h.oldbuckets == nil {
+			return unsafe.Pointer(&zeroVal[0])
+		}
+		g, i = find(t, h.oldbuckets, h.oldB, hash, key)
+		if g == nil {
+			return unsafe.Pointer(&zeroVal[0])
+		}
+	}
+	return g.elem(t, i)
+}
+
+func mapaccess2(t *maptype, h *hmap, key unsafe.Pointer) (unsafe.Pointer, bool) {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		pc := abi.FuncPCABIInternal(mapaccess2)
+		racereadpc(unsafe.Pointer(h), callerpc, pc)
+		raceReadObjectPC(t.Key, key, callerpc, pc)
+	}
+	if msanenabled && h != nil {
+		msanread(key, t.Key.Size_)
+	}
+	if asanenabled && h != nil {
+		asanread(key, t.Key.Size_)
+	}
+	if h == nil || h.count == 0 {
+		if err := mapKeyError(t, key); err != nil {
+			panic(err) // see issue 23734
+		}
+		return unsafe.Pointer(&zeroVal[0]), false
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map read and map write")
+	}
+	hash := t.Hasher(key, uintptr(h.hash0))
+	g, i := find(t, h.buckets, h.B, hash, key)
+	if g == nil {
+		if h.oldbuckets == nil {
+			return unsafe.Pointer(&zeroVal[0]), false
+		}
+		g, i = find(t, h.oldbuckets, h.oldB, hash, key)
+		if g == nil {
+			return unsafe.Pointer(&zeroVal[0]), false
+		}
+	}
+	return g.elem(t, i), true
+}
+
+// returns both key and elem. Used by map iterator.
+func mapaccessK(t *maptype, h *hmap, key unsafe.Pointer) (unsafe.Pointer, unsafe.Pointer) {
+	if h == nil || h.count == 0 {
+		return nil, nil
+	}
+	hash := t.Hasher(key, uintptr(h.hash0))
+	g, i := find(t, h.buckets, h.B, hash, key)
+	if g == nil {
+		if h.oldbuckets == nil {
+			return nil, nil
+		}
+		g, i = find(t, h.oldbuckets, h.oldB, hash, key)
+		if g == nil {
+			return nil, nil
+		}
+	}
+	return g.key(t, i), g.elem(t, i)
+}
+
+func mapaccess1_fat(t *maptype, h *hmap, key, zero unsafe.Pointer) unsafe.Pointer {
+	e := mapaccess1(t, h, key)
+	if e == unsafe.Pointer(&zeroVal[0]) {
+		return zero
+	}
+	return e
+}
+
+func mapaccess2_fat(t *maptype, h *hmap, key, zero unsafe.Pointer) (unsafe.Pointer, bool) {
+	e := mapaccess1(t, h, key)
+	if e == unsafe.Pointer(&zeroVal[0]) {
+		return zero, false
+	}
+	return e, true
+}
+
+// Like mapaccess, but allocates a slot for the key if it is not present in the map.
+func mapassign(t *maptype, h *hmap, key unsafe.Pointer) unsafe.Pointer {
+	if h == nil {
+		panic(plainError("assignment to entry in nil map"))
+	}
+	if raceenabled {
+		callerpc := getcallerpc()
+		pc := abi.FuncPCABIInternal(mapassign)
+		racewritepc(unsafe.Pointer(h), callerpc, pc)
+		raceReadObjectPC(t.Key, key, callerpc, pc)
+	}
+	if msanenabled {
+		msanread(key, t.Key.Size_)
+	}
+	if asanenabled {
+		asanread(key, t.Key.Size_)
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map writes")
+	}
+	hash := t.Hasher(key, uintptr(h.hash0))
+
+	// Set hashWriting after calling t.hasher, since t.hasher may panic,
+	// in which case we have not actually done a write.
+	h.flags ^= hashWriting
+
+	if h.buckets == nil {
+		h.buckets = newobject(t.Bucket) // newarray(t.Bucket, 1)
+	}
+	if h.growing() {
+		growWork(t, h)
+	}
+
+	g, i := find(t, h.buckets, h.B, hash, key)
+	if g == nil && h.growing() {
+		// The key may not have been copied to the new table yet.
+		// Update it in the old one, growth will copy it later.
+		g, i = find(t, h.oldbuckets, h.oldB, hash, key)
+	}
+	if g != nil {
+		// already have a mapping for key. Update it.
+		if t.NeedKeyUpdate() {
+			typedmemmove(t.Key, g.key(t, i), key)
+		}
+	} else {
+		top := ctrlTop(hash)
+		if !t.ReflexiveKey() && !t.Key.Equal(key, key) {
+			top &^= ctrlCopied
+		}
+		g, i = h.newSlot(t, hash, top)
+
+		// store new key/elem at insert position
+		insertk := g.keySlot(t, i)
+		if t.IndirectKey() {
+			kmem := newobject(t.Key)
+			*(*unsafe.Pointer)(insertk) = kmem
+			insertk = kmem
+		}
+		if t.IndirectElem() {
+			vmem := newobject(t.Elem)
+			*(*unsafe.Pointer)(g.elemSlot(t, i)) = vmem
+		}
+		typedmemmove(t.Key, insertk, key)
+	}
+
+	if h.flags&hashWriting == 0 {
+		fatal("concurrent map writes")
+	}
+	h.flags &^= hashWriting
+	return g.elem(t, i)
+}
+
+func mapdelete(t *maptype, h *hmap, key unsafe.Pointer) {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		pc := abi.FuncPCABIInternal(mapdelete)
+		racewritepc(unsafe.Pointer(h), callerpc, pc)
+		raceReadObjectPC(t.Key, key, callerpc, pc)
+	}
+	if msanenabled && h != nil {
+		msanread(key, t.Key.Size_)
+	}
+	if asanenabled && h != nil {
+		asanread(key, t.Key.Size_)
+	}
+	if h == nil || h.count == 0 {
+		if err := mapKeyError(t, key); err != nil {
+			panic(err) // see issue 23734
+		}
+		return
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map writes")
+	}
+
+	hash := t.Hasher(key, uintptr(h.hash0))
+
+	// Set hashWriting after calling t.hasher, since t.hasher may panic,
+	// in which case we have not actually done a write (delete).
+	h.flags ^= hashWriting
+
+	if h.growing() {
+		growWork(t, h)
+	}
+	// The key may be in both tables while growing.
+	deleted := false
+	if g, i := find(t, h.buckets, h.B, hash, key); g != nil {
+		if g.clear(t, i) {
+			h.tombstones++
+		}
+		deleted = true
+	}
+	if h.growing() {
+		if g, i := find(t, h.oldbuckets, h.oldB, hash, key); g != nil {
+			g.clear(t, i)
+			deleted = true
+		}
+	}
+	if deleted {
+		h.deleted()
+	}
+
+	if h.flags&hashWriting == 0 {
+		fatal("concurrent map writes")
+	}
+	h.flags &^= hashWriting
+}
+
+// mapiterinit initializes the hiter struct used for ranging over maps.
+// The hiter struct pointed to by 'it' is allocated on the stack
+// by the compilers order pass or on the heap by reflect_mapiterinit.
+// Both need to have zeroed hiter since the struct contains pointers.
+func mapiterinit(t *maptype, h *hmap, it *hiter) {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		racereadpc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapiterinit))
+	}
+
+	it.t = t
+	if h == nil || h.count == 0 {
+		return
+	}
+
+	if unsafe.Sizeof(hiter{})/goarch.PtrSize != 12 {
+		throw("hash_iter size incorrect") // see cmd/compile/internal/reflectdata/reflect.go
+	}
+	it.h = h
+
+	// grab snapshot of table state
+	it.B = h.B
+	it.buckets = h.buckets
+	if h.growing() {
+		it.oldB = h.oldB
+		it.oldbuckets = h.oldbuckets
+	}
+	it.seed = uintptr(h.hash0)
+
+	// decide where to start
+	r := uintptr(rand())
+	it.startGroup = r & bucketMask(h.B)
+	it.offset = uint8(r >> h.B & (groupSlots - 1))
+
+	mapiternext(it)
+}
+
+func mapiternext(it *hiter) {
+	h := it.h
+	if raceenabled {
+		callerpc := getcallerpc()
+		racereadpc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapiternext))
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map iteration and map write")
+	}
+	t := it.t
+
+	// The old groups, if any, are walked first.
+	nold := uintptr(0)
+	if it.oldbuckets != nil {
+		nold = bucketShift(it.oldB)
+	}
+	for ; it.n < nold+bucketShift(it.B); it.n, it.i = it.n+1, 0 {
+		old := it.n < nold
+		var g *bmap
+		if old {
+			gi := (it.startGroup + it.n) & (nold - 1)
+			g = (*bmap)(add(it.oldbuckets, gi*uintptr(t.BucketSize)))
+		} else {
+			gi := (it.startGroup + it.n - nold) & bucketMask(it.B)
+			g = (*bmap)(add(it.buckets, gi*uintptr(t.BucketSize)))
+		}
+		for ; it.i < groupSlots; it.i++ {
+			i := uintptr(it.i+it.offset) & (groupSlots - 1)
+			c := g.ctrl[i]
+			if c&ctrlFull == 0 {
+				continue
+			}
+			k := g.key(t, i)
+			if !t.ReflexiveKey() && !t.Key.Equal(k, k) {
+				// key!=key, so the entry can't be updated, and can
+				// only be deleted by clearing the map.
+				if it.seed != uintptr(h.hash0) {
+					continue // the map has been cleared
+				}
+				if !old && nold != 0 && c&ctrlCopied != 0 {
+					continue // copy of an entry of the old groups
+				}
+				it.key = k
+				it.elem = g.elem(t, i)
+				it.i++
+				return
+			}
+			if !old && nold != 0 {
+				// Skip the keys returned from the old groups.
+				hash := t.Hasher(k, it.seed)
+				if g, _ := find(t, it.oldbuckets, it.oldB, hash, k); g != nil {
+					continue
+				}
+			}
+			if !old && it.buckets == h.buckets {
+				// The table hasn't grown since the iterator was
+				// started, so this is the golden data.
+				it.key = k
+				it.elem = g.elem(t, i)
+			} else {
+				// The entry may have been updated, or deleted, since
+				// it was copied to a new table.
+				// Check the current hash table for the data.
+				// NOTE: we need to regrab the key as it has potentially been
+				// updated to an equal() but not identical key (e.g. +0.0 vs -0.0).
+				rk, re := mapaccessK(t, h, k)
+				if rk == nil {
+					continue // key has been deleted
+				}
+				it.key = rk
+				it.elem = re
+			}
+			it.i++
+			return
+		}
+	}
+	// end of iteration
+	it.key = nil
+	it.elem = nil
+}
+
+// mapclear deletes all keys from a map.
+func mapclear(t *maptype, h *hmap) {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		pc := abi.FuncPCABIInternal(mapclear)
+		racewritepc(unsafe.Pointer(h), callerpc, pc)
+	}
+
+	if h == nil || h.count == 0 {
+		return
+	}
+
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map writes")
+	}
+
+	h.flags ^= hashWriting
+
+	// Clear the tables in place, so existing iterators can be terminated, see issue #59411.
+	size := t.Bucket.Size_ * bucketShift(h.B)
+	if t.Bucket.Pointers() {
+		memclrHasPointers(h.buckets, size)
+	} else {
+		memclrNoHeapPointers(h.buckets, size)
+	}
+	if h.growing() {
+		for i := uintptr(0); i < bucketShift(h.oldB); i++ {
+			g := (*bmap)(add(h.oldbuckets, i*uintptr(t.BucketSize)))
+			g.ctrl = [groupSlots]uint8{}
+		}
+	}
+
+	h.oldbuckets = nil
+	h.tombstones = 0
+	h.count = 0
+
+	// Reset the hash seed to make it more difficult for attackers to
+	// repeatedly trigger hash collisions. See issue 25237.
+	h.hash0 = uint32(rand())
+
+	if h.flags&hashWriting == 0 {
+		fatal("concurrent map writes")
+	}
+	h.flags &^= hashWriting
+}
+
+func hashGrow(t *maptype, h *hmap) {
+	// Finish the current growth first. It is rarely not done yet,
+	// as every write to the map makes progress on it.
+	for h.growing() {
+		evacuate(t, h)
+	}
+
+	// If more than half of the full slots are live, get bigger.
+	// Otherwise, most of them are tombstones, so keep the same
+	// number of groups and get rid of them.
+	B := h.B
+	if uintptr(h.count)+1 > maxLoad(B)/2 {
+		B++
+	}
+	if h.extra == nil {
+		h.extra = new(mapextra)
+	}
+
+	// commit the grow (atomic wrt gc)
+	h.oldbuckets = h.buckets
+	h.oldB = h.B
+	h.buckets = newarray(t.Bucket, int(bucketShift(B)))
+	h.B = B
+	h.tombstones = 0
+	h.extra.nevacuate = 0
+
+	// the actual copying of the hash table data is done incrementally
+	// by growWork() and evacuate().
+}
+
+// growing reports whether h is growing. The growth may be to the same size or bigger.
+func (h *hmap) growing() bool {
+	return h.oldbuckets != nil
+}
+
+func growWork(t *maptype, h *hmap) {
+	// Copy two groups, so that growth is done well before the new
+	// table is full.
+	evacuate(t, h)
+	if h.growing() {
+		evacuate(t, h)
+	}
+}
+
+// evacuate copies the next group of the old table to the new one,
+// and ends the growth after the last one.
+func evacuate(t *maptype, h *hmap) {
+	g := (*bmap)(add(h.oldbuckets, h.extra.nevacuate*uintptr(t.BucketSize)))
+	for m := g.ctrls().matchFull(); m != 0; m = m.removeFirst() {
+		i := m.first()
+		k := g.key(t, i)
+		hash := t.Hasher(k, uintptr(h.hash0))
+		top := ctrlTop(hash)
+		if !t.ReflexiveKey() && !t.Key.Equal(k, k) {
+			// The hash of a NaN is random, mark the copy so
+			// that iterators know they may have seen it.
+			top |= ctrlCopied
+		}
+		dst, di, deleted := insertSlot(t, h.buckets, h.B, hash)
+		if deleted {
+			h.tombstones--
+		}
+		dst.ctrl[di] = top
+		// Indirect keys and elems are shared with the old table,
+		// whose entry is dead once the growth is done.
+		if t.IndirectKey() {
+			*(*unsafe.Pointer)(dst.keySlot(t, di)) = *(*unsafe.Pointer)(g.keySlot(t, i))
+		} else {
+			typedmemmove(t.Key, dst.keySlot(t, di), k)
+		}
+		if t.IndirectElem() {
+			*(*unsafe.Pointer)(dst.elemSlot(t, di)) = *(*unsafe.Pointer)(g.elemSlot(t, i))
+		} else {
+			typedmemmove(t.Elem, dst.elemSlot(t, di), g.elemSlot(t, i))
+		}
+	}
+
+	h.extra.nevacuate++
+	if h.extra.nevacuate == bucketShift(h.oldB) {
+		// Growth is complete. Drop the old table.
+		h.oldbuckets = nil
+	}
+}
+
+// Reflect stubs. Called from ../reflect/asm_*.s
+
+//go:linkname reflect_makemap reflect.makemap
+func reflect_makemap(t *maptype, cap int) *hmap {
+	// Check invariants and reflects math.
+	if t.Key.Equal == nil {
+		throw("runtime.reflect_makemap: unsupported map key type")
+	}
+	if t.Key.Size_ > abi.MapMaxKeyBytes && (!t.IndirectKey() || t.KeySize != uint8(goarch.PtrSize)) ||
+		t.Key.Size_ <= abi.MapMaxKeyBytes && (t.IndirectKey() || t.KeySize != uint8(t.Key.Size_)) {
+		throw("key size wrong")
+	}
+	if t.Elem.Size_ > abi.MapMaxElemBytes && (!t.IndirectElem() || t.ValueSize != uint8(goarch.PtrSize)) ||
+		t.Elem.Size_ <= abi.MapMaxElemBytes && (t.IndirectElem() || t.ValueSize != uint8(t.Elem.Size_)) {
+		throw("elem size wrong")
+	}
+	if t.Key.Align_ > groupSlots {
+		throw("key align too big")
+	}
+	if t.Elem.Align_ > groupSlots {
+		throw("elem align too big")
+	}
+	if t.Key.Size_%uintptr(t.Key.Align_) != 0 {
+		throw("key size not a multiple of key align")
+	}
+	if t.Elem.Size_%uintptr(t.Elem.Align_) != 0 {
+		throw("elem size not a multiple of elem align")
+	}
+	if groupSlots != 8 {
+		throw("group size must be 8 slots")
+	}
+	if dataOffset%uintptr(t.Key.Align_) != 0 {
+		throw("need padding in bucket (key)")
+	}
+	if dataOffset%uintptr(t.Elem.Align_) != 0 {
+		throw("need padding in bucket (elem)")
+	}
+
+	return makemap(t, cap, nil)
+}
+
+//go:linkname reflect_mapaccess reflect.mapaccess
+func reflect_mapaccess(t *maptype, h *hmap, key unsafe.Pointer) unsafe.Pointer {
+	elem, ok := mapaccess2(t, h, key)
+	if !ok {
+		// reflect wants nil for a missing element
+		elem = nil
+	}
+	return elem
+}
+
+//go:linkname reflect_mapaccess_faststr reflect.mapaccess_faststr
+func reflect_mapaccess_faststr(t *maptype, h *hmap, key string) unsafe.Pointer {
+	elem, ok := mapaccess2_faststr(t, h, key)
+	if !ok {
+		// reflect wants nil for a missing element
+		elem = nil
+	}
+	return elem
+}
+
+//go:linkname reflect_mapassign reflect.mapassign0
+func reflect_mapassign(t *maptype, h *hmap, key unsafe.Pointer, elem unsafe.Pointer) {
+	p := mapassign(t, h, key)
+	typedmemmove(t.Elem, p, elem)
+}
+
+//go:linkname reflect_mapassign_faststr reflect.mapassign_faststr0
+func reflect_mapassign_faststr(t *maptype, h *hmap, key string, elem unsafe.Pointer) {
+	p := mapassign_faststr(t, h, key)
+	typedmemmove(t.Elem, p, elem)
+}
+
+//go:linkname reflect_mapdelete reflect.mapdelete
+func reflect_mapdelete(t *maptype, h *hmap, key unsafe.Pointer) {
+	mapdelete(t, h, key)
+}
+
+//go:linkname reflect_mapdelete_faststr reflect.mapdelete_faststr
+func reflect_mapdelete_faststr(t *maptype, h *hmap, key string) {
+	mapdelete_faststr(t, h, key)
+}
+
+//go:linkname reflect_mapiterinit reflect.mapiterinit
+func reflect_mapiterinit(t *maptype, h *hmap, it *hiter) {
+	mapiterinit(t, h, it)
+}
+
+//go:linkname reflect_mapiternext reflect.mapiternext
+func reflect_mapiternext(it *hiter) {
+	mapiternext(it)
+}
+
+//go:linkname reflect_mapiterkey reflect.mapiterkey
+func reflect_mapiterkey(it *hiter) unsafe.Pointer {
+	return it.key
+}
+
+//go:linkname reflect_mapiterelem reflect.mapiterelem
+func reflect_mapiterelem(it *hiter) unsafe.Pointer {
+	return it.elem
+}
+
+//go:linkname reflect_maplen reflect.maplen
+func reflect_maplen(h *hmap) int {
+	if h == nil {
+		return 0
+	}
+	if raceenabled {
+		callerpc := getcallerpc()
+		racereadpc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(reflect_maplen))
+	}
+	return h.count
+}
+
+//go:linkname reflect_mapclear reflect.mapclear
+func reflect_mapclear(t *maptype, h *hmap) {
+	mapclear(t, h)
+}
+
+//go:linkname reflectlite_maplen internal/reflectlite.maplen
+func reflectlite_maplen(h *hmap) int {
+	if h == nil {
+		return 0
+	}
+	if raceenabled {
+		callerpc := getcallerpc()
+		racereadpc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(reflect_maplen))
+	}
+	return h.count
+}
+
+var zeroVal [abi.ZeroValSize]byte
+
+// mapinitnoop is a no-op function known the Go linker; if a given global
+// map (of the right size) is determined to be dead, the linker will
+// rewrite the relocation (from the package init func) from the outlined
+// map init function to this symbol. Defined in assembly so as to avoid
+// complications with instrumentation (coverage, etc).
+func mapinitnoop()
+
+// mapclone for implementing maps.Clone
+//
+//go:linkname mapclone maps.clone
+func mapclone(m any) any {
+	e := efaceOf(&m)
+	e.data = unsafe.Pointer(mapclone2((*maptype)(unsafe.Pointer(e._type)), (*hmap)(e.data)))
+	return m
+}
+
+func mapclone2(t *maptype, src *hmap) *hmap {
+	dst := makemap(t, src.count, nil)
+	//flags do not need to be copied here, just like a new map has no flags.
+
+	if src.count == 0 {
+		return dst
+	}
+
+	if src.flags&hashWriting != 0 {
+		fatal("concurrent map clone and map write")
+	}
+
+	if dst.B == src.B && !src.growing() && !(t.IndirectKey() && t.NeedKeyUpdate()) && !t.IndirectElem() {
+		// Quick copy of the groups, when they are the size we need.
+		// Note: if NeedKeyUpdate is false, then the memory
+		// used to store indirect keys is immutable, so we can share
+		// it between the original map and its clone.
+		if dst.buckets == nil {
+			dst.buckets = newobject(t.Bucket)
+		}
+		for i := uintptr(0); i < bucketShift(src.B); i++ {
+			typedmemmove(t.Bucket, add(dst.buckets, i*uintptr(t.BucketSize)), add(src.buckets, i*uintptr(t.BucketSize)))
+		}
+		dst.hash0 = src.hash0
+		dst.count = src.count
+		dst.tombstones = src.tombstones
+		return dst
+	}
+
+	var it hiter
+	for mapiterinit(t, src, &it); it.key != nil; mapiternext(&it) {
+		dstEle := mapassign(t, dst, it.key)
+		typedmemmove(t.Elem, dstEle, it.elem)
+	}
+	return dst
+}
+
+// keys for implementing maps.keys
+//
+//go:linkname keys maps.keys
+func keys(m any, p unsafe.Pointer) {
+	e := efaceOf(&m)
+	t := (*maptype)(unsafe.Pointer(e._type))
+	h := (*hmap)(e.data)
+
+	if h == nil || h.count == 0 {
+		return
+	}
+	s := (*slice)(p)
+	var it hiter
+	for mapiterinit(t, h, &it); it.key != nil; mapiternext(&it) {
+		if s.len >= s.cap {
+			fatal("concurrent map read and map write")
+		}
+		typedmemmove(t.Key, add(s.array, uintptr(s.len)*uintptr(t.Key.Size())), it.key)
+		s.len++
+	}
+}
+
+// values for implementing maps.values
+//
+//go:linkname values maps.values
+func values(m any, p unsafe.Pointer) {
+	e := efaceOf(&m)
+	t := (*maptype)(unsafe.Pointer(e._type))
+	h := (*hmap)(e.data)
+
+	if h == nil || h.count == 0 {
+		return
+	}
+	s := (*slice)(p)
+	var it hiter
+	for mapiterinit(t, h, &it); it.key != nil; mapiternext(&it) {
+		if s.len >= s.cap {
+			fatal("concurrent map read and map write")
+		}
+		typedmemmove(t.Elem, add(s.array, uintptr(s.len)*uintptr(t.Elem.Size())), it.elem)
+		s.len++
+	}
+}
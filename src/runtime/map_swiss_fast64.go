@@ -0,0 +1,209 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.swissmap
+
+package runtime
+
+import (
+	"internal/abi"
+	"unsafe"
+)
+
+func mapaccess1_fast64(t *maptype, h *hmap, key uint64) unsafe.Pointer {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		racereadpc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapaccess1_fast64))
+	}
+	if h == nil || h.count == 0 {
+		return unsafe.Pointer(&zeroVal[0])
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map read and map write")
+	}
+	if g, i := lookup_fast64(t, h, key); g != nil {
+		return add(unsafe.Pointer(g), dataOffset+groupSlots*8+i*uintptr(t.ValueSize))
+	}
+	return unsafe.Pointer(&zeroVal[0])
+}
+
+func mapaccess2_fast64(t *maptype, h *hmap, key uint64) (unsafe.Pointer, bool) {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		racereadpc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapaccess2_fast64))
+	}
+	if h == nil || h.count == 0 {
+		return unsafe.Pointer(&zeroVal[0]), false
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map read and map write")
+	}
+	if g, i := lookup_fast64(t, h, key); g != nil {
+		return add(unsafe.Pointer(g), dataOffset+groupSlots*8+i*uintptr(t.ValueSize)), true
+	}
+	return unsafe.Pointer(&zeroVal[0]), false
+}
+
+// lookup_fast64 returns the group and slot of key in h, or nil if it is not there.
+func lookup_fast64(t *maptype, h *hmap, key uint64) (*bmap, uintptr) {
+	if h.B == 0 && !h.growing() {
+		// One-group table. No need to hash.
+		g := (*bmap)(h.buckets)
+		for m := g.ctrls().matchFull(); m != 0; m = m.removeFirst() {
+			i := m.first()
+			if *(*uint64)(add(unsafe.Pointer(g), dataOffset+i*8)) == key {
+				return g, i
+			}
+		}
+		return nil, 0
+	}
+	hash := t.Hasher(noescape(unsafe.Pointer(&key)), uintptr(h.hash0))
+	if g, i := find_fast64(t, h.buckets, h.B, hash, key); g != nil || !h.growing() {
+		return g, i
+	}
+	return find_fast64(t, h.oldbuckets, h.oldB, hash, key)
+}
+
+// find_fast64 is like find, for 8-byte keys compared by value.
+func find_fast64(t *maptype, groups unsafe.Pointer, B uint8, hash uintptr, key uint64) (*bmap, uintptr) {
+	top := ctrlTop(hash)
+	for s := makeProbeSeq(hash, bucketMask(B)); ; s = s.next() {
+		g := (*bmap)(add(groups, s.offset*uintptr(t.BucketSize)))
+		c := g.ctrls()
+		for m := c.matchTop(top); m != 0; m = m.removeFirst() {
+			i := m.first()
+			if *(*uint64)(add(unsafe.Pointer(g), dataOffset+i*8)) == key {
+				return g, i
+			}
+		}
+		if c.matchEmpty() != 0 || s.index == s.mask {
+			return nil, 0
+		}
+	}
+}
+
+func mapassign_fast64(t *maptype, h *hmap, key uint64) unsafe.Pointer {
+	if h == nil {
+		panic(plainError("assignment to entry in nil map"))
+	}
+	if raceenabled {
+		callerpc := getcallerpc()
+		racewritepc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapassign_fast64))
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map writes")
+	}
+	hash := t.Hasher(noescape(unsafe.Pointer(&key)), uintptr(h.hash0))
+
+	// Set hashWriting after calling t.hasher for consistency with mapassign.
+	h.flags ^= hashWriting
+
+	if h.buckets == nil {
+		h.buckets = newobject(t.Bucket) // newarray(t.Bucket, 1)
+	}
+	if h.growing() {
+		growWork(t, h)
+	}
+
+	g, i := find_fast64(t, h.buckets, h.B, hash, key)
+	if g == nil && h.growing() {
+		g, i = find_fast64(t, h.oldbuckets, h.oldB, hash, key)
+	}
+	if g == nil {
+		g, i = h.newSlot(t, hash, ctrlTop(hash))
+		// store new key at insert position
+		*(*uint64)(add(unsafe.Pointer(g), dataOffset+i*8)) = key
+	}
+
+	elem := add(unsafe.Pointer(g), dataOffset+groupSlots*8+i*uintptr(t.ValueSize))
+	if h.flags&hashWriting == 0 {
+		fatal("concurrent map writes")
+	}
+	h.flags &^= hashWriting
+	return elem
+}
+
+func mapassign_fast64ptr(t *maptype, h *hmap, key unsafe.Pointer) unsafe.Pointer {
+	if h == nil {
+		panic(plainError("assignment to entry in nil map"))
+	}
+	if raceenabled {
+		callerpc := getcallerpc()
+		racewritepc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapassign_fast64))
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map writes")
+	}
+	hash := t.Hasher(noescape(unsafe.Pointer(&key)), uintptr(h.hash0))
+
+	// Set hashWriting after calling t.hasher for consistency with mapassign.
+	h.flags ^= hashWriting
+
+	if h.buckets == nil {
+		h.buckets = newobject(t.Bucket) // newarray(t.Bucket, 1)
+	}
+	if h.growing() {
+		growWork(t, h)
+	}
+
+	g, i := find_fast64(t, h.buckets, h.B, hash, uint64(uintptr(key)))
+	if g == nil && h.growing() {
+		g, i = find_fast64(t, h.oldbuckets, h.oldB, hash, uint64(uintptr(key)))
+	}
+	if g == nil {
+		g, i = h.newSlot(t, hash, ctrlTop(hash))
+		// store new key at insert position
+		*(*unsafe.Pointer)(add(unsafe.Pointer(g), dataOffset+i*8)) = key
+	}
+
+	elem := add(unsafe.Pointer(g), dataOffset+groupSlots*8+i*uintptr(t.ValueSize))
+	if h.flags&hashWriting == 0 {
+		fatal("concurrent map writes")
+	}
+	h.flags &^= hashWriting
+	return elem
+}
+
+func mapdelete_fast64(t *maptype, h *hmap, key uint64) {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		racewritepc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapdelete_fast64))
+	}
+	if h == nil || h.count == 0 {
+		return
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map writes")
+	}
+
+	hash := t.Hasher(noescape(unsafe.Pointer(&key)), uintptr(h.hash0))
+
+	// Set hashWriting after calling t.hasher for consistency with mapdelete
+	h.flags ^= hashWriting
+
+	if h.growing() {
+		growWork(t, h)
+	}
+	deleted := false
+	if g, i := find_fast64(t, h.buckets, h.B, hash, key); g != nil {
+		if g.clear(t, i) {
+			h.tombstones++
+		}
+		deleted = true
+	}
+	if h.growing() {
+		if g, i := find_fast64(t, h.oldbuckets, h.oldB, hash, key); g != nil {
+			g.clear(t, i)
+			deleted = true
+		}
+	}
+	if deleted {
+		h.deleted()
+	}
+
+	if h.flags&hashWriting == 0 {
+		fatal("concurrent map writes")
+	}
+	h.flags &^= hashWriting
+}
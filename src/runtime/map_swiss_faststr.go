@@ -0,0 +1,216 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.swissmap
+
+package runtime
+
+import (
+	"internal/abi"
+	"internal/goarch"
+	"unsafe"
+)
+
+func mapaccess1_faststr(t *maptype, h *hmap, ky string) unsafe.Pointer {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		racereadpc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapaccess1_faststr))
+	}
+	if h == nil || h.count == 0 {
+		return unsafe.Pointer(&zeroVal[0])
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map read and map write")
+	}
+	if g, i := lookup_faststr(t, h, ky); g != nil {
+		return add(unsafe.Pointer(g), dataOffset+groupSlots*2*goarch.PtrSize+i*uintptr(t.ValueSize))
+	}
+	return unsafe.Pointer(&zeroVal[0])
+}
+
+func mapaccess2_faststr(t *maptype, h *hmap, ky string) (unsafe.Pointer, bool) {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		racereadpc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapaccess2_faststr))
+	}
+	if h == nil || h.count == 0 {
+		return unsafe.Pointer(&zeroVal[0]), false
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map read and map write")
+	}
+	if g, i := lookup_faststr(t, h, ky); g != nil {
+		return add(unsafe.Pointer(g), dataOffset+groupSlots*2*goarch.PtrSize+i*uintptr(t.ValueSize)), true
+	}
+	return unsafe.Pointer(&zeroVal[0]), false
+}
+
+// lookup_faststr returns the group and slot of ky in h, or nil if it is not there.
+func lookup_faststr(t *maptype, h *hmap, ky string) (*bmap, uintptr) {
+	key := stringStructOf(&ky)
+	if h.B == 0 && !h.growing() {
+		// One-group table.
+		g := (*bmap)(h.buckets)
+		if key.len < 32 {
+			// short key, doing lots of comparisons is ok
+			for m := g.ctrls().matchFull(); m != 0; m = m.removeFirst() {
+				i := m.first()
+				k := (*stringStruct)(add(unsafe.Pointer(g), dataOffset+i*2*goarch.PtrSize))
+				if k.len == key.len && (k.str == key.str || memequal(k.str, key.str, uintptr(key.len))) {
+					return g, i
+				}
+			}
+			return nil, 0
+		}
+		// long key, try not to do more comparisons than necessary
+		keymaybe := uintptr(groupSlots)
+		for m := g.ctrls().matchFull(); m != 0; m = m.removeFirst() {
+			i := m.first()
+			k := (*stringStruct)(add(unsafe.Pointer(g), dataOffset+i*2*goarch.PtrSize))
+			if k.len != key.len {
+				continue
+			}
+			if k.str == key.str {
+				return g, i
+			}
+			// check first 4 bytes
+			if *((*[4]byte)(key.str)) != *((*[4]byte)(k.str)) {
+				continue
+			}
+			// check last 4 bytes
+			if *((*[4]byte)(add(key.str, uintptr(key.len)-4))) != *((*[4]byte)(add(k.str, uintptr(key.len)-4))) {
+				continue
+			}
+			if keymaybe != groupSlots {
+				// Two keys are potential matches. Use hash to distinguish them.
+				goto dohash
+			}
+			keymaybe = i
+		}
+		if keymaybe != groupSlots {
+			k := (*stringStruct)(add(unsafe.Pointer(g), dataOffset+keymaybe*2*goarch.PtrSize))
+			if memequal(k.str, key.str, uintptr(key.len)) {
+				return g, keymaybe
+			}
+		}
+		return nil, 0
+	}
+dohash:
+	hash := t.Hasher(noescape(unsafe.Pointer(&ky)), uintptr(h.hash0))
+	if g, i := find_faststr(t, h.buckets, h.B, hash, key); g != nil || !h.growing() {
+		return g, i
+	}
+	return find_faststr(t, h.oldbuckets, h.oldB, hash, key)
+}
+
+// find_faststr is like find, for string keys.
+func find_faststr(t *maptype, groups unsafe.Pointer, B uint8, hash uintptr, key *stringStruct) (*bmap, uintptr) {
+	top := ctrlTop(hash)
+	for s := makeProbeSeq(hash, bucketMask(B)); ; s = s.next() {
+		g := (*bmap)(add(groups, s.offset*uintptr(t.BucketSize)))
+		c := g.ctrls()
+		for m := c.matchTop(top); m != 0; m = m.removeFirst() {
+			i := m.first()
+			k := (*stringStruct)(add(unsafe.Pointer(g), dataOffset+i*2*goarch.PtrSize))
+			if k.len == key.len && (k.str == key.str || memequal(k.str, key.str, uintptr(key.len))) {
+				return g, i
+			}
+		}
+		if c.matchEmpty() != 0 || s.index == s.mask {
+			return nil, 0
+		}
+	}
+}
+
+func mapassign_faststr(t *maptype, h *hmap, s string) unsafe.Pointer {
+	if h == nil {
+		panic(plainError("assignment to entry in nil map"))
+	}
+	if raceenabled {
+		callerpc := getcallerpc()
+		racewritepc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapassign_faststr))
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map writes")
+	}
+	key := stringStructOf(&s)
+	hash := t.Hasher(noescape(unsafe.Pointer(&s)), uintptr(h.hash0))
+
+	// Set hashWriting after calling t.hasher for consistency with mapassign.
+	h.flags ^= hashWriting
+
+	if h.buckets == nil {
+		h.buckets = newobject(t.Bucket) // newarray(t.bucket, 1)
+	}
+	if h.growing() {
+		growWork(t, h)
+	}
+
+	g, i := find_faststr(t, h.buckets, h.B, hash, key)
+	if g == nil && h.growing() {
+		g, i = find_faststr(t, h.oldbuckets, h.oldB, hash, key)
+	}
+	if g != nil {
+		// already have a mapping for key. Update it.
+		// Overwrite existing key, so it can be garbage collected.
+		// The size is already guaranteed to be set correctly.
+		k := (*stringStruct)(add(unsafe.Pointer(g), dataOffset+i*2*goarch.PtrSize))
+		k.str = key.str
+	} else {
+		g, i = h.newSlot(t, hash, ctrlTop(hash))
+		// store new key at insert position
+		*((*stringStruct)(add(unsafe.Pointer(g), dataOffset+i*2*goarch.PtrSize))) = *key
+	}
+
+	elem := add(unsafe.Pointer(g), dataOffset+groupSlots*2*goarch.PtrSize+i*uintptr(t.ValueSize))
+	if h.flags&hashWriting == 0 {
+		fatal("concurrent map writes")
+	}
+	h.flags &^= hashWriting
+	return elem
+}
+
+func mapdelete_faststr(t *maptype, h *hmap, ky string) {
+	if raceenabled && h != nil {
+		callerpc := getcallerpc()
+		racewritepc(unsafe.Pointer(h), callerpc, abi.FuncPCABIInternal(mapdelete_faststr))
+	}
+	if h == nil || h.count == 0 {
+		return
+	}
+	if h.flags&hashWriting != 0 {
+		fatal("concurrent map writes")
+	}
+
+	key := stringStructOf(&ky)
+	hash := t.Hasher(noescape(unsafe.Pointer(&ky)), uintptr(h.hash0))
+
+	// Set hashWriting after calling t.hasher for consistency with mapdelete
+	h.flags ^= hashWriting
+
+	if h.growing() {
+		growWork(t, h)
+	}
+	deleted := false
+	if g, i := find_faststr(t, h.buckets, h.B, hash, key); g != nil {
+		if g.clear(t, i) {
+			h.tombstones++
+		}
+		deleted = true
+	}
+	if h.growing() {
+		if g, i := find_faststr(t, h.oldbuckets, h.oldB, hash, key); g != nil {
+			g.clear(t, i)
+			deleted = true
+		}
+	}
+	if deleted {
+		h.deleted()
+	}
+
+	if h.flags&hashWriting == 0 {
+		fatal("concurrent map writes")
+	}
+	h.flags &^= hashWriting
+}
@@ -0,0 +1,255 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.swissmap
+
+package runtime_test
+
+import (
+	"math"
+	"runtime"
+	"testing"
+)
+
+var mapGroupTests = [...]struct {
+	n      int // n is the number of map elements
+	groups int // number of expected groups
+}{
+	{-(1 << 30), 1},
+	{-1, 1},
+	{0, 1},
+	{1, 1},
+	{7, 1},
+	{8, 1}, // a single group may be full
+	{9, 2}, // otherwise groups hold 7 elements at most
+	{14, 2},
+	{15, 4},
+	{28, 4},
+	{29, 8},
+	{56, 8},
+	{57, 16},
+}
+
+func TestMapBuckets(t *testing.T) {
+	// Test that maps of different sizes have the right number of groups.
+	// Non-escaping maps with small groups (like map[int]int) never
+	// have a nil group pointer due to starting with a preallocated group
+	// on the stack. These tests depend on the maximum load in map_swiss.go.
+	for _, tt := range mapGroupTests {
+		localMap := map[int]int{}
+		if runtime.MapBucketsPointerIsNil(localMap) {
+			t.Errorf("no escape: groups pointer is nil for non-escaping map")
+		}
+		hintMap := make(map[int]int, tt.n)
+		escapingMap := runtime.Escape(make(map[int]int, tt.n))
+		for i := 0; i < tt.n; i++ {
+			localMap[i] = i
+			hintMap[i] = i
+			escapingMap[i] = i
+		}
+		if got := runtime.MapBucketsCount(localMap); got != tt.groups {
+			t.Errorf("no escape: n=%d want %d groups, got %d", tt.n, tt.groups, got)
+		}
+		if got := runtime.MapBucketsCount(hintMap); got != tt.groups {
+			t.Errorf("hint: n=%d want %d groups, got %d", tt.n, tt.groups, got)
+		}
+		if got := runtime.MapBucketsCount(escapingMap); got != tt.groups {
+			t.Errorf("escape: n=%d want %d groups, got %d", tt.n, tt.groups, got)
+		}
+	}
+}
+
+func TestLoadFactor(t *testing.T) {
+	if runtime.OverLoadFactor(8, 0) || !runtime.OverLoadFactor(9, 0) {
+		t.Errorf("a single group must hold 8 elements")
+	}
+	for b := uint8(1); b < 20; b++ {
+		count := 7 * (1 << b) // 7/8 of 8 slots per group
+		if runtime.OverLoadFactor(count, b) {
+			t.Errorf("OverLoadFactor(%d,%d)=true, want false", count, b)
+		}
+		if !runtime.OverLoadFactor(count+1, b) {
+			t.Errorf("OverLoadFactor(%d,%d)=false, want true", count+1, b)
+		}
+	}
+}
+
+func TestMapTombstones(t *testing.T) {
+	m := map[int]int{}
+	const N = 10000
+	// Fill a map.
+	for i := 0; i < N; i++ {
+		m[i] = i
+	}
+	runtime.MapTableCheck(m)
+	// Delete half of the entries.
+	for i := 0; i < N; i += 2 {
+		delete(m, i)
+	}
+	runtime.MapTableCheck(m)
+	// Add new entries to fill in holes.
+	for i := N; i < 3*N/2; i++ {
+		m[i] = i
+	}
+	runtime.MapTableCheck(m)
+	// Delete everything.
+	for i := 0; i < 3*N/2; i++ {
+		delete(m, i)
+	}
+	runtime.MapTableCheck(m)
+	if len(m) != 0 {
+		t.Errorf("len(m) = %d, want 0", len(m))
+	}
+}
+
+func TestMapTombstonesRehash(t *testing.T) {
+	// A map with a steady number of entries but a changing set of
+	// keys accumulates tombstones, which rehashing must get rid of
+	// without growing the map.
+	m := make(map[int]int)
+	const live = 100
+	for i := 0; i < live; i++ {
+		m[i] = i
+	}
+	groups := runtime.MapBucketsCount(m)
+	for i := live; i < 100*live; i++ {
+		m[i] = i
+		delete(m, i-live)
+	}
+	runtime.MapTableCheck(m)
+	if len(m) != live {
+		t.Fatalf("len(m) = %d, want %d", len(m), live)
+	}
+	for i := 99 * live; i < 100*live; i++ {
+		if m[i] != i {
+			t.Fatalf("m[%d] = %d, want %d", i, m[i], i)
+		}
+	}
+	if got := runtime.MapBucketsCount(m); got > 2*groups {
+		t.Errorf("map grew from %d to %d groups with %d live entries", groups, got, live)
+	}
+}
+
+// fillToGrowth returns a map with entries 0 to n-1 and n large
+// enough that it is growing.
+func fillToGrowth(t *testing.T) (map[int]int, int) {
+	m := make(map[int]int)
+	for i := 0; ; i++ {
+		m[i] = i
+		if runtime.MapGrowing(m) && i > 100 {
+			return m, i + 1
+		}
+		if i > 1<<20 {
+			t.Fatal("map never grew")
+		}
+	}
+}
+
+func TestMapIterStartedDuringGrowth(t *testing.T) {
+	m, n := fillToGrowth(t)
+	seen := make(map[int]int)
+	first := -1
+	for k, v := range m {
+		if _, ok := seen[k]; ok {
+			t.Fatalf("key %d returned twice", k)
+		}
+		seen[k] = v
+		if first < 0 {
+			first = k
+			// Finish the growth, and update and delete
+			// entries while iterating.
+			for i := 0; i < n; i += 3 {
+				delete(m, i)
+			}
+			for i := 1; i < n; i += 3 {
+				m[i] = -i
+			}
+			if runtime.MapGrowing(m) {
+				t.Fatal("map still growing")
+			}
+		}
+	}
+	for k := 0; k < n; k++ {
+		v, ok := seen[k]
+		switch {
+		case k%3 == 0:
+			if ok && k != first {
+				t.Errorf("deleted key %d returned", k)
+			}
+		case !ok:
+			t.Errorf("key %d not returned", k)
+		case k%3 == 1 && v != -k && v != k:
+			t.Errorf("key %d returned with value %d", k, v)
+		case k%3 == 2 && v != k:
+			t.Errorf("key %d returned with value %d, want %d", k, v, k)
+		}
+	}
+	runtime.MapTableCheck(m)
+}
+
+func TestMapIterWhileGrowing(t *testing.T) {
+	// Keys present when the iteration starts are returned exactly once,
+	// with their current value, even if the map grows several times.
+	m := make(map[int]int)
+	const n = 1000
+	for i := 0; i < n; i++ {
+		m[i] = i
+	}
+	seen := make(map[int]bool)
+	next := n
+	for k, v := range m {
+		if k < n {
+			if seen[k] {
+				t.Fatalf("key %d returned twice", k)
+			}
+			seen[k] = true
+			if v != k && v != k+1 {
+				t.Fatalf("key %d returned with value %d", k, v)
+			}
+			m[(k+1)%n]++
+		}
+		for i := 0; i < 8; i++ {
+			m[next] = next
+			next++
+		}
+	}
+	if len(seen) != n {
+		t.Errorf("returned %d of the %d initial keys", len(seen), n)
+	}
+	runtime.MapTableCheck(m)
+}
+
+func TestMapNaNIterDuringGrowth(t *testing.T) {
+	// NaN keys copied by growth are only returned once.
+	m := make(map[float64]int)
+	nan := math.NaN()
+	for i := 0; i < 1000; i++ {
+		m[nan] = i
+		m[float64(i)] = i
+	}
+	found := make(map[int]int)
+	for k, v := range m {
+		if k != k {
+			found[v]++
+		}
+		// Make progress on any growth.
+		m[-1] = 0
+		delete(m, -1)
+	}
+	if len(found) != 1000 {
+		t.Errorf("found %d NaN keys, want 1000", len(found))
+	}
+	for v, n := range found {
+		if n != 1 {
+			t.Errorf("NaN key with value %d returned %d times", v, n)
+		}
+	}
+	clear(m)
+	if len(m) != 0 {
+		t.Errorf("len(m) = %d after clear", len(m))
+	}
+	for range m {
+		t.Fatal("iteration over cleared map")
+	}
+}
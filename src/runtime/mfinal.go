@@ -173,7 +173,7 @@ func finalizercommit(gp *g, lock unsafe.Pointer) bool {
 	return true
 }
 
-// This is the goroutine that runs all of the finalizers.
+// This is the goroutine that runs all of the finalizers and cleanups.
 func runfinq() {
 	var (
 		frame    unsafe.Pointer
@@ -203,6 +203,20 @@ func runfinq() {
 			for i := fb.cnt; i > 0; i-- {
 				f := &fb.fin[i-1]
 
+				// arg is only nil when a cleanup has been queued.
+				if f.arg == nil {
+					var cleanup func()
+					fn := unsafe.Pointer(f.fn)
+					cleanup = *(*func())(unsafe.Pointer(&fn))
+					fingStatus.Or(fingRunningFinalizer)
+					cleanup()
+					fingStatus.And(^fingRunningFinalizer)
+
+					f.fn = nil
+					atomic.Store(&fb.cnt, i-1)
+					continue
+				}
+
 				var regs abi.RegArgs
 				// The args may be passed in registers or on stack. Even for
 				// the register case, we still need the spill slots.
@@ -322,7 +336,10 @@ func blockUntilEmptyFinalizerQueue(timeout int64) bool {
 }
 
 // SetFinalizer sets the finalizer associated with obj to the provided
-// finalizer function. When the garbage collector finds an unreachable block
+// finalizer function. New Go code should consider using [AddCleanup]
+// instead, which is much less error-prone than SetFinalizer.
+//
+// When the garbage collector finds an unreachable block
 // with an associated finalizer, it clears the association and runs
 // finalizer(obj) in a separate goroutine. This makes obj reachable again,
 // but now without an associated finalizer. Assuming that SetFinalizer
@@ -348,7 +348,8 @@ type workType struct {
 	// Number of roots of various root types. Set by gcMarkRootPrepare.
 	//
 	// nStackRoots == len(stackRoots), but we have nStackRoots for
-	// consistency.
+	// consistency, except in goroutine leak detection cycles, where
+	// the stacks past nStackRoots are withheld from the roots.
 	nDataRoots, nBSSRoots, nSpanRoots, nStackRoots int
 
 	// Base indexes of each root type. Set by gcMarkRootPrepare.
@@ -357,7 +358,8 @@ type workType struct {
 	// stackRoots is a snapshot of all of the Gs that existed
 	// before the beginning of concurrent marking. The backing
 	// store of this must not be modified because it might be
-	// shared with allgs.
+	// shared with allgs, unless it was copied for goroutine
+	// leak detection.
 	stackRoots []*g
 
 	// Each type of GC state transition is protected by a lock.
@@ -704,6 +706,10 @@ func gcStart(trigger gcTrigger) {
 		schedEnableUser(false)
 	}
 
+	// Hide the objects leak candidates are blocked on from the GC
+	// while write barriers are still disabled.
+	gcPrepareLeakDetection()
+
 	// Enter concurrent mark phase and enable
 	// write barriers.
 	//
@@ -895,6 +901,19 @@ top:
 		goto top
 	}
 
+	// In a goroutine leak detection cycle, the stacks of goroutines
+	// that may be leaked were withheld from the roots. If some of them
+	// turn out to be reachable, or leaked, scan them and resume mark.
+	if goroutineLeak.enabled && gcFindLeakedGoroutines() {
+		getg().m.preemptoff = ""
+		systemstack(func() {
+			now := startTheWorldWithSema(0, stw)
+			work.pauseNS += now - stw.start
+		})
+		semrelease(&worldsema)
+		goto top
+	}
+
 	gcComputeStartingStackSize()
 
 	// Disable assists and background workers. We must do
@@ -1803,7 +1822,7 @@ func gcTestIsReachable(ptrs ...unsafe.Pointer) (mask uint64) {
 		s := (*specialReachable)(mheap_.specialReachableAlloc.alloc())
 		unlock(&mheap_.speciallock)
 		s.special.kind = _KindSpecialReachable
-		if !addspecial(p, &s.special) {
+		if !addspecial(p, &s.special, false) {
 			throw("already have a reachable special (duplicate pointer?)")
 		}
 		specials[i] = s
@@ -0,0 +1,230 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Garbage collector: goroutine leak detection.
+//
+// A goroutine blocked on a channel operation, a sync.Mutex, a
+// sync.RWMutex, a sync.WaitGroup or a sync.Cond can only be unblocked
+// by another goroutine using the same object. If the object is not
+// reachable from the globals or from a goroutine that may run, the
+// goroutine is blocked forever: it is leaked, along with everything
+// its stack references.
+//
+// Leak detection piggybacks on a GC cycle. During the sweep
+// termination STW, the goroutines blocked on such objects are the
+// leak candidates. The pointers from their sudogs to the objects they
+// are blocked on are hidden from the GC (see sudog.hidden), and they
+// are withheld from the stack roots of the mark phase (see
+// gcMarkRootPrepare). When the mark phase would otherwise be done,
+// every candidate blocked on an object that was marked may still be
+// unblocked: its stack is added to the roots and marking resumes, as
+// it may make more objects reachable. Once no remaining candidate is
+// blocked on a marked object, the remaining candidates are leaked.
+// Their stacks are added to the roots as well, so that the memory
+// they retain is not freed, and the cycle completes as usual.
+//
+// The goroutines found leaked are reported by the goroutineleak
+// profile in runtime/pprof.
+
+package runtime
+
+import (
+	"runtime/internal/atomic"
+	"unsafe"
+)
+
+var goroutineLeak struct {
+	// pending requests leak detection in the next GC cycle.
+	pending atomic.Bool
+
+	// enabled is set from the start of a leak detection cycle
+	// until the leaked goroutines are found. It is only accessed
+	// with the world stopped.
+	enabled bool
+}
+
+// channel returns the channel s is blocked on for a channel operation,
+// even while goroutine leak detection hides it from the GC.
+func (s *sudog) channel() *hchan {
+	if s.hidden != 0 {
+		return (*hchan)(unsafe.Pointer(s.hidden))
+	}
+	return s.c
+}
+
+// syncObject returns the address s is blocked on for a semaphore or
+// sync.Cond wait, even while goroutine leak detection hides it from
+// the GC.
+func (s *sudog) syncObject() unsafe.Pointer {
+	if s.hidden != 0 {
+		return unsafe.Pointer(s.hidden)
+	}
+	return s.elem
+}
+
+// isLeakCandidate reports whether gp is blocked in a way that only
+// another goroutine may unblock it.
+//
+// The world must be stopped.
+func isLeakCandidate(gp *g) bool {
+	if readgstatus(gp) != _Gwaiting || isSystemGoroutine(gp, false) {
+		return false
+	}
+	switch gp.waitreason {
+	case waitReasonChanReceive, waitReasonChanSend, waitReasonSelect,
+		waitReasonChanReceiveNilChan, waitReasonChanSendNilChan, waitReasonSelectNoCases:
+		return true
+	case waitReasonSyncMutexLock, waitReasonSyncRWMutexRLock, waitReasonSyncRWMutexLock,
+		waitReasonSemacquire, waitReasonSyncCondWait:
+		return gp.waitingSync != nil
+	}
+	return false
+}
+
+// gcPrepareLeakDetection makes the GC cycle being started detect
+// goroutine leaks, if it was requested.
+//
+// The world must be stopped and write barriers must still be disabled,
+// so that hiding pointers does not shade the objects they point to.
+func gcPrepareLeakDetection() {
+	assertWorldStopped()
+	if writeBarrier.enabled {
+		throw("gcPrepareLeakDetection with write barriers enabled")
+	}
+	if !goroutineLeak.pending.Load() {
+		return
+	}
+	goroutineLeak.pending.Store(false)
+	goroutineLeak.enabled = true
+	forEachGRace(func(gp *g) {
+		gp.leaked = false
+		if !isLeakCandidate(gp) {
+			return
+		}
+		for sg := gp.waiting; sg != nil; sg = sg.waitlink {
+			if sg.c != nil {
+				sg.hidden = uintptr(unsafe.Pointer(sg.c))
+				sg.c = nil
+			}
+		}
+		if sg := gp.waitingSync; sg != nil && sg.elem != nil {
+			sg.hidden = uintptr(sg.elem)
+			sg.elem = nil
+		}
+	})
+}
+
+// gcUnhideSyncObjects restores the pointers hidden from the GC in the
+// sudogs of gp. Since the mark phase is in progress, the write barrier
+// shades the objects they point to.
+//
+// The world must be stopped.
+func gcUnhideSyncObjects(gp *g) {
+	for sg := gp.waiting; sg != nil; sg = sg.waitlink {
+		if sg.hidden != 0 {
+			sg.c = (*hchan)(unsafe.Pointer(sg.hidden))
+			sg.hidden = 0
+		}
+	}
+	if sg := gp.waitingSync; sg != nil && sg.hidden != 0 {
+		sg.elem = unsafe.Pointer(sg.hidden)
+		sg.hidden = 0
+	}
+}
+
+// withholdLeakCandidates moves the leak candidates of roots to its end,
+// and returns the number of other goroutines.
+func withholdLeakCandidates(roots []*g) int {
+	n := 0
+	for i, gp := range roots {
+		if !isLeakCandidate(gp) {
+			roots[i], roots[n] = roots[n], gp
+			n++
+		}
+	}
+	return n
+}
+
+// mayBeUnblocked reports whether the leak candidate gp may still be
+// unblocked by a goroutine the mark phase found reachable.
+//
+// The world must be stopped.
+func mayBeUnblocked(gp *g) bool {
+	if readgstatus(gp) != _Gwaiting {
+		// It was unblocked since the start of the cycle.
+		return true
+	}
+	for sg := gp.waiting; sg != nil; sg = sg.waitlink {
+		if c := sg.channel(); c != nil && isMarkedOrNotHeap(unsafe.Pointer(c)) {
+			return true
+		}
+	}
+	if sg := gp.waitingSync; sg != nil {
+		if p := sg.syncObject(); p != nil && isMarkedOrNotHeap(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMarkedOrNotHeap reports whether p points into a heap object marked
+// in the current cycle, or outside of the heap, for example into
+// a global.
+func isMarkedOrNotHeap(p unsafe.Pointer) bool {
+	s := spanOfHeap(uintptr(p))
+	if s == nil {
+		return true
+	}
+	return s.markBitsForIndex(s.objIndex(uintptr(p))).isMarked()
+}
+
+// gcFindLeakedGoroutines is called when the mark phase of a leak
+// detection cycle has no work left. It adds the stacks of the withheld
+// goroutines that may still be unblocked to the roots or, if there are
+// none, finds the remaining ones leaked and adds them. It reports
+// whether it added roots, in which case the mark phase must resume.
+//
+// The world must be stopped.
+func gcFindLeakedGoroutines() bool {
+	assertWorldStopped()
+	start := work.nStackRoots
+	if start == len(work.stackRoots) {
+		goroutineLeak.enabled = false
+		return false
+	}
+	n := start
+	for i := start; i < len(work.stackRoots); i++ {
+		gp := work.stackRoots[i]
+		if mayBeUnblocked(gp) {
+			work.stackRoots[i], work.stackRoots[n] = work.stackRoots[n], gp
+			n++
+		}
+	}
+	if n == start {
+		// Only leaked goroutines may unblock the remaining ones.
+		for _, gp := range work.stackRoots[start:] {
+			gp.leaked = true
+		}
+		n = len(work.stackRoots)
+		goroutineLeak.enabled = false
+	}
+	for _, gp := range work.stackRoots[start:n] {
+		gcUnhideSyncObjects(gp)
+	}
+
+	// Workers that found no root job left may have incremented
+	// markrootNext past markrootJobs.
+	work.markrootNext = work.markrootJobs
+	work.markrootJobs += uint32(n - start)
+	work.nStackRoots = n
+	work.baseEnd = work.baseStacks + uint32(n)
+	return true
+}
+
+// detectGoroutineLeaks runs a GC cycle that detects goroutine leaks,
+// and returns when the leaked goroutines have been found.
+func detectGoroutineLeaks() {
+	goroutineLeak.pending.Store(true)
+	GC()
+}
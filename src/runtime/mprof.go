@@ -1401,6 +1401,62 @@ func goroutineProfileWithLabelsSync(p []StackRecord, labels []unsafe.Pointer) (n
 	return n, ok
 }
 
+//go:linkname runtime_goroutineLeakGC runtime/pprof.runtime_goroutineLeakGC
+func runtime_goroutineLeakGC() {
+	detectGoroutineLeaks()
+}
+
+//go:linkname runtime_goroutineLeakProfileWithLabels runtime/pprof.runtime_goroutineLeakProfileWithLabels
+func runtime_goroutineLeakProfileWithLabels(p []StackRecord, labels []unsafe.Pointer) (n int, ok bool) {
+	return goroutineLeakProfileWithLabels(p, labels)
+}
+
+// goroutineLeakProfileWithLabels records the stacks of the goroutines
+// found leaked by the last goroutine leak detection, which are still
+// blocked. labels may be nil. If labels is non-nil, it must have the
+// same length as p.
+func goroutineLeakProfileWithLabels(p []StackRecord, labels []unsafe.Pointer) (n int, ok bool) {
+	if labels != nil && len(labels) != len(p) {
+		labels = nil
+	}
+
+	isLeaked := func(gp1 *g) bool {
+		return gp1.leaked && readgstatus(gp1) == _Gwaiting
+	}
+
+	stw := stopTheWorld(stwGoroutineProfile)
+
+	// World is stopped, no locking required.
+	forEachGRace(func(gp1 *g) {
+		if isLeaked(gp1) {
+			n++
+		}
+	})
+
+	if n <= len(p) {
+		ok = true
+		i := 0
+		forEachGRace(func(gp1 *g) {
+			if !isLeaked(gp1) || i == len(p) {
+				return
+			}
+			// See goroutineProfileWithLabelsSync.
+			systemstack(func() { saveg(^uintptr(0), ^uintptr(0), gp1, &p[i]) })
+			if labels != nil {
+				labels[i] = gp1.labels
+			}
+			i++
+		})
+	}
+
+	if raceenabled {
+		raceacquire(unsafe.Pointer(&labelSync))
+	}
+
+	startTheWorld(stw)
+	return n, ok
+}
+
 // GoroutineProfile returns n, the number of records in the active goroutine stack profile.
 // If len(p) >= n, GoroutineProfile copies the profile into p and returns n, true.
 // If len(p) < n, GoroutineProfile does not change p and returns n, false.
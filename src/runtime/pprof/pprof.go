@@ -99,12 +99,13 @@ import (
 //
 // Each Profile has a unique name. A few profiles are predefined:
 //
-//	goroutine    - stack traces of all current goroutines
-//	heap         - a sampling of memory allocations of live objects
-//	allocs       - a sampling of all past memory allocations
-//	threadcreate - stack traces that led to the creation of new OS threads
-//	block        - stack traces that led to blocking on synchronization primitives
-//	mutex        - stack traces of holders of contended mutexes
+//	goroutine     - stack traces of all current goroutines
+//	goroutineleak - stack traces of goroutines blocked forever
+//	heap          - a sampling of memory allocations of live objects
+//	allocs        - a sampling of all past memory allocations
+//	threadcreate  - stack traces that led to the creation of new OS threads
+//	block         - stack traces that led to blocking on synchronization primitives
+//	mutex         - stack traces of holders of contended mutexes
 //
 // These predefined profiles maintain themselves and panic on an explicit
 // [Profile.Add] or [Profile.Remove] method call.
@@ -113,6 +114,22 @@ import (
 // the [StartCPUProfile] and [StopCPUProfile] functions, because it streams
 // output to a writer during profiling.
 //
+// # Goroutine leak profile
+//
+// The goroutine leak profile reports the goroutines blocked forever on
+// channel operations or on synchronization primitives, such as
+// [sync.Mutex], [sync.RWMutex], [sync.WaitGroup] and [sync.Cond].
+// A goroutine is blocked forever when the objects it is blocked on
+// cannot be reached by any goroutine that may run, nor by a global
+// variable, so that no other goroutine can unblock it.
+//
+// Writing the profile runs a garbage collection that finds the leaked
+// goroutines; [Profile.Count] reports the goroutines found leaked by
+// the last one. The memory retained by leaked goroutines is not freed.
+//
+// Stack traces correspond to the location the goroutines are blocked
+// at.
+//
 // # Heap profile
 //
 // The heap profile reports statistics as of the most recently completed
@@ -189,6 +206,12 @@ var goroutineProfile = &Profile{
 	write: writeGoroutine,
 }
 
+var goroutineLeakProfile = &Profile{
+	name:  "goroutineleak",
+	count: countGoroutineLeak,
+	write: writeGoroutineLeak,
+}
+
 var threadcreateProfile = &Profile{
 	name:  "threadcreate",
 	count: countThreadCreate,
@@ -224,12 +247,13 @@ func lockProfiles() {
 	if profiles.m == nil {
 		// Initial built-in profiles.
 		profiles.m = map[string]*Profile{
-			"goroutine":    goroutineProfile,
-			"threadcreate": threadcreateProfile,
-			"heap":         heapProfile,
-			"allocs":       allocsProfile,
-			"block":        blockProfile,
-			"mutex":        mutexProfile,
+			"goroutine":     goroutineProfile,
+			"goroutineleak": goroutineLeakProfile,
+			"threadcreate":  threadcreateProfile,
+			"heap":          heapProfile,
+			"allocs":        allocsProfile,
+			"block":         blockProfile,
+			"mutex":         mutexProfile,
 		}
 	}
 }
@@ -734,6 +758,26 @@ func writeGoroutine(w io.Writer, debug int) error {
 	return writeRuntimeProfile(w, debug, "goroutine", runtime_goroutineProfileWithLabels)
 }
 
+// runtime_goroutineLeakGC is defined in runtime/mprof.go
+func runtime_goroutineLeakGC()
+
+// runtime_goroutineLeakProfileWithLabels is defined in runtime/mprof.go
+func runtime_goroutineLeakProfileWithLabels(p []runtime.StackRecord, labels []unsafe.Pointer) (n int, ok bool)
+
+// countGoroutineLeak returns the number of goroutines found leaked by
+// the last goroutine leak detection.
+func countGoroutineLeak() int {
+	n, _ := runtime_goroutineLeakProfileWithLabels(nil, nil)
+	return n
+}
+
+// writeGoroutineLeak detects goroutine leaks and writes the stacks of
+// the leaked goroutines to w.
+func writeGoroutineLeak(w io.Writer, debug int) error {
+	runtime_goroutineLeakGC()
+	return writeRuntimeProfile(w, debug, "goroutineleak", runtime_goroutineLeakProfileWithLabels)
+}
+
 func writeGoroutineStacks(w io.Writer) error {
 	// We don't know how big the buffer needs to be to collect
 	// all the goroutines. Start with 1 MB and try a few times, doubling each time.
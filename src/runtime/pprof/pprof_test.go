@@ -1550,6 +1550,119 @@ func containsCountsLabels(prof *profile.Profile, countLabels map[int64]map[strin
 	return true
 }
 
+// reachableLeakTestChan keeps the goroutine blocked on it from being
+// reported as leaked.
+var reachableLeakTestChan = make(chan int)
+
+//go:noinline
+func leakChanRecv(c chan int) { <-c }
+
+//go:noinline
+func leakChanSend(c chan int) { c <- 1 }
+
+//go:noinline
+func leakSelect(c1, c2 chan int) {
+	select {
+	case <-c1:
+	case c2 <- 1:
+	}
+}
+
+//go:noinline
+func leakNilChan() {
+	var c chan int
+	<-c
+}
+
+//go:noinline
+func leakMutex(mu *sync.Mutex) { mu.Lock() }
+
+//go:noinline
+func leakWaitGroup(wg *sync.WaitGroup) { wg.Wait() }
+
+//go:noinline
+func leakCond(c *sync.Cond) {
+	c.L.Lock()
+	c.Wait()
+}
+
+//go:noinline
+func blockOnReachableChan() { <-reachableLeakTestChan }
+
+//go:noinline
+func blockOnReachableMutex(mu *sync.Mutex) { mu.Lock() }
+
+func TestGoroutineLeakProfile(t *testing.T) {
+	go leakChanRecv(make(chan int))
+	go leakChanSend(make(chan int))
+	go leakSelect(make(chan int), make(chan int))
+	go leakNilChan()
+	mu := new(sync.Mutex)
+	mu.Lock()
+	go leakMutex(mu)
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go leakWaitGroup(wg)
+	go leakCond(sync.NewCond(new(sync.Mutex)))
+	mu, wg = nil, nil
+
+	go blockOnReachableChan()
+	defer func() { reachableLeakTestChan <- 1 }()
+	reachableMu := new(sync.Mutex)
+	reachableMu.Lock()
+	go blockOnReachableMutex(reachableMu)
+	defer reachableMu.Unlock()
+
+	leaked := []string{
+		"runtime/pprof.leakChanRecv",
+		"runtime/pprof.leakChanSend",
+		"runtime/pprof.leakSelect",
+		"runtime/pprof.leakNilChan",
+		"runtime/pprof.leakMutex",
+		"runtime/pprof.leakWaitGroup",
+		"runtime/pprof.leakCond",
+	}
+	// The goroutines may take a while to block.
+	var prof string
+	for i := 0; i < 100; i++ {
+		var w strings.Builder
+		if err := Lookup("goroutineleak").WriteTo(&w, 1); err != nil {
+			t.Fatalf("writing goroutineleak profile: %v", err)
+		}
+		prof = w.String()
+		missing := false
+		for _, f := range leaked {
+			if !strings.Contains(prof, f) {
+				missing = true
+			}
+		}
+		if !missing {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, f := range leaked {
+		if !strings.Contains(prof, f) {
+			t.Errorf("goroutineleak profile does not contain %s", f)
+		}
+	}
+	for _, f := range []string{"blockOnReachableChan", "blockOnReachableMutex", "TestGoroutineLeakProfile"} {
+		if strings.Contains(prof, f) {
+			t.Errorf("goroutineleak profile contains %s", f)
+		}
+	}
+	if !strings.HasPrefix(prof, "goroutineleak profile: total ") {
+		t.Errorf("unexpected goroutineleak profile header:\n%s", prof)
+	}
+	if n := Lookup("goroutineleak").Count(); n < len(leaked) {
+		t.Errorf("goroutineleak profile count is %d, want at least %d", n, len(leaked))
+	}
+	runtime.KeepAlive(reachableMu)
+	if t.Failed() {
+		t.Logf("goroutineleak profile:\n%s", prof)
+	}
+}
+
 func TestGoroutineProfileConcurrency(t *testing.T) {
 	testenv.MustHaveParallelism(t)
 
@@ -2003,7 +2116,7 @@ func TestLabelSystemstack(t *testing.T) {
 					// which part of the function they are
 					// at.
 					mayBeLabeled = true
-				case "runtime.bgsweep", "runtime.bgscavenge", "runtime.forcegchelper", "runtime.gcBgMarkWorker", "runtime.runfinq", "runtime.sysmon":
+				case "runtime.bgsweep", "runtime.bgscavenge", "runtime.forcegchelper", "runtime.gcBgMarkWorker", "runtime.runfinq", "runtime.sysmon", "runtime.updateMaxProcsGoroutine":
 					// Runtime system goroutines or threads
 					// (such as those identified by
 					// runtime.isSystemGoroutine). These
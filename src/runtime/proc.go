@@ -333,6 +333,74 @@ func forcegchelper() {
 	}
 }
 
+// updateMaxProcsG is the state of the goroutine that applies the
+// changes of the default GOMAXPROCS detected by sysmon.
+var updateMaxProcsG struct {
+	lock mutex
+	g    *g
+	idle atomic.Bool
+
+	// procs is the new default GOMAXPROCS, set by sysmon before it
+	// wakes the goroutine.
+	procs int32
+}
+
+// start GOMAXPROCS updater goroutine
+func init() {
+	go updateMaxProcsGoroutine()
+}
+
+func updateMaxProcsGoroutine() {
+	updateMaxProcsG.g = getg()
+	lockInit(&updateMaxProcsG.lock, lockRankUpdateMaxProcsG)
+	for {
+		lock(&updateMaxProcsG.lock)
+		if updateMaxProcsG.idle.Load() {
+			throw("updateMaxProcsGoroutine: phase error")
+		}
+		updateMaxProcsG.idle.Store(true)
+		goparkunlock(&updateMaxProcsG.lock, waitReasonUpdateGOMAXPROCSIdle, traceBlockSystemGoroutine, 1)
+		// This goroutine is explicitly resumed by sysmon.
+
+		stw := stopTheWorldGC(stwGOMAXPROCS)
+		// GOMAXPROCS may have been set since sysmon checked.
+		lock(&sched.lock)
+		if !sched.customGOMAXPROCS {
+			// newprocs will be processed by startTheWorld.
+			newprocs = updateMaxProcsG.procs
+		}
+		unlock(&sched.lock)
+		startTheWorldGC(stw)
+	}
+}
+
+// defaultGOMAXPROCS returns the default GOMAXPROCS for ncpu CPUs: ncpu,
+// unless the cgroup of the process limits its CPU bandwidth to fewer
+// CPUs. The limit is rounded up, and does not bring the default below
+// 2, so that a fraction of a CPU is used fully and that a goroutine
+// may still run while another one blocks in a system call.
+func defaultGOMAXPROCS(ncpu int32) int32 {
+	procs := ncpu
+	if debug.containermaxprocs == 0 {
+		return procs
+	}
+	limit, ok := cgroupCPULimit()
+	if !ok {
+		return procs
+	}
+	n := int32(2)
+	if limit > 2 {
+		if limit >= float64(procs) {
+			return procs
+		}
+		n = int32(limit)
+		if float64(n) < limit {
+			n++
+		}
+	}
+	return min(procs, n)
+}
+
 // Gosched yields the processor, allowing other goroutines to run. It does not
 // suspend the current goroutine, so execution resumes automatically.
 //
@@ -472,6 +540,9 @@ func releaseSudog(s *sudog) {
 	if s.c != nil {
 		throw("runtime: sudog with non-nil c")
 	}
+	if s.hidden != 0 {
+		throw("runtime: sudog with non-zero hidden")
+	}
 	gp := getg()
 	if gp.param != nil {
 		throw("runtime: releaseSudog with non-nil gp.param")
@@ -819,11 +890,16 @@ func schedinit() {
 		MemProfileRate = 0
 	}
 
+	cgroupInit()
+
 	lock(&sched.lock)
 	sched.lastpoll.Store(nanotime())
-	procs := ncpu
+	var procs int32
 	if n, ok := atoi32(gogetenv("GOMAXPROCS")); ok && n > 0 {
 		procs = n
+		sched.customGOMAXPROCS = true
+	} else {
+		procs = defaultGOMAXPROCS(ncpu)
 	}
 	if procresize(procs) != nil {
 		throw("unknown runnable goroutine during bootstrap")
@@ -5885,6 +5961,10 @@ var needSysmonWorkaround bool = false
 // No threads on wasm yet, so no sysmon.
 const haveSysmon = GOARCH != "wasm"
 
+// maxprocsCheckPeriod is the minimum time in nanoseconds between
+// checks by sysmon of the default GOMAXPROCS.
+const maxprocsCheckPeriod = 1e9
+
 // Always runs without a P, so write barriers are not allowed.
 //
 //go:nowritebarrierrec
@@ -5895,6 +5975,7 @@ func sysmon() {
 	unlock(&sched.lock)
 
 	lasttrace := int64(0)
+	lastmaxprocs := nanotime()
 	idle := 0 // how many cycles in succession we had not wokeup somebody
 	delay := uint32(0)
 
@@ -6027,6 +6108,11 @@ func sysmon() {
 			injectglist(&list)
 			unlock(&forcegc.lock)
 		}
+		// check if the default GOMAXPROCS changed
+		if debug.updatemaxprocs != 0 && lastmaxprocs+maxprocsCheckPeriod <= now {
+			lastmaxprocs = now
+			sysmonUpdateGOMAXPROCS()
+		}
 		if debug.schedtrace > 0 && lasttrace+int64(debug.schedtrace)*1000000 <= now {
 			lasttrace = now
 			schedtrace(debug.scheddetail > 0)
@@ -6046,6 +6132,35 @@ type sysmontick struct {
 // preempted.
 const forcePreemptNS = 10 * 1000 * 1000 // 10ms
 
+// sysmonUpdateGOMAXPROCS wakes the GOMAXPROCS updater goroutine if
+// GOMAXPROCS was not set explicitly and its default changed, for
+// example because the CPU limit of the cgroup of the process changed.
+//
+//go:nowritebarrierrec
+func sysmonUpdateGOMAXPROCS() {
+	if !updateMaxProcsG.idle.Load() {
+		return
+	}
+	lock(&sched.lock)
+	custom := sched.customGOMAXPROCS
+	curr := gomaxprocs
+	unlock(&sched.lock)
+	if custom {
+		return
+	}
+	procs := defaultGOMAXPROCS(ncpu)
+	if procs == curr {
+		return
+	}
+	lock(&updateMaxProcsG.lock)
+	updateMaxProcsG.procs = procs
+	updateMaxProcsG.idle.Store(false)
+	var list gList
+	list.push(updateMaxProcsG.g)
+	injectglist(&list)
+	unlock(&updateMaxProcsG.lock)
+}
+
 func retake(now int64) uint32 {
 	n := 0
 	// Prevent allp slice changes. This lock will be completely
@@ -58,6 +58,20 @@ func TestStopTheWorldDeadlock(t *testing.T) {
 	runtime.GOMAXPROCS(maxprocs)
 }
 
+func TestSetDefaultGOMAXPROCS(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	want := int(runtime.DefaultGOMAXPROCS(int32(runtime.NumCPU())))
+	if want < 1 || want > runtime.NumCPU() {
+		t.Fatalf("default GOMAXPROCS = %d, want between 1 and NumCPU = %d", want, runtime.NumCPU())
+	}
+	runtime.GOMAXPROCS(want + 1)
+	runtime.SetDefaultGOMAXPROCS()
+	if got := runtime.GOMAXPROCS(0); got != want {
+		t.Errorf("GOMAXPROCS after SetDefaultGOMAXPROCS = %d, want %d", got, want)
+	}
+}
+
 func TestYieldProgress(t *testing.T) {
 	testYieldProgress(false)
 }
@@ -309,6 +309,7 @@ type dbgVar struct {
 var debug struct {
 	cgocheck                int32
 	clobberfree             int32
+	containermaxprocs       int32
 	disablethp              int32
 	dontfreezetheworld      int32
 	efence                  int32
@@ -329,6 +330,7 @@ var debug struct {
 	adaptivestackstart      int32
 	tracefpunwindoff        int32
 	traceadvanceperiod      int32
+	updatemaxprocs          int32
 
 	// debug.malloc is used as a combined debug check
 	// in the malloc function and should be set
@@ -358,6 +360,7 @@ var dbgvars = []*dbgVar{
 	{name: "asynctimerchan", atomic: &debug.asynctimerchan},
 	{name: "cgocheck", value: &debug.cgocheck},
 	{name: "clobberfree", value: &debug.clobberfree},
+	{name: "containermaxprocs", value: &debug.containermaxprocs, def: 1},
 	{name: "disablethp", value: &debug.disablethp},
 	{name: "dontfreezetheworld", value: &debug.dontfreezetheworld},
 	{name: "efence", value: &debug.efence},
@@ -379,6 +382,7 @@ var dbgvars = []*dbgVar{
 	{name: "traceadvanceperiod", value: &debug.traceadvanceperiod},
 	{name: "tracebackancestors", value: &debug.tracebackancestors},
 	{name: "tracefpunwindoff", value: &debug.tracefpunwindoff},
+	{name: "updatemaxprocs", value: &debug.updatemaxprocs, def: 1},
 }
 
 func parsedebugvars() {
@@ -394,6 +394,11 @@ type sudog struct {
 	waitlink *sudog // g.waiting list or semaRoot
 	waittail *sudog // semaRoot
 	c        *hchan // channel
+
+	// hidden holds c, for a channel operation, or elem, for a
+	// semaphore or sync.Cond wait, while goroutine leak detection
+	// hides it from the garbage collector. See mgcleak.go.
+	hidden uintptr
 }
 
 type libcall struct {
@@ -503,6 +508,7 @@ type g struct {
 	startpc       uintptr         // pc of goroutine function
 	racectx       uintptr
 	waiting       *sudog         // sudog structures this g is waiting on (that have a valid elem ptr); in lock order
+	waitingSync   *sudog         // sudog this g is waiting on in a semaphore or sync.Cond
 	cgoCtxt       []uintptr      // cgo traceback context
 	labels        unsafe.Pointer // profiler labels
 	timer         *timer         // cached timer for time.Sleep
@@ -515,6 +521,10 @@ type g struct {
 	// current in-progress goroutine profile
 	goroutineProfiled goroutineProfileStateHolder
 
+	// leaked indicates that the last goroutine leak detection found
+	// this goroutine blocked forever. See mgcleak.go.
+	leaked bool
+
 	// Per-G tracer state.
 	trace gTraceState
 
@@ -850,6 +860,12 @@ type schedt struct {
 	procresizetime int64 // nanotime() of last change to gomaxprocs
 	totaltime      int64 // ∫gomaxprocs dt up to procresizetime
 
+	// customGOMAXPROCS is set when GOMAXPROCS was set by the
+	// GOMAXPROCS environment variable or by runtime.GOMAXPROCS,
+	// in which case the runtime no longer updates it when the
+	// default changes. Protected by sched.lock.
+	customGOMAXPROCS bool
+
 	// sysmonlock protects sysmon's actions on the runtime.
 	//
 	// Acquire and hold this mutex to block sysmon from interacting
@@ -1076,6 +1092,7 @@ const (
 	waitReasonChanSend                                // "chan send"
 	waitReasonFinalizerWait                           // "finalizer wait"
 	waitReasonForceGCIdle                             // "force gc (idle)"
+	waitReasonUpdateGOMAXPROCSIdle                    // "GOMAXPROCS updater (idle)"
 	waitReasonSemacquire                              // "semacquire"
 	waitReasonSleep                                   // "sleep"
 	waitReasonSyncCondWait                            // "sync.Cond.Wait"
@@ -1116,6 +1133,7 @@ var waitReasonStrings = [...]string{
 	waitReasonChanSend:              "chan send",
 	waitReasonFinalizerWait:         "finalizer wait",
 	waitReasonForceGCIdle:           "force gc (idle)",
+	waitReasonUpdateGOMAXPROCSIdle:  "GOMAXPROCS updater (idle)",
 	waitReasonSemacquire:            "semacquire",
 	waitReasonSleep:                 "sleep",
 	waitReasonSyncCondWait:          "sync.Cond.Wait",
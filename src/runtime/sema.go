@@ -144,6 +144,7 @@ func semacquire1(addr *uint32, lifo bool, profile semaProfileFlags, skipframes i
 		}
 		s.acquiretime = t0
 	}
+	gp.waitingSync = s
 	for {
 		lockWithRank(&root.lock, lockRankRoot)
 		// Add ourselves to nwait to disable "easy case" in semrelease.
@@ -162,6 +163,7 @@ func semacquire1(addr *uint32, lifo bool, profile semaProfileFlags, skipframes i
 			break
 		}
 	}
+	gp.waitingSync = nil
 	if s.releasetime > 0 {
 		blockevent(s.releasetime-t0, 3+skipframes)
 	}
@@ -274,7 +276,7 @@ func (root *semaRoot) queue(addr *uint32, s *sudog, lifo bool) {
 	var last *sudog
 	pt := &root.treap
 	for t := *pt; t != nil; t = *pt {
-		if t.elem == unsafe.Pointer(addr) {
+		if t.syncObject() == unsafe.Pointer(addr) {
 			// Already have addr in list.
 			if lifo {
 				// Substitute s in t's place in treap.
@@ -320,7 +322,7 @@ func (root *semaRoot) queue(addr *uint32, s *sudog, lifo bool) {
 			return
 		}
 		last = t
-		if uintptr(unsafe.Pointer(addr)) < uintptr(t.elem) {
+		if uintptr(unsafe.Pointer(addr)) < uintptr(t.syncObject()) {
 			pt = &t.prev
 		} else {
 			pt = &t.next
@@ -366,10 +368,10 @@ func (root *semaRoot) dequeue(addr *uint32) (found *sudog, now, tailtime int64)
 	ps := &root.treap
 	s := *ps
 	for ; s != nil; s = *ps {
-		if s.elem == unsafe.Pointer(addr) {
+		if s.syncObject() == unsafe.Pointer(addr) {
 			goto Found
 		}
-		if uintptr(unsafe.Pointer(addr)) < uintptr(s.elem) {
+		if uintptr(unsafe.Pointer(addr)) < uintptr(s.syncObject()) {
 			ps = &s.prev
 		} else {
 			ps = &s.next
@@ -435,6 +437,7 @@ Found:
 	}
 	s.parent = nil
 	s.elem = nil
+	s.hidden = 0
 	s.next = nil
 	s.prev = nil
 	s.ticket = 0
@@ -553,6 +556,7 @@ func notifyListWait(l *notifyList, t uint32) {
 	// Enqueue itself.
 	s := acquireSudog()
 	s.g = getg()
+	s.elem = unsafe.Pointer(l)
 	s.ticket = t
 	s.releasetime = 0
 	t0 := int64(0)
@@ -566,7 +570,11 @@ func notifyListWait(l *notifyList, t uint32) {
 		l.tail.next = s
 	}
 	l.tail = s
+	s.g.waitingSync = s
 	goparkunlock(&l.lock, waitReasonSyncCondWait, traceBlockCondWait, 3)
+	s.g.waitingSync = nil
+	s.elem = nil
+	s.hidden = 0
 	if t0 != 0 {
 		blockevent(s.releasetime-t0, 2)
 	}
@@ -115,9 +115,6 @@ func reflect_memmove(to, from unsafe.Pointer, n uintptr) {
 	memmove(to, from, n)
 }
 
-// exported value for testing
-const hashLoad = float32(loadFactorNum) / float32(loadFactorDen)
-
 // in internal/bytealg/equal_*.s
 //
 //go:noescape
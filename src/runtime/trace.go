@@ -1795,6 +1795,14 @@ func (_ traceLocker) HeapGoal() {
 // To access runtime functions from runtime/trace.
 // See runtime/trace/annotation.go
 
+// trace_runtime_traceAdvance is not supported by this tracer,
+// whose trace is not split into generations.
+//
+//go:linkname trace_runtime_traceAdvance runtime/trace.runtime_traceAdvance
+func trace_runtime_traceAdvance() uint64 {
+	return 0
+}
+
 //go:linkname trace_userTaskCreate runtime/trace.userTaskCreate
 func trace_userTaskCreate(id, parentID uint64, taskType string) {
 	if !trace.enabled {
@@ -0,0 +1,329 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trace
+
+import (
+	"errors"
+	"internal/goexperiment"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// FlightRecorder keeps the most recent part of the execution trace of
+// the program in memory, so that it can be written out when something
+// interesting happens, such as a latency spike, without paying for
+// writing out the whole trace.
+//
+// The trace is recorded in units called generations, which the runtime
+// starts about once per second. A snapshot taken by
+// [FlightRecorder.WriteTo] contains whole generations: it ends with the
+// generation in progress when WriteTo is called, and begins with the
+// oldest generation kept by the recorder. The recorder keeps the
+// generations needed to cover the period set by
+// [FlightRecorder.SetPeriod], as long as they fit in the size set by
+// [FlightRecorder.SetSize]; it always keeps the most recent generation.
+//
+// Like [Start], a flight recorder enables tracing for the whole program,
+// so only one of them can be in use at a time.
+type FlightRecorder struct {
+	period time.Duration
+	size   int
+
+	mu      sync.Mutex // guards the fields below
+	running bool
+	header  []byte        // trace header
+	gens    []*recordGen  // complete or current generations, oldest first
+	pending []byte        // incomplete batch read from the runtime
+	total   int           // size of the data in gens
+	done    chan struct{} // closed when the reader goroutine exits
+
+	writing sync.Mutex // serializes WriteTo calls
+}
+
+// recordGen is the data of a generation of the trace.
+type recordGen struct {
+	gen     uint64
+	batches [][]byte
+	size    int
+	end     time.Time // time the generation was known to be complete
+}
+
+// NewFlightRecorder creates a new flight recorder. By default, it keeps
+// at least the last 10 seconds of the trace, within 10 MiB.
+func NewFlightRecorder() *FlightRecorder {
+	return &FlightRecorder{
+		period: 10 * time.Second,
+		size:   10 << 20,
+	}
+}
+
+// SetPeriod sets the approximate duration of the trace the recorder
+// keeps. It must be called before [FlightRecorder.Start].
+//
+// The period is approximate: it is rounded up to whole generations.
+func (r *FlightRecorder) SetPeriod(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		panic("trace: SetPeriod called on a running FlightRecorder")
+	}
+	r.period = d
+}
+
+// SetSize sets the approximate maximum number of bytes of trace data
+// the recorder keeps, which takes precedence over the period.
+// It must be called before [FlightRecorder.Start].
+//
+// The size is approximate: the most recent generation is always kept,
+// whatever its size.
+func (r *FlightRecorder) SetSize(bytes int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		panic("trace: SetSize called on a running FlightRecorder")
+	}
+	r.size = bytes
+}
+
+// Start starts recording the trace. It returns an error if tracing
+// is already enabled, by [Start] or another flight recorder.
+// Start discards the data of previous recordings.
+func (r *FlightRecorder) Start() error {
+	if !goexperiment.ExecTracer2 {
+		return errors.New("trace: flight recorder requires the execution tracer of Go 1.22")
+	}
+	tracing.Lock()
+	defer tracing.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return errors.New("trace: flight recorder already running")
+	}
+	if err := runtime.StartTrace(); err != nil {
+		return err
+	}
+	r.running = true
+	r.header = nil
+	r.gens = nil
+	r.pending = nil
+	r.total = 0
+	r.done = make(chan struct{})
+	go r.read()
+	tracing.enabled.Store(true)
+	return nil
+}
+
+// Stop stops recording the trace. The recorded data remains available
+// to [FlightRecorder.WriteTo] until the next call to [FlightRecorder.Start].
+func (r *FlightRecorder) Stop() error {
+	tracing.Lock()
+	defer tracing.Unlock()
+
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return errors.New("trace: flight recorder not running")
+	}
+	r.running = false
+	r.mu.Unlock()
+
+	tracing.enabled.Store(false)
+	runtime.StopTrace()
+	<-r.done
+	return nil
+}
+
+// Enabled reports whether the flight recorder is recording.
+func (r *FlightRecorder) Enabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
+
+// WriteTo writes a snapshot of the recorded trace to w, and returns the
+// number of bytes written. The snapshot is a complete trace, which can
+// be read by the trace tool.
+//
+// If the recorder is running, WriteTo first ends the current generation,
+// so that the snapshot includes the most recent events. Concurrent calls
+// to WriteTo are serialized.
+func (r *FlightRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.writing.Lock()
+	defer r.writing.Unlock()
+
+	r.mu.Lock()
+	running := r.running
+	r.mu.Unlock()
+
+	// The last generation to write. Once runtime_traceAdvance returns,
+	// the reader has received all of its data.
+	last := ^uint64(0)
+	if running {
+		if gen := runtime_traceAdvance(); gen != 0 {
+			last = gen
+		}
+	}
+
+	r.mu.Lock()
+	if r.header == nil {
+		r.mu.Unlock()
+		return 0, errors.New("trace: flight recorder has no data")
+	}
+	// Copy the slices, as the reader goroutine may append to them
+	// while w is written to.
+	header := r.header
+	var batches [][]byte
+	for _, g := range r.gens {
+		if g.gen <= last {
+			batches = append(batches, g.batches...)
+		}
+	}
+	r.mu.Unlock()
+
+	n, err := w.Write(header)
+	total := int64(n)
+	for _, b := range batches {
+		if err != nil {
+			break
+		}
+		n, err = w.Write(b)
+		total += int64(n)
+	}
+	return total, err
+}
+
+// read reads the trace from the runtime until tracing stops.
+func (r *FlightRecorder) read() {
+	defer close(r.done)
+	for {
+		data := runtime.ReadTrace()
+		if data == nil {
+			break
+		}
+		r.mu.Lock()
+		r.add(data)
+		r.mu.Unlock()
+	}
+	// The last generation is complete.
+	r.mu.Lock()
+	r.trim(time.Now())
+	r.mu.Unlock()
+}
+
+// add splits data read from the runtime into batches, and adds them to
+// their generation. It must be called with r.mu held.
+func (r *FlightRecorder) add(data []byte) {
+	r.pending = append(r.pending, data...)
+	if r.header == nil {
+		if len(r.pending) < traceHeaderSize {
+			return
+		}
+		r.header = r.pending[:traceHeaderSize:traceHeaderSize]
+		r.pending = r.pending[traceHeaderSize:]
+	}
+	for {
+		gen, size, ok := parseBatchHeader(r.pending)
+		if !ok || size > len(r.pending) {
+			break
+		}
+		// The data was copied to r.pending, as the runtime reuses
+		// its buffers.
+		b := r.pending[:size:size]
+		r.pending = r.pending[size:]
+
+		var g *recordGen
+		if len(r.gens) > 0 {
+			g = r.gens[len(r.gens)-1]
+		}
+		if g == nil || g.gen != gen {
+			// The runtime sends generations in order, so the last one
+			// is complete.
+			r.trim(time.Now())
+			g = &recordGen{gen: gen}
+			r.gens = append(r.gens, g)
+		}
+		g.batches = append(g.batches, b)
+		g.size += len(b)
+		r.total += len(b)
+	}
+	if len(r.pending) == 0 {
+		r.pending = nil
+	}
+}
+
+// trim marks the last generation complete at time now, and discards
+// the oldest generations not needed to cover the period of the recorder
+// or exceeding its size. It must be called with r.mu held.
+func (r *FlightRecorder) trim(now time.Time) {
+	if len(r.gens) == 0 {
+		return
+	}
+	r.gens[len(r.gens)-1].end = now
+	for len(r.gens) > 1 {
+		oldest := r.gens[0]
+		if r.total <= r.size && !oldest.end.Before(now.Add(-r.period)) {
+			break
+		}
+		r.total -= oldest.size
+		r.gens[0] = nil
+		r.gens = r.gens[1:]
+	}
+}
+
+// traceHeaderSize is the size of the header of a trace,
+// such as "go 1.22 trace\x00\x00\x00".
+const traceHeaderSize = 16
+
+// Trace batches start with this event type.
+const evEventBatch = 1
+
+// parseBatchHeader parses the header of the batch at the start of b,
+// and returns its generation and total size. It reports whether b
+// contains the whole header.
+func parseBatchHeader(b []byte) (gen uint64, size int, ok bool) {
+	if len(b) == 0 {
+		return 0, 0, false
+	}
+	if b[0] != evEventBatch {
+		panic("trace: unexpected data from the runtime")
+	}
+	off := 1
+	var fields [4]uint64 // generation, M, timestamp and data size
+	for i := range fields {
+		v, n := uvarint(b[off:])
+		if n == 0 {
+			return 0, 0, false
+		}
+		fields[i] = v
+		off += n
+	}
+	return fields[0], off + int(fields[3]), true
+}
+
+// uvarint is like encoding/binary.Uvarint, but returns n == 0
+// for any invalid or incomplete input.
+func uvarint(b []byte) (uint64, int) {
+	var x uint64
+	var s uint
+	for i, c := range b {
+		if i == 10 {
+			return 0, 0
+		}
+		if c < 0x80 {
+			return x | uint64(c)<<s, i + 1
+		}
+		x |= uint64(c&0x7f) << s
+		s += 7
+	}
+	return 0, 0
+}
+
+// runtime_traceAdvance ends the current generation of the trace, and
+// returns its number once the reader has received all of its data.
+// It returns 0 if tracing is disabled.
+func runtime_traceAdvance() uint64
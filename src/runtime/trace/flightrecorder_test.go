@@ -0,0 +1,162 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trace_test
+
+import (
+	"bytes"
+	"context"
+	"internal/goexperiment"
+	"internal/trace/v2"
+	"io"
+	. "runtime/trace"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	if !goexperiment.ExecTracer2 {
+		t.Skip("skipping because the flight recorder requires the new tracer")
+	}
+	if IsEnabled() {
+		t.Skip("skipping because -test.trace is set")
+	}
+
+	fr := NewFlightRecorder()
+	if err := fr.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := Start(io.Discard); err == nil {
+		Stop()
+		t.Fatal("Start succeeded while the flight recorder is running")
+	}
+	if !IsEnabled() || !fr.Enabled() {
+		t.Error("tracing is not enabled after Start")
+	}
+
+	// Snapshots taken while the program runs are complete traces.
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					WithRegion(context.Background(), "work", func() {
+						time.Sleep(time.Millisecond)
+					})
+				}
+			}
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		time.Sleep(10 * time.Millisecond)
+		var buf bytes.Buffer
+		n, err := fr.WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("WriteTo: %v", err)
+		}
+		if n != int64(buf.Len()) {
+			t.Errorf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+		}
+		if regions := countRegions(t, buf.Bytes(), "work"); regions == 0 {
+			t.Error("snapshot has no work regions")
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if err := fr.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if IsEnabled() || fr.Enabled() {
+		t.Error("tracing is enabled after Stop")
+	}
+	if err := fr.Stop(); err == nil {
+		t.Error("second Stop succeeded")
+	}
+
+	// The recorded data remains available.
+	var buf bytes.Buffer
+	if _, err := fr.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo after Stop: %v", err)
+	}
+	countRegions(t, buf.Bytes(), "work")
+}
+
+func TestFlightRecorderSize(t *testing.T) {
+	if !goexperiment.ExecTracer2 {
+		t.Skip("skipping because the flight recorder requires the new tracer")
+	}
+	if IsEnabled() {
+		t.Skip("skipping because -test.trace is set")
+	}
+
+	// A recorder with a tiny size keeps only the latest generation.
+	fr := NewFlightRecorder()
+	fr.SetSize(1)
+	if err := fr.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer fr.Stop()
+	for i := 0; i < 5; i++ {
+		Log(context.Background(), "iteration", "x")
+		if _, err := fr.WriteTo(io.Discard); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := fr.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	r, err := trace.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syncs := 0
+	for {
+		ev, err := r.ReadEvent()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Kind() == trace.EventSync {
+			syncs++
+		}
+	}
+	// There is a sync event before and after each generation.
+	if syncs != 2 {
+		t.Errorf("snapshot has %d generations, want 1", syncs-1)
+	}
+}
+
+// countRegions parses the trace in data and returns the number of
+// regions of the given type it contains.
+func countRegions(t *testing.T, data []byte, typ string) int {
+	t.Helper()
+	r, err := trace.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parsing snapshot: %v", err)
+	}
+	n := 0
+	for {
+		ev, err := r.ReadEvent()
+		if err == io.EOF {
+			return n
+		}
+		if err != nil {
+			t.Fatalf("parsing snapshot: %v", err)
+		}
+		if ev.Kind() == trace.EventRegionBegin && ev.Region().Type == typ {
+			n++
+		}
+	}
+}
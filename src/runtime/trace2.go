@@ -899,6 +899,20 @@ func traceReaderAvailable() *g {
 	return nil
 }
 
+// trace_runtime_traceAdvance ends the current generation of the trace,
+// and returns its number once all of its data has been read by the
+// trace reader. It returns 0 if tracing is disabled.
+//
+//go:linkname trace_runtime_traceAdvance runtime/trace.runtime_traceAdvance
+func trace_runtime_traceAdvance() uint64 {
+	gen := trace.gen.Load()
+	if gen == 0 {
+		return 0
+	}
+	traceAdvance(false)
+	return uint64(gen)
+}
+
 // Trace advancer goroutine.
 var traceAdvancer traceAdvancerState
 
@@ -13,6 +13,7 @@ package testdeps
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"internal/fuzz"
 	"internal/testlog"
 	"io"
@@ -197,3 +198,41 @@ func (TestDeps) ResetCoverage() {
 func (TestDeps) SnapshotCoverage() {
 	fuzz.SnapshotCoverage()
 }
+
+// ReadTestDurations returns the elapsed times of the top-level tests of
+// the package being tested, as recorded in file, the output of a
+// previous 'go test -json' run. Events for other packages, subtests,
+// and lines that are not JSON test events are ignored.
+func (TestDeps) ReadTestDurations(file string) (map[string]time.Duration, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	durations := make(map[string]time.Duration)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var ev struct {
+			Action  string
+			Package string
+			Test    string
+			Elapsed *float64
+		}
+		if json.Unmarshal(scanner.Bytes(), &ev) != nil {
+			continue
+		}
+		if ev.Package != ImportPath || ev.Test == "" || strings.Contains(ev.Test, "/") || ev.Elapsed == nil {
+			continue
+		}
+		switch ev.Action {
+		case "pass", "fail", "skip":
+			durations[ev.Test] = time.Duration(*ev.Elapsed * float64(time.Second))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return durations, nil
+}
@@ -18,6 +18,7 @@ var cover2 struct {
 	mode        string
 	tearDown    func(coverprofile string, gocoverdir string) (string, error)
 	snapshotcov func() float64
+	perTest     func(dir string, test string) error
 }
 
 // registerCover2 is invoked during "go test -cover" runs by the test harness
@@ -29,6 +30,28 @@ func registerCover2(mode string, tearDown func(coverprofile string, gocoverdir s
 	cover2.snapshotcov = snapcov
 }
 
+// registerCoverPerTest is invoked during "go test -cover" runs by the
+// test harness code in _testmain.go; it is used to record a function
+// that writes the coverage counters incremented by a test to a
+// directory, to support the -test.coverpertest flag.
+func registerCoverPerTest(f func(dir string, test string) error) {
+	cover2.perTest = f
+}
+
+// coverPerTest writes the coverage counters incremented by the named
+// top-level test, which has just finished, to the directory named by
+// the -test.coverpertest flag. If name is empty, coverPerTest only
+// records the counters as they are before the first test runs.
+func coverPerTest(name string) {
+	if *coverPerTestDir == "" || cover2.perTest == nil {
+		return
+	}
+	if err := cover2.perTest(*coverPerTestDir, name); err != nil {
+		fmt.Fprintf(os.Stderr, "testing: writing coverage data for %s: %v\n", name, err)
+		os.Exit(2)
+	}
+}
+
 // coverReport2 invokes a callback in _testmain.go that will
 // emit coverage data at the point where test execution is complete,
 // for "go test -cover" runs.
@@ -0,0 +1,144 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseShard parses a -test.shard value of the form "i/n",
+// with 0 <= i < n.
+func parseShard(s string) (index, count int, err error) {
+	is, ns, ok := strings.Cut(s, "/")
+	if ok {
+		index, err = strconv.Atoi(is)
+		if err == nil {
+			count, err = strconv.Atoi(ns)
+		}
+	}
+	if !ok || err != nil || count < 1 || index < 0 || index >= count {
+		return 0, 0, fmt.Errorf("-test.shard must be of the form i/n with 0 <= i < n, not %q", s)
+	}
+	return index, count, nil
+}
+
+// minShardWeight is the duration assumed for a test whose
+// recorded duration is shorter, so that many fast tests are
+// still spread across shards.
+const minShardWeight = time.Millisecond
+
+// assignShards assigns each of names to one of count shards
+// and returns the shard index for each name.
+//
+// If durations is empty, each name is assigned by a hash of the name,
+// so that the assignment of a test does not change as other tests are
+// added or removed. Otherwise the names are assigned greedily, longest
+// first, to the shard with the least total duration so far; names
+// without a recorded duration are assumed to take the average duration.
+// In both cases the result depends only on the set of names,
+// not on their order.
+func assignShards(names []string, count int, durations map[string]time.Duration) map[string]int {
+	shards := make(map[string]int, len(names))
+
+	var total time.Duration
+	var known int
+	for _, name := range names {
+		if d, ok := durations[name]; ok {
+			total += max(d, minShardWeight)
+			known++
+		}
+	}
+	if known == 0 {
+		for _, name := range names {
+			shards[name] = int(fnv32a(name) % uint32(count))
+		}
+		return shards
+	}
+
+	average := total / time.Duration(known)
+	weight := func(name string) time.Duration {
+		if d, ok := durations[name]; ok {
+			return max(d, minShardWeight)
+		}
+		return average
+	}
+	sorted := slices.Clone(names)
+	slices.SortFunc(sorted, func(a, b string) int {
+		if wa, wb := weight(a), weight(b); wa != wb {
+			if wa > wb {
+				return -1
+			}
+			return +1
+		}
+		return strings.Compare(a, b)
+	})
+	totals := make([]time.Duration, count)
+	for _, name := range sorted {
+		i := 0
+		for j := range totals {
+			if totals[j] < totals[i] {
+				i = j
+			}
+		}
+		shards[name] = i
+		totals[i] += weight(name)
+	}
+	return shards
+}
+
+// fnv32a returns the 32-bit FNV-1a hash of s.
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+	return h
+}
+
+// shard removes from m the tests, examples, and fuzz targets that
+// are not assigned to the shard selected by -test.shard.
+// Benchmarks are not sharded.
+func (m *M) shard() error {
+	if *shardFlag == "" {
+		return nil
+	}
+	index, count, err := parseShard(*shardFlag)
+	if err != nil {
+		return err
+	}
+	var durations map[string]time.Duration
+	if *shardTimes != "" {
+		durations, err = m.deps.ReadTestDurations(*shardTimes)
+		if err != nil {
+			return fmt.Errorf("reading -test.shardtimes: %v", err)
+		}
+	}
+
+	var names []string
+	for _, t := range m.tests {
+		names = append(names, t.Name)
+	}
+	for _, e := range m.examples {
+		names = append(names, e.Name)
+	}
+	for _, f := range m.fuzzTargets {
+		names = append(names, f.Name)
+	}
+	shards := assignShards(names, count, durations)
+
+	m.tests = slices.DeleteFunc(m.tests, func(t InternalTest) bool { return shards[t.Name] != index })
+	m.examples = slices.DeleteFunc(m.examples, func(e InternalExample) bool { return shards[e.Name] != index })
+	m.fuzzTargets = slices.DeleteFunc(m.fuzzTargets, func(f InternalFuzzTarget) bool { return shards[f.Name] != index })
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+func TestParseShard(t *T) {
+	for _, tc := range []struct {
+		in           string
+		index, count int
+		ok           bool
+	}{
+		{"0/1", 0, 1, true},
+		{"2/3", 2, 3, true},
+		{"3/3", 0, 0, false},
+		{"-1/3", 0, 0, false},
+		{"0/0", 0, 0, false},
+		{"1", 0, 0, false},
+		{"a/b", 0, 0, false},
+		{"", 0, 0, false},
+	} {
+		index, count, err := parseShard(tc.in)
+		if ok := err == nil; ok != tc.ok || index != tc.index || count != tc.count {
+			t.Errorf("parseShard(%q) = %d, %d, %v; want %d, %d, ok=%v", tc.in, index, count, err, tc.index, tc.count, tc.ok)
+		}
+	}
+}
+
+func TestAssignShardsHash(t *T) {
+	var names []string
+	for i := range 100 {
+		names = append(names, fmt.Sprintf("Test%d", i))
+	}
+	const count = 4
+	shards := assignShards(names, count, nil)
+	perShard := make([]int, count)
+	for _, name := range names {
+		i, ok := shards[name]
+		if !ok || i < 0 || i >= count {
+			t.Fatalf("%s assigned to shard %d (ok=%v), want 0 <= shard < %d", name, i, ok, count)
+		}
+		perShard[i]++
+	}
+	for i, n := range perShard {
+		if n == 0 {
+			t.Errorf("shard %d has no tests: %v", i, perShard)
+		}
+	}
+
+	// Adding a test or reordering the tests does not move any other test.
+	more := append(slices.Clone(names), "TestNew")
+	slices.Reverse(more)
+	shards2 := assignShards(more, count, nil)
+	for _, name := range names {
+		if shards[name] != shards2[name] {
+			t.Errorf("%s moved from shard %d to %d", name, shards[name], shards2[name])
+		}
+	}
+}
+
+func TestAssignShardsDurations(t *T) {
+	names := []string{"TestA", "TestB", "TestC", "TestD", "TestE", "TestNew"}
+	durations := map[string]time.Duration{
+		"TestA": 8 * time.Second,
+		"TestB": 5 * time.Second,
+		"TestC": 4 * time.Second,
+		"TestD": 3 * time.Second,
+		"TestE": 0,
+	}
+	// TestNew has no recorded duration, so it is assumed to take the
+	// average, just over 4s. Assigning longest first to the shard with
+	// the least total so far gives:
+	//	TestA (8s)      → 0, totals 8s, 0s
+	//	TestB (5s)      → 1, totals 8s, 5s
+	//	TestNew (4s+)   → 1, totals 8s, 9s+
+	//	TestC (4s)      → 0, totals 12s, 9s+
+	//	TestD (3s)      → 1, totals 12s, 12s+
+	//	TestE (at least 1ms) → 0
+	want := map[string]int{
+		"TestA":   0,
+		"TestB":   1,
+		"TestNew": 1,
+		"TestC":   0,
+		"TestD":   1,
+		"TestE":   0,
+	}
+	got := assignShards(names, 2, durations)
+	for name, i := range want {
+		if got[name] != i {
+			t.Errorf("%s assigned to shard %d, want %d (all: %v)", name, got[name], i, got)
+		}
+	}
+
+	// The assignment does not depend on the order of the names.
+	reversed := slices.Clone(names)
+	slices.Reverse(reversed)
+	got2 := assignShards(reversed, 2, durations)
+	for name := range want {
+		if got[name] != got2[name] {
+			t.Errorf("%s assigned to shard %d in one order and %d in another", name, got[name], got2[name])
+		}
+	}
+}
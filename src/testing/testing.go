@@ -424,6 +424,7 @@ func Init() {
 	count = flag.Uint("test.count", 1, "run tests and benchmarks `n` times")
 	coverProfile = flag.String("test.coverprofile", "", "write a coverage profile to `file`")
 	gocoverdir = flag.String("test.gocoverdir", "", "write coverage intermediate files to this directory")
+	coverPerTestDir = flag.String("test.coverpertest", "", "write the coverage counters of each test to `dir`")
 	matchList = flag.String("test.list", "", "list tests, examples, and benchmarks matching `regexp` then exit")
 	match = flag.String("test.run", "", "run only tests and examples matching `regexp`")
 	skip = flag.String("test.skip", "", "do not list or run tests matching `regexp`")
@@ -442,6 +443,8 @@ func Init() {
 	testlog = flag.String("test.testlogfile", "", "write test action log to `file` (for use only by cmd/go)")
 	shuffle = flag.String("test.shuffle", "off", "randomize the execution order of tests and benchmarks")
 	fullPath = flag.Bool("test.fullpath", false, "show full file names in error messages")
+	shardFlag = flag.String("test.shard", "", "run only shard `i/n` (0 <= i < n) of the tests, examples, and fuzz targets")
+	shardTimes = flag.String("test.shardtimes", "", "balance shards using the test durations recorded in `file`, the output of a previous go test -json run")
 
 	initBenchmarkFlags()
 	initFuzzFlags()
@@ -456,6 +459,7 @@ var (
 	count                *uint
 	coverProfile         *string
 	gocoverdir           *string
+	coverPerTestDir      *string
 	matchList            *string
 	match                *string
 	skip                 *string
@@ -474,6 +478,8 @@ var (
 	shuffle              *string
 	testlog              *string
 	fullPath             *bool
+	shardFlag            *string
+	shardTimes           *string
 
 	haveExamples bool // are there examples?
 
@@ -1669,6 +1675,9 @@ func tRunner(t *T, fn func(t *T)) {
 			// test. See comment in Run method.
 			t.context.release()
 		}
+		if t.level == 1 && !t.context.isFuzzing {
+			coverPerTest(t.name)
+		}
 		t.report() // Report after all subtests have finished.
 
 		// Do not lock t.done to allow race detector to detect race in case
@@ -1853,6 +1862,9 @@ func (f matchStringOnly) ReadCorpus(string, []reflect.Type) ([]corpusEntry, erro
 func (f matchStringOnly) CheckCorpus([]any, []reflect.Type) error { return nil }
 func (f matchStringOnly) ResetCoverage()                          {}
 func (f matchStringOnly) SnapshotCoverage()                       {}
+func (f matchStringOnly) ReadTestDurations(string) (map[string]time.Duration, error) {
+	return nil, errMain
+}
 
 // Main is an internal function, part of the implementation of the "go test" command.
 // It was exported because it is cross-package and predates "internal" packages.
@@ -1901,6 +1913,7 @@ type testDeps interface {
 	CheckCorpus([]any, []reflect.Type) error
 	ResetCoverage()
 	SnapshotCoverage()
+	ReadTestDurations(string) (map[string]time.Duration, error)
 }
 
 // MainStart is meant for use by tests generated by 'go test'.
@@ -1988,6 +2001,13 @@ func (m *M) Run() (code int) {
 		return
 	}
 
+	if err := m.shard(); err != nil {
+		fmt.Fprintln(os.Stderr, "testing:", err)
+		flag.Usage()
+		m.exitCode = 2
+		return
+	}
+
 	if *matchList != "" {
 		listTests(m.deps.MatchString, m.tests, m.benchmarks, m.fuzzTargets, m.examples)
 		m.exitCode = 0
@@ -2156,6 +2176,7 @@ func runTests(matchString func(pat, str string) (bool, error), tests []InternalT
 			if Verbose() {
 				t.chatty = newChattyPrinter(t.w)
 			}
+			coverPerTest("")
 			tRunner(t, func(t *T) {
 				for _, test := range tests {
 					t.Run(test.Name, test.F)
@@ -2218,6 +2239,20 @@ func (m *M) before() {
 		fmt.Fprintf(os.Stderr, "testing: cannot use -test.gocoverdir because test binary was not built with coverage enabled\n")
 		os.Exit(2)
 	}
+	if *coverPerTestDir != "" {
+		switch CoverMode() {
+		case "":
+			fmt.Fprintf(os.Stderr, "testing: cannot use -test.coverpertest because test binary was not built with coverage enabled\n")
+			os.Exit(2)
+		case "set":
+			fmt.Fprintf(os.Stderr, "testing: cannot use -test.coverpertest because test binary was built with -covermode=set\n")
+			os.Exit(2)
+		}
+		if !goexperiment.CoverageRedesign {
+			fmt.Fprintf(os.Stderr, "testing: cannot use -test.coverpertest with GOEXPERIMENT=nocoverageredesign\n")
+			os.Exit(2)
+		}
+	}
 	if *testlog != "" {
 		// Note: Not using toOutputDir.
 		// This file is for use by cmd/go, not users.